@@ -1,47 +1,35 @@
 package main
 
 import (
+	"context"
 	"log/slog"
-	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
-	_ "github.com/Nicholas2012/time-tracker/docs"
-	"github.com/Nicholas2012/time-tracker/internal/api"
 	"github.com/Nicholas2012/time-tracker/internal/config"
-	"github.com/Nicholas2012/time-tracker/internal/repository"
-	"github.com/Nicholas2012/time-tracker/internal/usecase"
-	"github.com/Nicholas2012/time-tracker/pkg/database"
-	httpSwagger "github.com/swaggo/http-swagger/v2"
+	"github.com/Nicholas2012/time-tracker/internal/logging"
 )
 
 func main() {
-	slog.Info("Starting server...")
-
-	config := config.New()
+	cfg := config.New()
 
-	db, err := database.New(config.DatabaseDSN)
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
-		slog.Error("Failed to connect to database", "error", err)
-		os.Exit(1)
-	}
-
-	if err := database.ApplyMigrations(db); err != nil {
-		slog.Error("Failed to apply migrations", "error", err)
+		slog.Error("Invalid logging config", "error", err)
 		os.Exit(1)
 	}
+	slog.SetDefault(logger)
 
-	repo := repository.New(db)
-	svc := usecase.New(repo)
-	api := api.New(svc)
+	slog.Info("Starting server...")
 
-	api.AddRoutes(http.DefaultServeMux)
-	http.Handle("/swagger/", httpSwagger.Handler())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	slog.Info("Server started", "listen", config.Listen)
-	if err := http.ListenAndServe(config.Listen, nil); err != nil {
-		slog.Error("Server failed to start", "error", err)
+	if err := Run(ctx, cfg); err != nil {
+		slog.Error("Server failed", "error", err)
 		os.Exit(1)
 	}
 
-	// todo add graceful shutdown
+	slog.Info("Server stopped")
 }
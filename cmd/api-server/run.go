@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	_ "github.com/Nicholas2012/time-tracker/docs"
+	"github.com/Nicholas2012/time-tracker/internal/api"
+	"github.com/Nicholas2012/time-tracker/internal/auth"
+	"github.com/Nicholas2012/time-tracker/internal/config"
+	"github.com/Nicholas2012/time-tracker/internal/encryption"
+	"github.com/Nicholas2012/time-tracker/internal/events"
+	"github.com/Nicholas2012/time-tracker/internal/health"
+	"github.com/Nicholas2012/time-tracker/internal/metrics"
+	"github.com/Nicholas2012/time-tracker/internal/nameservice"
+	"github.com/Nicholas2012/time-tracker/internal/repository"
+	"github.com/Nicholas2012/time-tracker/internal/repository/memory"
+	"github.com/Nicholas2012/time-tracker/internal/repository/notified"
+	"github.com/Nicholas2012/time-tracker/internal/repository/sqlite"
+	"github.com/Nicholas2012/time-tracker/internal/repository/traced"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/tracing"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/Nicholas2012/time-tracker/pkg/database"
+	httpSwagger "github.com/swaggo/http-swagger/v2"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Run starts the server and blocks until ctx is cancelled or the server fails.
+// On cancellation in-flight requests are drained within cfg.ShutdownTimeout, then the storage is closed.
+func Run(ctx context.Context, cfg config.Config) error {
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracingExporter)
+	if err != nil {
+		return fmt.Errorf("setup tracing: %w", err)
+	}
+	defer func() {
+		// pending spans are flushed after the server has stopped
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Failed to flush spans", "error", err)
+		}
+	}()
+
+	repo, db, err := openRepository(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if db != nil {
+		defer db.Close()
+	}
+
+	m := metrics.New()
+	m.RegisterRunningTasks(countRunningTasks(repo))
+	if db != nil {
+		m.RegisterDB(db, cfg.Storage)
+	}
+
+	secret, err := tokenSecret(cfg.AuthTokenSecret)
+	if err != nil {
+		return err
+	}
+
+	opts := []usecase.Option{
+		usecase.WithStartPolicy(usecase.StartPolicy(cfg.TaskStartPolicy)),
+		usecase.WithTokens(auth.NewTokens(secret, cfg.AuthTokenTTL)),
+		usecase.WithMetrics(m),
+	}
+	if cfg.IsAutoStopEnabled() {
+		opts = append(opts, usecase.WithAutoStop(usecase.AutoStopPolicy{
+			MaxDuration: cfg.AutoStopMaxDuration,
+			Cutoff:      cfg.AutoStopCutoff,
+		}))
+	}
+	if cfg.AuthAdminKey != "" {
+		opts = append(opts, usecase.WithAdminKey(cfg.AuthAdminKey))
+	}
+	checker := health.NewChecker()
+	if db != nil {
+		checker.Add("database", db.PingContext)
+		checker.Add("migrations", func(ctx context.Context) error {
+			if cfg.Storage == config.StorageSQLite {
+				return database.CheckSQLiteMigrations(ctx, db)
+			}
+			return database.CheckMigrations(ctx, db)
+		})
+	}
+
+	if cfg.IsNameServiceEnabled() {
+		names := nameservice.New(cfg.NameServiceURL, nameservice.Options{
+			Timeout: cfg.NameServiceTimeout,
+			Retries: cfg.NameServiceRetries,
+			Backoff: cfg.NameServiceBackoff,
+		})
+		opts = append(opts, usecase.WithNameService(names, usecase.NamePolicy(cfg.NameServicePolicy)))
+		checker.Add("name_service", names.Ping)
+	}
+
+	// only queries of the service are traced, the running tasks gauge is scraped too often
+	var svcRepo usecase.Repository = traced.New(repo, dbSystem(cfg.Storage))
+
+	// PostgreSQL notifies every instance of changes, other storages are used by a single one
+	// which publishes its changes itself
+	broker := events.NewBroker(svcRepo)
+	if cfg.Storage != config.StoragePostgres {
+		svcRepo = notified.New(svcRepo, broker)
+	}
+	opts = append(opts, usecase.WithEvents(broker))
+
+	svc := usecase.New(svcRepo, opts...)
+
+	a := api.New(svc)
+	mux := http.NewServeMux()
+	a.AddRoutes(mux)
+	mux.Handle("/swagger/", httpSwagger.Handler())
+	mux.Handle("GET /metrics", m.Handler())
+	mux.HandleFunc("GET /livez", checker.Livez)
+	mux.HandleFunc("GET /readyz", checker.Readyz)
+
+	// background workers must stop before the storage is closed
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	// workers make internal calls, they are not restricted as calls of users are
+	workersCtx, stopWorkers := context.WithCancel(usecase.WithActor(ctx, usecase.System))
+	defer stopWorkers()
+
+	if cfg.IsNameServiceEnabled() && usecase.NamePolicy(cfg.NameServicePolicy) == usecase.NamePolicyDefer {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			enrichUsers(workersCtx, svc, cfg.NameServiceEnrichInterval)
+		}()
+	}
+
+	if cfg.IsAutoStopEnabled() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			autoStopTasks(workersCtx, svc, cfg.AutoStopInterval)
+		}()
+	}
+
+	if cfg.Storage == config.StoragePostgres {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			events.Listen(workersCtx, cfg.DatabaseDSN, broker)
+		}()
+	}
+
+	ln, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler:      m.Instrument(mux, api.Trace(mux, api.Log(mux, slog.Default(), a.Authenticate(mux)))),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+	// event streams never finish by themselves
+	srv.RegisterOnShutdown(broker.Close)
+
+	slog.Info("Server started", "listen", ln.Addr().String())
+	return serve(ctx, srv, ln, checker, cfg.ShutdownDelay, cfg.ShutdownTimeout)
+}
+
+// openRepository connects to the configured storage, applies migrations and encrypts passports
+// stored in plaintext or with old keys in every organization.
+// db is the database of the storage to be closed by the caller, nil for the in-memory storage.
+func openRepository(ctx context.Context, cfg config.Config) (repo usecase.Repository, db *sql.DB, err error) {
+	var encrypt func(ctx context.Context) (int, error)
+	switch cfg.Storage {
+	case config.StorageMemory:
+		slog.Warn("Using in-memory storage, data will be lost on exit")
+		return memory.New(), nil, nil
+	case config.StorageSQLite:
+		keys, err := passportKeyring(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if db, err = database.NewSQLite(cfg.SQLitePath); err != nil {
+			return nil, nil, fmt.Errorf("open sqlite database: %w", err)
+		}
+		if err := database.ApplySQLiteMigrations(db); err != nil {
+			db.Close()
+			return nil, nil, fmt.Errorf("apply migrations: %w", err)
+		}
+		r := sqlite.New(db, keys)
+		repo, encrypt = r, r.EncryptPassports
+	case config.StoragePostgres:
+		keys, err := passportKeyring(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if db, err = database.New(cfg.DatabaseDSN); err != nil {
+			return nil, nil, fmt.Errorf("connect to database: %w", err)
+		}
+		if err := database.ApplyMigrations(db); err != nil {
+			db.Close()
+			return nil, nil, fmt.Errorf("apply migrations: %w", err)
+		}
+		if bypass, err := database.BypassesRowSecurity(ctx, db); err != nil {
+			slog.Warn("Failed to check the database role", "error", err)
+		} else if bypass {
+			slog.Warn("Database role bypasses row-level security, organizations are isolated by queries only")
+		}
+		r := repository.New(db, keys)
+		repo, encrypt = r, r.EncryptPassports
+	default:
+		return nil, nil, fmt.Errorf("unknown storage %q, must be postgres, sqlite or memory", cfg.Storage)
+	}
+
+	orgs, err := repo.ListOrgs(ctx)
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("list organizations: %w", err)
+	}
+	for _, org := range orgs {
+		n, err := encrypt(tenant.WithOrg(ctx, org.ID))
+		if err != nil {
+			db.Close()
+			return nil, nil, fmt.Errorf("encrypt passports of organization %d: %w", org.ID, err)
+		}
+		if n > 0 {
+			slog.Info("Passports encrypted with the current key", "org", org.ID, "users", n)
+		}
+	}
+	return repo, db, nil
+}
+
+// countRunningTasks returns the count of the running tasks gauge, the total over all organizations.
+func countRunningTasks(repo usecase.Repository) func(ctx context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		orgs, err := repo.ListOrgs(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		var total int
+		for _, org := range orgs {
+			n, err := repo.CountRunningTasks(tenant.WithOrg(ctx, org.ID))
+			if err != nil {
+				return 0, err
+			}
+			total += n
+		}
+		return total, nil
+	}
+}
+
+// passportKeyring returns the keyring encrypting passports. Unlike the token secret the keys can't be random,
+// stored passports would be lost on restart, so they are required.
+func passportKeyring(cfg config.Config) (*encryption.Keyring, error) {
+	if cfg.PassportKeys == "" || cfg.PassportIndexKey == "" {
+		return nil, errors.New("PASSPORT_KEYS and PASSPORT_INDEX_KEY must be set to store passports encrypted")
+	}
+
+	keys, err := encryption.ParseKeys(cfg.PassportKeys)
+	if err != nil {
+		return nil, fmt.Errorf("parse PASSPORT_KEYS: %w", err)
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(cfg.PassportIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("parse PASSPORT_INDEX_KEY: %w", err)
+	}
+
+	keyring, err := encryption.NewKeyring(indexKey, keys...)
+	if err != nil {
+		return nil, fmt.Errorf("passport keys: %w", err)
+	}
+	return keyring, nil
+}
+
+// dbSystem returns the db.system attribute of repository spans for the storage.
+func dbSystem(storage string) attribute.KeyValue {
+	switch storage {
+	case config.StoragePostgres:
+		return semconv.DBSystemPostgreSQL
+	case config.StorageSQLite:
+		return semconv.DBSystemSqlite
+	default:
+		return semconv.DBSystemKey.String(storage)
+	}
+}
+
+// tokenSecret returns the configured secret to sign tokens or a random one.
+func tokenSecret(configured string) ([]byte, error) {
+	if configured != "" {
+		return []byte(configured), nil
+	}
+
+	slog.Warn("AUTH_TOKEN_SECRET is not set, issued tokens will be invalid after restart")
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generate token secret: %w", err)
+	}
+	return secret, nil
+}
+
+// serve handles requests on ln until ctx is cancelled, then shuts srv down gracefully.
+// On cancellation the checker reports not ready while requests are still served for delay,
+// so load balancers stop routing to the server before it refuses connections.
+// Requests which don't finish within timeout are cut off.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, checker *health.Checker, delay, timeout time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	checker.ShutDown()
+	if delay > 0 {
+		slog.Info("Server is not ready, waiting before shutdown", "delay", delay)
+		select {
+		case err := <-errc:
+			return fmt.Errorf("serve: %w", err)
+		case <-time.After(delay):
+		}
+	}
+
+	slog.Info("Shutting down server...", "timeout", timeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("shutdown: %w", err)
+	}
+
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+	return nil
+}
+
+// enrichUsers periodically fills users created while the name service was unavailable.
+func enrichUsers(ctx context.Context, svc *usecase.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := svc.EnrichPendingUsers(ctx)
+		if err != nil {
+			slog.Warn("Failed to enrich pending users", "error", err)
+		}
+		if n > 0 {
+			slog.Info("Pending users enriched", "count", n)
+		}
+	}
+}
+
+// autoStopTasks periodically ends tasks running longer than the auto-stop policy allows.
+func autoStopTasks(ctx context.Context, svc *usecase.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := svc.AutoStopTasks(ctx)
+		if err != nil {
+			slog.Warn("Failed to auto-stop tasks", "error", err)
+		}
+		if n > 0 {
+			slog.Info("Tasks auto-stopped", "count", n)
+		}
+	}
+}
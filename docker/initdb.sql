@@ -0,0 +1,4 @@
+-- The service connects as an ordinary role owning its database: superusers bypass row-level security
+-- isolating organizations. The script runs only when the data directory of the container is initialized.
+CREATE ROLE time_tracker LOGIN PASSWORD 'time_tracker';
+CREATE DATABASE time_tracker OWNER time_tracker;
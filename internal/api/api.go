@@ -2,11 +2,26 @@ package api
 
 import (
 	"encoding/json"
-	"log/slog"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
 )
 
-//go:generate go run github.com/swaggo/swag/cmd/swag@latest init -g api.go -o ../../docs --parseDependency
+//go:generate go run github.com/swaggo/swag/cmd/swag@latest init -g api.go -d ./,../health -o ../../docs --parseDependency
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Bearer token issued by POST /auth/token, prefixed with "Bearer ".
 
 type API struct {
 	service Service
@@ -19,23 +34,81 @@ func New(s Service) *API {
 }
 
 func (a *API) AddRoutes(s *http.ServeMux) {
-	s.HandleFunc("/health", a.health)
 	s.HandleFunc("POST /users", a.CreateUser)
+	s.HandleFunc("GET /users", a.ListUsers)
+	s.HandleFunc("GET /users/by-passport", a.GetUserByPassport)
+	s.HandleFunc("GET /users/{id}", a.GetUser)
+	s.HandleFunc("PATCH /users/{id}", a.UpdateUser)
+	s.HandleFunc("DELETE /users/{id}", a.DeleteUser)
+
+	s.HandleFunc("POST /auth/token", a.IssueToken)
+	s.HandleFunc("POST /users/{id}/keys", a.CreateAPIKey)
+	s.HandleFunc("GET /users/{id}/keys", a.ListAPIKeys)
+	s.HandleFunc("DELETE /users/{id}/keys/{keyID}", a.RevokeAPIKey)
+	s.HandleFunc("POST /users/{id}/feed-token", a.IssueFeedToken)
+	s.HandleFunc("DELETE /users/{id}/feed-token", a.RevokeFeedToken)
 
+	s.HandleFunc("GET /users/{id}/report", a.Workload)
+	s.HandleFunc("GET /users/{id}/timesheet", a.Timesheet)
+	s.HandleFunc("GET /timesheet", a.TimesheetAll)
 	s.HandleFunc("GET /users/{id}/tasks", a.ListTasks)
+	s.HandleFunc(feedPattern, a.TaskFeed)
+	s.HandleFunc("POST /users/{id}/tasks", a.CreateTask)
+	s.HandleFunc("PATCH /users/{id}/tasks/{taskID}", a.UpdateTask)
 	s.HandleFunc("POST /users/{id}/tasks/start", a.StartTask)
+	s.HandleFunc("POST /users/{id}/tasks/switch", a.SwitchTask)
 	s.HandleFunc("POST /users/{id}/tasks/{taskID}/end", a.EndTask)
-}
+	s.HandleFunc("POST /users/{id}/tasks/{taskID}/pause", a.PauseTask)
+	s.HandleFunc("POST /users/{id}/tasks/{taskID}/resume", a.ResumeTask)
+
+	s.HandleFunc("POST /projects", a.CreateProject)
+	s.HandleFunc("GET /projects", a.ListProjects)
+	s.HandleFunc("GET /projects/{id}", a.GetProject)
+	s.HandleFunc("PATCH /projects/{id}", a.UpdateProject)
+	s.HandleFunc("DELETE /projects/{id}", a.DeleteProject)
+
+	s.HandleFunc("POST /tags", a.CreateTag)
+	s.HandleFunc("GET /tags", a.ListTags)
+	s.HandleFunc("GET /tags/{id}", a.GetTag)
+	s.HandleFunc("PATCH /tags/{id}", a.UpdateTag)
+	s.HandleFunc("DELETE /tags/{id}", a.DeleteTag)
+
+	s.HandleFunc("POST /teams", a.CreateTeam)
+	s.HandleFunc("GET /teams", a.ListTeams)
+	s.HandleFunc("GET /teams/{id}", a.GetTeam)
+	s.HandleFunc("PATCH /teams/{id}", a.UpdateTeam)
+	s.HandleFunc("DELETE /teams/{id}", a.DeleteTeam)
+	s.HandleFunc("GET /teams/{id}/report", a.TeamReport)
 
-func (a *API) health(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("OK"))
+	s.HandleFunc("GET /audit", a.ListAudit)
+	s.HandleFunc("GET /events", a.Events)
+
+	s.HandleFunc("POST /orgs", a.CreateOrg)
+	s.HandleFunc("GET /orgs", a.ListOrgs)
 }
 
 type Response struct {
-	Data  any    `json:"data"`
-	Error string `json:"error,omitempty"`
+	Data any `json:"data"`
+}
+
+// Problem is an error response body as described in RFC 7807.
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Code     string         `json:"code"`             // stable machine-readable error code
+	Errors   []ProblemField `json:"errors,omitempty"` // invalid fields of validation errors
 }
 
+type ProblemField struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+const problemContentType = "application/problem+json"
+
 func (a *API) writeResp(w http.ResponseWriter, r *http.Request, data any) {
 	resp := Response{
 		Data: data,
@@ -46,24 +119,144 @@ func (a *API) writeResp(w http.ResponseWriter, r *http.Request, data any) {
 	}
 }
 
-func (a *API) writeErr(w http.ResponseWriter, r *http.Request, status int, err error) {
-	slog.Error("Request failed", "status", status, "error", err.Error(), "url", r.URL.Path)
-
-	resp := Response{
-		Error: err.Error(),
+// writeErr writes the error as a problem, domain errors are mapped to matching statuses.
+func (a *API) writeErr(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr *usecase.Error
+	if !errors.As(err, &domainErr) {
+		a.internalServerError(w, r, err)
+		return
 	}
 
-	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		slog.Error("Failed to write response", "error", err)
-		return
+	p := Problem{
+		Status: domainStatus(domainErr.Kind),
+		Detail: domainErr.Error(),
+		Code:   domainErr.Code,
+	}
+	if p.Code == "" {
+		p.Code = string(domainErr.Kind)
 	}
+	for _, f := range domainErr.Fields {
+		p.Errors = append(p.Errors, ProblemField{Field: f.Field, Message: f.Message})
+	}
+
+	a.writeProblem(w, r, p, err)
 }
 
 func (a *API) badRequest(w http.ResponseWriter, r *http.Request, err error) {
-	a.writeErr(w, r, http.StatusBadRequest, err)
+	a.writeProblem(w, r, Problem{
+		Status: http.StatusBadRequest,
+		Detail: err.Error(),
+		Code:   "bad_request",
+	}, err)
 }
 
 func (a *API) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
-	a.writeErr(w, r, http.StatusInternalServerError, err)
+	// details of internal errors are only logged
+	a.writeProblem(w, r, Problem{
+		Status: http.StatusInternalServerError,
+		Detail: "internal server error",
+		Code:   "internal_error",
+	}, err)
+}
+
+func (a *API) writeProblem(w http.ResponseWriter, r *http.Request, p Problem, err error) {
+	logger := logging.FromContext(r.Context())
+	if p.Status >= http.StatusInternalServerError {
+		logger.Error("Request failed", "status", p.Status, "error", err.Error(), "url", r.URL.Path)
+	} else {
+		logger.Info("Request rejected", "status", p.Status, "code", p.Code, "error", err.Error(), "url", r.URL.Path)
+	}
+
+	p.Type = "about:blank"
+	p.Title = http.StatusText(p.Status)
+	p.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		logger.Error("Failed to write response", "error", err)
+		return
+	}
+}
+
+// domainStatus returns response status for the kind of domain errors.
+func domainStatus(kind usecase.Kind) int {
+	switch kind {
+	case usecase.KindNotFound:
+		return http.StatusNotFound
+	case usecase.KindValidation:
+		return http.StatusUnprocessableEntity
+	case usecase.KindConflict:
+		return http.StatusConflict
+	case usecase.KindForbidden:
+		return http.StatusForbidden
+	case usecase.KindUnauthorized:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// pathInt returns integer path value with the given name.
+func pathInt(r *http.Request, name string) (int, error) {
+	v := r.PathValue(name)
+	if v == "" {
+		return 0, fmt.Errorf("missing %s", name)
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	return i, nil
+}
+
+// queryInt returns integer query value with the given name or zero if it is not set.
+func queryInt(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	return i, nil
+}
+
+// queryTime returns time query value in RFC 3339 or 2006-01-02 format, zero if it is not set.
+func queryTime(r *http.Request, name string) (time.Time, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s, must be RFC 3339 time or date: %s", name, v)
+	}
+
+	return t, nil
+}
+
+// queryBool returns boolean query value with the given name or false if it is not set.
+func queryBool(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	return b, nil
 }
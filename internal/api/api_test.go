@@ -5,31 +5,253 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/Nicholas2012/time-tracker/internal/events"
 	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
 )
 
 type serviceMock struct {
-	createUserFn func(ctx context.Context, passportNumber string) error
-	startTaskFn  func(ctx context.Context, userID int) (int, error)
-	endTaskFn    func(ctx context.Context, userID, taskID int) error
-	listTasksFn  func(ctx context.Context, userID int) ([]models.Task, error)
+	createUserFn        func(ctx context.Context, passportNumber string) error
+	getUserFn           func(ctx context.Context, id int) (*models.User, error)
+	listUsersFn         func(ctx context.Context, opts models.UserListOpts) (*models.UserList, error)
+	updateUserFn        func(ctx context.Context, id int, upd usecase.UserUpdate) (*models.User, error)
+	deleteUserFn        func(ctx context.Context, id int) error
+	startTaskFn         func(ctx context.Context, userID int, info usecase.TaskInfo) (int, error)
+	endTaskFn           func(ctx context.Context, userID, taskID int) error
+	listTasksFn         func(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error)
+	workloadFn          func(ctx context.Context, userID int, from, to time.Time) (*models.Workload, error)
+	timesheetFn         func(ctx context.Context, userID int, from, to time.Time, fn func(*models.TimesheetRow) error) error
+	createProjectFn     func(ctx context.Context, name, description string) (*models.Project, error)
+	getProjectFn        func(ctx context.Context, id int) (*models.Project, error)
+	listProjectsFn      func(ctx context.Context) ([]models.Project, error)
+	updateProjectFn     func(ctx context.Context, id int, upd usecase.ProjectUpdate) (*models.Project, error)
+	deleteProjectFn     func(ctx context.Context, id int) error
+	createTagFn         func(ctx context.Context, name string) (*models.Tag, error)
+	getTagFn            func(ctx context.Context, id int) (*models.Tag, error)
+	listTagsFn          func(ctx context.Context) ([]models.Tag, error)
+	updateTagFn         func(ctx context.Context, id int, name string) (*models.Tag, error)
+	deleteTagFn         func(ctx context.Context, id int) error
+	switchTaskFn        func(ctx context.Context, userID int, info usecase.TaskInfo) (int, int, error)
+	pauseTaskFn         func(ctx context.Context, userID, taskID int) error
+	resumeTaskFn        func(ctx context.Context, userID, taskID int) error
+	createTaskFn        func(ctx context.Context, userID int, entry usecase.TaskEntry) (*models.Task, error)
+	updateTaskFn        func(ctx context.Context, userID, taskID int, upd usecase.TaskUpdate) (*models.Task, error)
+	authenticateFn      func(ctx context.Context, key string) (usecase.Actor, error)
+	authenticateTokenFn func(ctx context.Context, token string) (usecase.Actor, error)
+	issueTokenFn        func(ctx context.Context) (string, time.Time, error)
+	createAPIKeyFn      func(ctx context.Context, userID int, name string) (*models.APIKey, string, error)
+	listAPIKeysFn       func(ctx context.Context, userID int) ([]models.APIKey, error)
+	revokeAPIKeyFn      func(ctx context.Context, userID, keyID int) error
+	issueFeedTokenFn    func(ctx context.Context, userID int) (string, error)
+	revokeFeedTokenFn   func(ctx context.Context, userID int) error
+	taskFeedFn          func(ctx context.Context, userID int, token string) ([]models.Task, error)
+	listAuditEntriesFn  func(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error)
+	getUserByPassportFn func(ctx context.Context, passport string) (*models.User, error)
+	revealPassportsFn   func(ctx context.Context) error
+	createTeamFn        func(ctx context.Context, info usecase.TeamInfo) (*models.Team, error)
+	listTeamsFn         func(ctx context.Context) ([]models.Team, error)
+	getTeamFn           func(ctx context.Context, id int) (*models.Team, error)
+	updateTeamFn        func(ctx context.Context, id int, upd usecase.TeamUpdate) (*models.Team, error)
+	deleteTeamFn        func(ctx context.Context, id int) error
+	teamReportFn        func(ctx context.Context, teamID int, from, to time.Time) (*models.TeamReport, error)
+	resolveOrgFn        func(ctx context.Context, requested int) (int, error)
+	createOrgFn         func(ctx context.Context, name string) (*models.Org, error)
+	listOrgsFn          func(ctx context.Context) ([]models.Org, error)
+	subscribeEventsFn   func(ctx context.Context, filter models.EventFilter, lastEventID int) (*events.Subscription, error)
 }
 
 func (m *serviceMock) CreateUser(ctx context.Context, passportNumber string) error {
 	return m.createUserFn(ctx, passportNumber)
 }
 
-func (m *serviceMock) StartTask(ctx context.Context, userID int) (int, error) {
-	return m.startTaskFn(ctx, userID)
+func (m *serviceMock) GetUser(ctx context.Context, id int) (*models.User, error) {
+	return m.getUserFn(ctx, id)
+}
+
+func (m *serviceMock) ListUsers(ctx context.Context, opts models.UserListOpts) (*models.UserList, error) {
+	return m.listUsersFn(ctx, opts)
+}
+
+func (m *serviceMock) UpdateUser(ctx context.Context, id int, upd usecase.UserUpdate) (*models.User, error) {
+	return m.updateUserFn(ctx, id, upd)
+}
+
+func (m *serviceMock) DeleteUser(ctx context.Context, id int) error {
+	return m.deleteUserFn(ctx, id)
+}
+
+func (m *serviceMock) StartTask(ctx context.Context, userID int, info usecase.TaskInfo) (int, error) {
+	return m.startTaskFn(ctx, userID, info)
 }
 
 func (m *serviceMock) EndTask(ctx context.Context, userID, taskID int) error {
 	return m.endTaskFn(ctx, userID, taskID)
 }
 
-func (m *serviceMock) ListTasks(ctx context.Context, userID int) ([]models.Task, error) {
-	return m.listTasksFn(ctx, userID)
+func (m *serviceMock) ListTasks(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+	return m.listTasksFn(ctx, userID, opts)
+}
+
+func (m *serviceMock) Workload(ctx context.Context, userID int, from, to time.Time) (*models.Workload, error) {
+	return m.workloadFn(ctx, userID, from, to)
+}
+
+func (m *serviceMock) Timesheet(ctx context.Context, userID int, from, to time.Time, fn func(*models.TimesheetRow) error) error {
+	return m.timesheetFn(ctx, userID, from, to, fn)
+}
+
+func (m *serviceMock) CreateProject(ctx context.Context, name, description string) (*models.Project, error) {
+	return m.createProjectFn(ctx, name, description)
+}
+
+func (m *serviceMock) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	return m.getProjectFn(ctx, id)
+}
+
+func (m *serviceMock) ListProjects(ctx context.Context) ([]models.Project, error) {
+	return m.listProjectsFn(ctx)
+}
+
+func (m *serviceMock) UpdateProject(ctx context.Context, id int, upd usecase.ProjectUpdate) (*models.Project, error) {
+	return m.updateProjectFn(ctx, id, upd)
+}
+
+func (m *serviceMock) DeleteProject(ctx context.Context, id int) error {
+	return m.deleteProjectFn(ctx, id)
+}
+
+func (m *serviceMock) CreateTag(ctx context.Context, name string) (*models.Tag, error) {
+	return m.createTagFn(ctx, name)
+}
+
+func (m *serviceMock) GetTag(ctx context.Context, id int) (*models.Tag, error) {
+	return m.getTagFn(ctx, id)
+}
+
+func (m *serviceMock) ListTags(ctx context.Context) ([]models.Tag, error) {
+	return m.listTagsFn(ctx)
+}
+
+func (m *serviceMock) UpdateTag(ctx context.Context, id int, name string) (*models.Tag, error) {
+	return m.updateTagFn(ctx, id, name)
+}
+
+func (m *serviceMock) DeleteTag(ctx context.Context, id int) error {
+	return m.deleteTagFn(ctx, id)
+}
+
+func (m *serviceMock) SwitchTask(ctx context.Context, userID int, info usecase.TaskInfo) (int, int, error) {
+	return m.switchTaskFn(ctx, userID, info)
+}
+
+func (m *serviceMock) PauseTask(ctx context.Context, userID, taskID int) error {
+	return m.pauseTaskFn(ctx, userID, taskID)
+}
+
+func (m *serviceMock) ResumeTask(ctx context.Context, userID, taskID int) error {
+	return m.resumeTaskFn(ctx, userID, taskID)
+}
+
+func (m *serviceMock) CreateTask(ctx context.Context, userID int, entry usecase.TaskEntry) (*models.Task, error) {
+	return m.createTaskFn(ctx, userID, entry)
+}
+
+func (m *serviceMock) UpdateTask(ctx context.Context, userID, taskID int, upd usecase.TaskUpdate) (*models.Task, error) {
+	return m.updateTaskFn(ctx, userID, taskID, upd)
+}
+
+func (m *serviceMock) Authenticate(ctx context.Context, key string) (usecase.Actor, error) {
+	return m.authenticateFn(ctx, key)
+}
+
+func (m *serviceMock) AuthenticateToken(ctx context.Context, token string) (usecase.Actor, error) {
+	return m.authenticateTokenFn(ctx, token)
+}
+
+func (m *serviceMock) IssueToken(ctx context.Context) (string, time.Time, error) {
+	return m.issueTokenFn(ctx)
+}
+
+func (m *serviceMock) CreateAPIKey(ctx context.Context, userID int, name string) (*models.APIKey, string, error) {
+	return m.createAPIKeyFn(ctx, userID, name)
+}
+
+func (m *serviceMock) ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+	return m.listAPIKeysFn(ctx, userID)
+}
+
+func (m *serviceMock) RevokeAPIKey(ctx context.Context, userID, keyID int) error {
+	return m.revokeAPIKeyFn(ctx, userID, keyID)
+}
+
+func (m *serviceMock) IssueFeedToken(ctx context.Context, userID int) (string, error) {
+	return m.issueFeedTokenFn(ctx, userID)
+}
+
+func (m *serviceMock) RevokeFeedToken(ctx context.Context, userID int) error {
+	return m.revokeFeedTokenFn(ctx, userID)
+}
+
+func (m *serviceMock) TaskFeed(ctx context.Context, userID int, token string) ([]models.Task, error) {
+	return m.taskFeedFn(ctx, userID, token)
+}
+
+func (m *serviceMock) ListAuditEntries(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error) {
+	return m.listAuditEntriesFn(ctx, filter)
+}
+
+func (m *serviceMock) GetUserByPassport(ctx context.Context, passport string) (*models.User, error) {
+	return m.getUserByPassportFn(ctx, passport)
+}
+
+func (m *serviceMock) RevealPassports(ctx context.Context) error {
+	return m.revealPassportsFn(ctx)
+}
+
+func (m *serviceMock) CreateTeam(ctx context.Context, info usecase.TeamInfo) (*models.Team, error) {
+	return m.createTeamFn(ctx, info)
+}
+
+func (m *serviceMock) ListTeams(ctx context.Context) ([]models.Team, error) {
+	return m.listTeamsFn(ctx)
+}
+
+func (m *serviceMock) GetTeam(ctx context.Context, id int) (*models.Team, error) {
+	return m.getTeamFn(ctx, id)
+}
+
+func (m *serviceMock) UpdateTeam(ctx context.Context, id int, upd usecase.TeamUpdate) (*models.Team, error) {
+	return m.updateTeamFn(ctx, id, upd)
+}
+
+func (m *serviceMock) DeleteTeam(ctx context.Context, id int) error {
+	return m.deleteTeamFn(ctx, id)
+}
+
+func (m *serviceMock) TeamReport(ctx context.Context, teamID int, from, to time.Time) (*models.TeamReport, error) {
+	return m.teamReportFn(ctx, teamID, from, to)
+}
+
+// ResolveOrg acts within the default organization unless the test resolves otherwise, as every request is resolved.
+func (m *serviceMock) ResolveOrg(ctx context.Context, requested int) (int, error) {
+	if m.resolveOrgFn == nil {
+		return models.DefaultOrgID, nil
+	}
+	return m.resolveOrgFn(ctx, requested)
+}
+
+func (m *serviceMock) CreateOrg(ctx context.Context, name string) (*models.Org, error) {
+	return m.createOrgFn(ctx, name)
+}
+
+func (m *serviceMock) ListOrgs(ctx context.Context) ([]models.Org, error) {
+	return m.listOrgsFn(ctx)
+}
+
+func (m *serviceMock) SubscribeEvents(ctx context.Context, filter models.EventFilter, lastEventID int) (*events.Subscription, error) {
+	return m.subscribeEventsFn(ctx, filter, lastEventID)
 }
 
 func setup(t *testing.T) (*httptest.Server, *serviceMock) {
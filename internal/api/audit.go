@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+type ListAuditResponse struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor string       `json:"nextCursor,omitempty"` // empty on the last page
+}
+
+// AuditEntry is a change of a user or a task.
+type AuditEntry struct {
+	ID        int             `json:"id"`
+	CreatedAt time.Time       `json:"createdAt"`
+	ActorID   int             `json:"actorId,omitempty"`   // absent for the admin key and internal changes
+	ActorRole string          `json:"actorRole,omitempty"` // absent for internal changes, e.g. auto-stopped tasks
+	Action    string          `json:"action" enums:"create,update,delete"`
+	Entity    string          `json:"entity" enums:"user,task"`
+	EntityID  int             `json:"entityId"`
+	Before    json.RawMessage `json:"before" swaggertype:"object"` // null for created entities
+	After     json.RawMessage `json:"after" swaggertype:"object"`  // null for deleted entities
+	RequestID string          `json:"requestId,omitempty"`
+}
+
+func newAuditEntry(e *models.AuditEntry) AuditEntry {
+	return AuditEntry{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		ActorID:   e.ActorID,
+		ActorRole: string(e.ActorRole),
+		Action:    string(e.Action),
+		Entity:    string(e.Entity),
+		EntityID:  e.EntityID,
+		Before:    e.Before,
+		After:     e.After,
+		RequestID: e.RequestID,
+	}
+}
+
+// ListAudit lists changes of users and tasks page by page.
+// @Summary List the audit log
+// @Description Changes are listed from the newest, pages are linked by cursors: pass nextCursor of a page to get the next one with the same filters.
+// @Tags audit
+// @Param entity query string false "Filter by entity" Enums(user, task)
+// @Param entityId query int false "Filter by entity ID, requires entity"
+// @Param actor query int false "Filter by ID of the user who made the change"
+// @Param from query string false "Changes made at or after the time, RFC 3339 or date"
+// @Param to query string false "Changes made before the time, RFC 3339 or date"
+// @Param limit query int false "Entries per page"
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} Response{data=ListAuditResponse} "Audit entries"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 422 {object} Problem "Invalid filter or cursor"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /audit [get]
+func (a *API) ListAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.AuditFilter{
+		Entity: models.AuditEntity(q.Get("entity")),
+		Cursor: q.Get("cursor"),
+	}
+
+	var err error
+	for name, v := range map[string]*int{
+		"entityId": &filter.EntityID,
+		"actor":    &filter.ActorID,
+		"limit":    &filter.Limit,
+	} {
+		if *v, err = queryInt(r, name); err != nil {
+			a.badRequest(w, r, err)
+			return
+		}
+	}
+
+	if filter.From, err = queryTime(r, "from"); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+	if filter.To, err = queryTime(r, "to"); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	log, err := a.service.ListAuditEntries(r.Context(), filter)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	resp := ListAuditResponse{
+		Entries:    make([]AuditEntry, len(log.Entries)),
+		NextCursor: log.NextCursor,
+	}
+	for i := range log.Entries {
+		resp.Entries[i] = newAuditEntry(&log.Entries[i])
+	}
+
+	a.writeResp(w, r, resp)
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAudit(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.listAuditEntriesFn = func(_ context.Context, filter models.AuditFilter) (*models.AuditLog, error) {
+		require.Equal(t, models.AuditFilter{
+			Entity:   models.AuditTask,
+			EntityID: 81,
+			ActorID:  51,
+			From:     time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+			Limit:    10,
+		}, filter)
+		return &models.AuditLog{NextCursor: "eyJhIjoxfQ", Entries: []models.AuditEntry{
+			{
+				ID:        2,
+				CreatedAt: time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC),
+				ActorID:   51,
+				ActorRole: models.RoleEmployee,
+				Action:    models.AuditUpdate,
+				Entity:    models.AuditTask,
+				EntityID:  81,
+				Before:    json.RawMessage(`{"title":"Fix"}`),
+				After:     json.RawMessage(`{"title":"Fix bug"}`),
+				RequestID: "req-42",
+			},
+			{
+				ID:        1,
+				CreatedAt: time.Date(2021, 10, 1, 11, 0, 0, 0, time.UTC),
+				Action:    models.AuditCreate,
+				Entity:    models.AuditTask,
+				EntityID:  81,
+				After:     json.RawMessage(`{"title":"Fix"}`),
+			},
+		}}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/audit?entity=task&entityId=81&actor=51&from=2021-10-01&limit=10")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {"nextCursor": "eyJhIjoxfQ", "entries": [
+		{
+			"id": 2, "createdAt": "2021-10-01T12:00:00Z", "actorId": 51, "actorRole": "employee",
+			"action": "update", "entity": "task", "entityId": 81,
+			"before": {"title": "Fix"}, "after": {"title": "Fix bug"}, "requestId": "req-42"
+		},
+		{
+			"id": 1, "createdAt": "2021-10-01T11:00:00Z", "action": "create", "entity": "task", "entityId": 81,
+			"before": null, "after": {"title": "Fix"}
+		}
+	]}}`, string(body))
+}
+
+func TestListAudit_Errors(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.listAuditEntriesFn = func(_ context.Context, filter models.AuditFilter) (*models.AuditLog, error) {
+		return nil, &usecase.Error{Kind: usecase.KindForbidden, Message: "access denied"}
+	}
+
+	for query, status := range map[string]int{
+		"/audit?entityId=x":  http.StatusBadRequest,
+		"/audit?from=monday": http.StatusBadRequest,
+		"/audit":             http.StatusForbidden,
+	} {
+		res, err := http.Get(srv.URL + query)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		require.Equal(t, status, res.StatusCode, query)
+		require.Equal(t, problemContentType, res.Header.Get("Content-Type"), query)
+	}
+}
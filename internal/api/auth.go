@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+)
+
+// publicPaths are served without authentication.
+var publicPaths = []string{"/livez", "/readyz", "/metrics", "/swagger/"}
+
+// feedPattern routes calendar feeds which are authorized by feed tokens in the URL.
+const feedPattern = "GET /users/{id}/tasks.ics"
+
+// orgHeader selects the organization a request of the admin key acts within,
+// requests of users act within the organization of their credentials.
+const orgHeader = "X-Org-ID"
+
+// Authenticate resolves the caller from an API key in the X-API-Key header or a bearer token
+// in the Authorization header and the organization the request acts within.
+// Requests without valid credentials or access to the organization are rejected.
+func (a *API) Authenticate(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern == feedPattern {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		for _, p := range publicPaths {
+			if r.URL.Path == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(r.URL.Path, p)) {
+				mux.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		var (
+			actor usecase.Actor
+			err   error
+		)
+		switch {
+		case r.Header.Get("X-API-Key") != "":
+			actor, err = a.service.Authenticate(r.Context(), r.Header.Get("X-API-Key"))
+		case strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "):
+			actor, err = a.service.AuthenticateToken(r.Context(), strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		default:
+			err = &usecase.Error{Kind: usecase.KindUnauthorized, Code: "unauthenticated", Message: "missing credentials"}
+		}
+		if err != nil {
+			if errors.Is(err, usecase.ErrUnauthorized) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="time-tracker"`)
+			}
+			a.writeErr(w, r, err)
+			return
+		}
+
+		var requested int
+		if v := r.Header.Get(orgHeader); v != "" {
+			if requested, err = strconv.Atoi(v); err != nil || requested <= 0 {
+				a.badRequest(w, r, fmt.Errorf("invalid %s header, must be a positive integer: %q", orgHeader, v))
+				return
+			}
+		}
+
+		ctx := usecase.WithActor(r.Context(), actor)
+		orgID, err := a.service.ResolveOrg(ctx, requested)
+		if err != nil {
+			a.writeErr(w, r, err)
+			return
+		}
+
+		mux.ServeHTTP(w, r.WithContext(tenant.WithOrg(ctx, orgID)))
+	})
+}
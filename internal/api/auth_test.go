@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuth(t *testing.T) (*httptest.Server, *serviceMock) {
+	mux := http.NewServeMux()
+	sm := &serviceMock{}
+
+	a := New(sm)
+	a.AddRoutes(mux)
+
+	srv := httptest.NewServer(a.Authenticate(mux))
+	t.Cleanup(srv.Close)
+
+	return srv, sm
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	srv, sm := setupAuth(t)
+
+	sm.authenticateFn = func(_ context.Context, key string) (usecase.Actor, error) {
+		require.Equal(t, "tt_secret", key)
+		return usecase.Actor{UserID: 51, Role: models.RoleEmployee}, nil
+	}
+	sm.getUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		actor, ok := usecase.ActorFrom(ctx)
+		require.True(t, ok)
+		require.Equal(t, usecase.Actor{UserID: 51, Role: models.RoleEmployee}, actor)
+		return &models.User{ID: id}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/users/51", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-API-Key", "tt_secret")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestAuthenticate_Token(t *testing.T) {
+	srv, sm := setupAuth(t)
+
+	sm.authenticateTokenFn = func(_ context.Context, token string) (usecase.Actor, error) {
+		require.Equal(t, "signed", token)
+		return usecase.Actor{UserID: 51, Role: models.RoleEmployee}, nil
+	}
+	sm.getUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return nil, &usecase.Error{Kind: usecase.KindForbidden, Code: "access_denied", Message: "access denied"}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/users/52", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer signed")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func TestAuthenticate_Unauthorized(t *testing.T) {
+	srv, sm := setupAuth(t)
+
+	sm.authenticateFn = func(_ context.Context, key string) (usecase.Actor, error) {
+		return usecase.Actor{}, usecase.ErrUnauthorized
+	}
+
+	res, err := http.Get(srv.URL + "/users/51")
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	require.NotEmpty(t, res.Header.Get("WWW-Authenticate"))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/users/51", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-API-Key", "tt_revoked")
+
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestAuthenticate_Public(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {})
+
+	srv := httptest.NewServer(New(&serviceMock{}).Authenticate(mux))
+	t.Cleanup(srv.Close)
+
+	res, err := http.Get(srv.URL + "/readyz")
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestAuthenticate_FeedPatternOnly(t *testing.T) {
+	srv, _ := setupAuth(t)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodPost, "/users/51/tasks.ics"},
+		{http.MethodGet, "/users/51/tasks/tasks.ics"},
+		{http.MethodGet, "/tasks.ics"},
+	} {
+		req, err := http.NewRequest(tc.method, srv.URL+tc.path, nil)
+		require.NoError(t, err)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode, "%s %s", tc.method, tc.path)
+	}
+}
+
+func TestAuthenticate_Org(t *testing.T) {
+	srv, sm := setupAuth(t)
+
+	sm.authenticateFn = func(_ context.Context, key string) (usecase.Actor, error) {
+		return usecase.Actor{Role: models.RoleAdmin}, nil
+	}
+	sm.resolveOrgFn = func(ctx context.Context, requested int) (int, error) {
+		_, ok := usecase.ActorFrom(ctx)
+		require.True(t, ok)
+		if requested == 3 {
+			return 0, usecase.ErrForbidden
+		}
+		require.Equal(t, 2, requested)
+		return requested, nil
+	}
+	sm.getUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		orgID, ok := tenant.OrgFrom(ctx)
+		require.True(t, ok)
+		require.Equal(t, 2, orgID)
+		return &models.User{ID: id}, nil
+	}
+
+	for _, tc := range []struct {
+		org  string
+		want int
+	}{
+		{org: "2", want: http.StatusOK},
+		{org: "3", want: http.StatusForbidden},
+		{org: "0", want: http.StatusBadRequest},
+		{org: "acme", want: http.StatusBadRequest},
+	} {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/users/51", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-API-Key", "tt_admin")
+		req.Header.Set("X-Org-ID", tc.org)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		require.Equal(t, tc.want, res.StatusCode, tc.org)
+	}
+}
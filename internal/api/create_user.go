@@ -15,8 +15,14 @@ type CreateUserRequest struct {
 // @Tags users
 // @Param passportNumber body CreateUserRequest true "Body"
 // @Success 201 "User created"
-// @Failure 400 "Bad request"
-// @Failure 500 "Internal server error"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 409 {object} Problem "User with the passport already exists"
+// @Failure 422 {object} Problem "Invalid passport number"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /users [post]
 func (a *API) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req CreateUserRequest
@@ -27,7 +33,7 @@ func (a *API) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := a.service.CreateUser(r.Context(), req.PassportNumber); err != nil {
-		a.internalServerError(w, r, err)
+		a.writeErr(w, r, err)
 		return
 	}
 
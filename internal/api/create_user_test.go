@@ -3,10 +3,13 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
 
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
 	"github.com/stretchr/testify/require"
 )
 
@@ -41,10 +44,85 @@ func TestCreateUser_BadRequest(t *testing.T) {
 
 	require.Equal(t, http.StatusBadRequest, res.StatusCode)
 
-	var resp Response
+	require.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+
+	var resp Problem
 	err = json.NewDecoder(res.Body).Decode(&resp)
 	require.NoError(t, err)
 
-	require.NotEmpty(t, resp.Error)
-	require.Contains(t, resp.Error, "json: cannot unmarshal number into Go struct field CreateUserRequest.passportNumber of type string")
+	require.Equal(t, "bad_request", resp.Code)
+	require.Equal(t, "/users", resp.Instance)
+	require.Contains(t, resp.Detail, "json: cannot unmarshal number into Go struct field CreateUserRequest.passportNumber of type string")
+}
+
+func TestCreateUser_Invalid(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.createUserFn = func(_ context.Context, passport string) error {
+		return &usecase.Error{
+			Kind:    usecase.KindValidation,
+			Code:    "invalid_passport",
+			Message: "invalid passport number",
+			Fields:  []usecase.FieldError{{Field: "passportNumber", Message: "invalid passport number"}},
+		}
+	}
+
+	res, err := http.Post(srv.URL+"/users", "application/json", strings.NewReader(`{"passportNumber": "12 34"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	require.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Unprocessable Entity",
+		"status": 422,
+		"detail": "invalid passport number",
+		"instance": "/users",
+		"code": "invalid_passport",
+		"errors": [{"field": "passportNumber", "message": "invalid passport number"}]
+	}`, string(body))
+}
+
+func TestCreateUser_PassportTaken(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.createUserFn = func(_ context.Context, passport string) error {
+		return &usecase.Error{Kind: usecase.KindConflict, Code: "passport_taken", Message: "user with the passport already exists"}
+	}
+
+	res, err := http.Post(srv.URL+"/users", "application/json", strings.NewReader(`{"passportNumber": "1234 567890"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+
+	var resp Problem
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+
+	require.Equal(t, "passport_taken", resp.Code)
+}
+
+func TestCreateUser_InternalError(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.createUserFn = func(_ context.Context, passport string) error {
+		return errors.New("connection refused")
+	}
+
+	res, err := http.Post(srv.URL+"/users", "application/json", strings.NewReader(`{"passportNumber": "1234 567890"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+	var resp Problem
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+
+	require.Equal(t, "internal_error", resp.Code)
+	require.NotContains(t, resp.Detail, "connection refused")
 }
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// heartbeatInterval is how often idle event streams get a comment, so proxies keep them open
+// and disconnected clients are noticed.
+var heartbeatInterval = 15 * time.Second
+
+// Event is the data of an event of the stream.
+type Event struct {
+	Type   string          `json:"type" example:"task.started"`
+	Time   time.Time       `json:"time"`
+	UserID int             `json:"userId,omitempty"`
+	TaskID int             `json:"taskId,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty" swaggertype:"object"` // snapshot of the task or the user after the change, of the deleted task before it
+}
+
+// Events streams live activity as Server-Sent Events.
+// @Summary Stream live activity
+// @Description Server-Sent Events of started (task.started), added (task.created), ended (task.ended), updated (task.updated) and deleted (task.deleted) tasks and created users (user.created), the id of each event is the ID of its audit entry.
+// @Description Clients resume after the event in the Last-Event-ID header, the reset event tells that missed events can't be replayed and the state must be reloaded.
+// @Description Admins get events of everyone, other users get their own events unless they filter by a user or team they may read. Comments are sent to idle streams as heartbeats.
+// @Description The stream is authenticated with the X-API-Key or Authorization header like other endpoints, so clients must be able to set headers, browser EventSource can't.
+// @Tags events
+// @Produce text/event-stream
+// @Param user query number false "User ID"
+// @Param team query number false "Team ID, events of its members"
+// @Param Last-Event-ID header string false "ID of the last received event"
+// @Success 200 {object} Event "Event stream"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User or team not found"
+// @Failure 422 {object} Problem "Invalid filter"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /events [get]
+func (a *API) Events(w http.ResponseWriter, r *http.Request) {
+	var (
+		filter models.EventFilter
+		err    error
+	)
+	for name, v := range map[string]*int{
+		"user": &filter.UserID,
+		"team": &filter.TeamID,
+	} {
+		if *v, err = queryInt(r, name); err != nil {
+			a.badRequest(w, r, err)
+			return
+		}
+	}
+
+	var lastEventID int
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		if lastEventID, err = strconv.Atoi(v); err != nil {
+			a.badRequest(w, r, fmt.Errorf("invalid Last-Event-ID header: %q", v))
+			return
+		}
+	}
+
+	sub, err := a.service.SubscribeEvents(r.Context(), filter, lastEventID)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+	defer sub.Close()
+
+	// the stream outlives the write timeout of the server
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logging.FromContext(r.Context()).Warn("Failed to clear write deadline of the event stream", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// nginx buffers responses by default
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, e := range sub.Replay() {
+		if err := writeEvent(w, &e); err != nil {
+			logging.FromContext(r.Context()).Debug("Event stream closed", "error", err)
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.Events():
+			if !ok {
+				// dropped subscribers resume after reconnecting
+				return
+			}
+			err = writeEvent(w, &e)
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			logging.FromContext(r.Context()).Debug("Event stream closed", "error", err)
+			return
+		}
+	}
+}
+
+func writeEvent(w io.Writer, e *models.Event) error {
+	data, err := json.Marshal(Event{
+		Type:   string(e.Type),
+		Time:   e.Time,
+		UserID: e.UserID,
+		TaskID: e.TaskID,
+		Data:   e.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
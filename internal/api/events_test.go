@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/audit"
+	"github.com/Nicholas2012/time-tracker/internal/events"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+// eventSource replays the entry after any ID.
+type eventSource struct {
+	entry models.AuditEntry
+}
+
+func (s eventSource) ListAuditEntries(context.Context, models.AuditFilter) (*models.AuditLog, error) {
+	return &models.AuditLog{Entries: []models.AuditEntry{s.entry}}, nil
+}
+
+func (s eventSource) ListAuditEntriesAfter(context.Context, int, int) ([]models.AuditEntry, error) {
+	return []models.AuditEntry{s.entry}, nil
+}
+
+func taskEntry(t *testing.T, id int, task *models.Task) *models.AuditEntry {
+	entry, err := audit.TaskEntry(context.Background(), models.AuditCreate, nil, task)
+	require.NoError(t, err)
+	entry.ID = id
+	entry.CreatedAt = time.Date(2024, 7, 10, 9, 0, 0, 0, time.UTC)
+	return entry
+}
+
+// readEvent reads lines of the stream up to the blank line ending an event or a comment.
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		if line == "\n" {
+			return lines
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+}
+
+func TestEvents_Stream(t *testing.T) {
+	srv, sm := setup(t)
+	defer srv.Close()
+
+	prev := heartbeatInterval
+	heartbeatInterval = 50 * time.Millisecond
+	t.Cleanup(func() { heartbeatInterval = prev })
+
+	since := time.Date(2024, 7, 10, 9, 0, 0, 0, time.UTC)
+	broker := events.NewBroker(eventSource{entry: *taskEntry(t, 6, &models.Task{ID: 81, UserID: 2, Title: "Report", Since: since})})
+	sm.subscribeEventsFn = func(_ context.Context, filter models.EventFilter, lastEventID int) (*events.Subscription, error) {
+		require.Equal(t, models.EventFilter{UserID: 2}, filter)
+		require.Equal(t, 5, lastEventID)
+		return broker.Subscribe(tenant.WithOrg(context.Background(), models.DefaultOrgID), lastEventID, func(*models.Event) bool { return true })
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/events?user=2", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "5")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	body := bufio.NewReader(res.Body)
+
+	// missed events are replayed first
+	replayed := readEvent(t, body)
+	require.Len(t, replayed, 3)
+	require.Equal(t, []string{"id: 6", "event: task.started"}, replayed[:2])
+	data, ok := strings.CutPrefix(replayed[2], "data: ")
+	require.True(t, ok)
+	require.JSONEq(t, `{
+		"type": "task.started", "time": "2024-07-10T09:00:00Z", "userId": 2, "taskId": 81,
+		"data": {
+			"id": 81, "userId": 2, "title": "Report", "description": "", "tagIds": [], "since": "2024-07-10T09:00:00Z",
+			"until": null, "minutes": 0, "segments": [], "autoStopped": false
+		}
+	}`, data)
+
+	broker.Publish(models.DefaultOrgID, taskEntry(t, 7, &models.Task{ID: 82, UserID: 2, Since: since}))
+	live := readEvent(t, body)
+	require.Equal(t, []string{"id: 7", "event: task.started"}, live[:2])
+
+	require.Equal(t, []string{": heartbeat"}, readEvent(t, body))
+}
+
+func TestEvents_Closed(t *testing.T) {
+	srv, sm := setup(t)
+	defer srv.Close()
+
+	broker := events.NewBroker(eventSource{})
+	sm.subscribeEventsFn = func(_ context.Context, filter models.EventFilter, lastEventID int) (*events.Subscription, error) {
+		require.Equal(t, models.EventFilter{TeamID: 3}, filter)
+		require.Equal(t, 9, lastEventID)
+		return broker.Subscribe(tenant.WithOrg(context.Background(), models.DefaultOrgID), 0, func(*models.Event) bool { return true })
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/events?team=3", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "9")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// streams end on shutdown
+	broker.Close()
+	_, err = bufio.NewReader(res.Body).ReadString('\n')
+	require.Error(t, err)
+}
+
+func TestEvents_Invalid(t *testing.T) {
+	srv, sm := setup(t)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "abc")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	sm.subscribeEventsFn = func(_ context.Context, filter models.EventFilter, lastEventID int) (*events.Subscription, error) {
+		return nil, usecase.ErrForbidden
+	}
+
+	res, err = http.Get(srv.URL + "/events?user=3")
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+type APIKey struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt"` // null for active key
+}
+
+func newAPIKey(k *models.APIKey) APIKey {
+	key := APIKey{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		CreatedAt: k.CreatedAt,
+	}
+	if !k.RevokedAt.IsZero() {
+		key.RevokedAt = &k.RevokedAt
+	}
+	return key
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"` // shown only once
+}
+
+type FeedTokenResponse struct {
+	Token string `json:"token"` // shown only once
+	URL   string `json:"url"`   // path of the feed with the token
+}
+
+type TokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CreateAPIKey issues a new API key for a user.
+// @Summary Create an API key
+// @Description The key is returned only in this response, it can't be recovered later.
+// @Tags auth
+// @Param id path number true "User ID"
+// @Param key body CreateAPIKeyRequest false "Key description"
+// @Success 201 {object} Response{data=CreateAPIKeyResponse} "Created key"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Keys of other users may be created only by admins"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/keys [post]
+func (a *API) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	key, secret, err := a.service.CreateAPIKey(r.Context(), userID, req.Name)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	a.writeResp(w, r, CreateAPIKeyResponse{APIKey: newAPIKey(key), Key: secret})
+}
+
+// ListAPIKeys lists API keys of a user including revoked ones.
+// @Summary List API keys
+// @Tags auth
+// @Param id path number true "User ID"
+// @Success 200 {object} Response{data=[]APIKey} "Keys"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Keys of other users may be listed only by admins"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/keys [get]
+func (a *API) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	keys, err := a.service.ListAPIKeys(r.Context(), userID)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	resp := make([]APIKey, len(keys))
+	for i := range keys {
+		resp[i] = newAPIKey(&keys[i])
+	}
+
+	a.writeResp(w, r, resp)
+}
+
+// RevokeAPIKey revokes an API key of a user.
+// @Summary Revoke an API key
+// @Tags auth
+// @Param id path number true "User ID"
+// @Param keyID path number true "Key ID"
+// @Success 200 {object} Response "Key revoked"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Keys of other users may be revoked only by admins"
+// @Failure 404 {object} Problem "Active key not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/keys/{keyID} [delete]
+func (a *API) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	keyID, err := pathInt(r, "keyID")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	if err := a.service.RevokeAPIKey(r.Context(), userID, keyID); err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, nil)
+}
+
+// IssueToken exchanges credentials of the request for a short-lived bearer token.
+// @Summary Issue a bearer token
+// @Description The token is bound to the key of the request, it stops working once the key is revoked, the user is deleted or the admin key is changed. Changes of the role of the user apply to it at once.
+// @Tags auth
+// @Success 200 {object} Response{data=TokenResponse} "Token"
+// @Failure 401 {object} Problem "Invalid credentials"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /auth/token [post]
+func (a *API) IssueToken(w http.ResponseWriter, r *http.Request) {
+	token, expires, err := a.service.IssueToken(r.Context())
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, TokenResponse{Token: token, ExpiresAt: expires})
+}
+
+// IssueFeedToken issues a new calendar feed token for a user, the previous token stops working.
+// @Summary Issue a calendar feed token
+// @Description The token is returned only in this response, it can't be recovered later. It grants read access to GET /users/{id}/tasks.ics only.
+// @Tags auth
+// @Param id path number true "User ID"
+// @Success 201 {object} Response{data=FeedTokenResponse} "Feed token"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Feed tokens of other users may be issued only by admins"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/feed-token [post]
+func (a *API) IssueFeedToken(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	token, err := a.service.IssueFeedToken(r.Context(), userID)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	orgID, _ := tenant.OrgFrom(r.Context())
+
+	w.WriteHeader(http.StatusCreated)
+	a.writeResp(w, r, FeedTokenResponse{
+		Token: token,
+		URL:   fmt.Sprintf("/users/%d/tasks.ics?token=%s&org=%d", userID, url.QueryEscape(token), orgID),
+	})
+}
+
+// RevokeFeedToken revokes the calendar feed token of a user.
+// @Summary Revoke the calendar feed token
+// @Tags auth
+// @Param id path number true "User ID"
+// @Success 200 {object} Response "Token revoked"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Feed tokens of other users may be revoked only by admins"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/feed-token [delete]
+func (a *API) RevokeFeedToken(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	if err := a.service.RevokeFeedToken(r.Context(), userID); err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, nil)
+}
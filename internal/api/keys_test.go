@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	created := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	sm.createAPIKeyFn = func(_ context.Context, userID int, name string) (*models.APIKey, string, error) {
+		require.Equal(t, 51, userID)
+		require.Equal(t, "laptop", name)
+		return &models.APIKey{ID: 3, UserID: userID, Name: name, Prefix: "tt_abcdefgh", CreatedAt: created}, "tt_abcdefghsecret", nil
+	}
+
+	res, err := http.Post(srv.URL+"/users/51/keys", "application/json", strings.NewReader(`{"name": "laptop"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"id": 3, "name": "laptop", "prefix": "tt_abcdefgh", "createdAt": "2021-10-01T00:00:00Z", "revokedAt": null, "key": "tt_abcdefghsecret"}}`, string(body))
+}
+
+func TestListAPIKeys_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	created := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	sm.listAPIKeysFn = func(_ context.Context, userID int) ([]models.APIKey, error) {
+		return []models.APIKey{{ID: 3, UserID: userID, Prefix: "tt_abcdefgh", Hash: []byte("hash"), CreatedAt: created, RevokedAt: created}}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/users/51/keys")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": [{"id": 3, "name": "", "prefix": "tt_abcdefgh", "createdAt": "2021-10-01T00:00:00Z", "revokedAt": "2021-10-01T00:00:00Z"}]}`, string(body))
+}
+
+func TestRevokeAPIKey_Forbidden(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.revokeAPIKeyFn = func(_ context.Context, userID, keyID int) error {
+		require.Equal(t, 51, userID)
+		require.Equal(t, 3, keyID)
+		return &usecase.Error{Kind: usecase.KindForbidden, Code: "access_denied", Message: "access denied"}
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL+"/users/51/keys/3", nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func TestIssueToken_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	expires := time.Date(2021, 10, 1, 1, 0, 0, 0, time.UTC)
+	sm.issueTokenFn = func(_ context.Context) (string, time.Time, error) {
+		return "signed", expires, nil
+	}
+
+	res, err := http.Post(srv.URL+"/auth/token", "", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"token": "signed", "expiresAt": "2021-10-01T01:00:00Z"}}`, string(body))
+}
+
+func TestIssueFeedToken(t *testing.T) {
+	srv, sm := setupAuth(t)
+
+	sm.authenticateFn = func(_ context.Context, key string) (usecase.Actor, error) {
+		return usecase.Actor{UserID: 51, Role: models.RoleEmployee, OrgID: 3}, nil
+	}
+	sm.resolveOrgFn = func(ctx context.Context, requested int) (int, error) {
+		return 3, nil
+	}
+	sm.issueFeedTokenFn = func(_ context.Context, userID int) (string, error) {
+		require.Equal(t, 51, userID)
+		return "ttf_a+b", nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/users/51/feed-token", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-API-Key", "tt_secret")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	// the feed is requested without credentials, so its URL tells the organization
+	require.JSONEq(t, `{"data": {"token": "ttf_a+b", "url": "/users/51/tasks.ics?token=ttf_a%2Bb&org=3"}}`, string(body))
+}
+
+func TestRevokeFeedToken(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.revokeFeedTokenFn = func(_ context.Context, userID int) error {
+		require.Equal(t, 51, userID)
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL+"/users/51/feed-token", nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/metrics"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+
+	// maxRequestIDLength limits IDs taken from clients, longer ones are replaced.
+	maxRequestIDLength = 128
+)
+
+// Log assigns each request an ID, propagating the one sent in X-Request-ID if it's valid, and returns it in the response.
+// Handlers get the ID from logging.RequestID and a logger carrying it from logging.FromContext. A line is logged with logger when each request is served,
+// requests to untraced paths are logged at debug level.
+func Log(mux *http.ServeMux, logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		reqLogger := logger.With("request_id", id)
+		if span := trace.SpanContextFromContext(r.Context()); span.IsValid() {
+			reqLogger = reqLogger.With("trace_id", span.TraceID().String())
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		defer func() {
+			level := slog.LevelInfo
+			if isUntraced(r) {
+				level = slog.LevelDebug
+			}
+
+			reqLogger.LogAttrs(r.Context(), level, "Request served",
+				slog.String("method", r.Method),
+				slog.String("route", metrics.Route(mux, r)),
+				slog.Int("status", rec.status),
+				slog.Int64("bytes", rec.bytes),
+				slog.Duration("duration", time.Since(start)),
+			)
+		}()
+
+		ctx := logging.WithRequestID(r.Context(), id)
+		next.ServeHTTP(rec, r.WithContext(logging.WithLogger(ctx, reqLogger)))
+	})
+}
+
+// validRequestID reports whether the ID sent by a client is safe to log and return.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// responseRecorder remembers the status and the size of the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *responseRecorder) Flush() {
+	r.wroteHeader = true
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach other features of the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
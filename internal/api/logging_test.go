@@ -0,0 +1,110 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLog(t *testing.T) (*httptest.Server, *serviceMock, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	mux := http.NewServeMux()
+	sm := &serviceMock{}
+	New(sm).AddRoutes(mux)
+
+	srv := httptest.NewServer(Log(mux, logger, mux))
+	t.Cleanup(srv.Close)
+
+	return srv, sm, &buf
+}
+
+// records decodes JSON log lines.
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var res []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		res = append(res, rec)
+	}
+	return res
+}
+
+func TestLog(t *testing.T) {
+	srv, sm, buf := setupLog(t)
+
+	sm.getUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		require.Equal(t, "req-42", logging.RequestID(ctx))
+		logging.FromContext(ctx).Info("Service called")
+		return &models.User{ID: id}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/users/51", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Request-ID", "req-42")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "req-42", res.Header.Get("X-Request-ID"))
+
+	recs := records(t, buf)
+	require.Len(t, recs, 2)
+
+	require.Equal(t, "Service called", recs[0]["msg"])
+	require.Equal(t, "req-42", recs[0]["request_id"])
+
+	access := recs[1]
+	require.Equal(t, "Request served", access["msg"])
+	require.Equal(t, "INFO", access["level"])
+	require.Equal(t, "req-42", access["request_id"])
+	require.Equal(t, "GET", access["method"])
+	require.Equal(t, "/users/{id}", access["route"])
+	require.EqualValues(t, http.StatusOK, access["status"])
+	require.EqualValues(t, res.ContentLength, access["bytes"])
+	require.Contains(t, access, "duration")
+}
+
+func TestLog_GeneratedID(t *testing.T) {
+	srv, _, buf := setupLog(t)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/users/abc", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Request-ID", "bad id\twith spaces")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	id := res.Header.Get("X-Request-ID")
+	require.Len(t, id, 32)
+
+	recs := records(t, buf)
+	access := recs[len(recs)-1]
+	require.Equal(t, id, access["request_id"])
+	require.EqualValues(t, http.StatusBadRequest, access["status"])
+}
+
+func TestLog_Probe(t *testing.T) {
+	srv, _, buf := setupLog(t)
+
+	res, err := http.Get(srv.URL + "/livez")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	recs := records(t, buf)
+	require.Len(t, recs, 1)
+	require.Equal(t, "DEBUG", recs[0]["level"])
+}
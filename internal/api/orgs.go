@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+type Org struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newOrgs(orgs []models.Org) []Org {
+	resp := make([]Org, len(orgs))
+	for i, o := range orgs {
+		resp[i] = Org{ID: o.ID, Name: o.Name}
+	}
+	return resp
+}
+
+type OrgRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateOrg creates a new organization, only the admin key may.
+// @Summary Create an organization
+// @Description Operators holding the admin key act within an organization by passing its ID in the X-Org-ID header.
+// @Tags orgs
+// @Param org body OrgRequest true "Organization"
+// @Success 201 {object} Response{data=Org} "Organization created"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 409 {object} Problem "Organization already exists"
+// @Failure 422 {object} Problem "Invalid organization"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /orgs [post]
+func (a *API) CreateOrg(w http.ResponseWriter, r *http.Request) {
+	var req OrgRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	org, err := a.service.CreateOrg(r.Context(), req.Name)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	a.writeResp(w, r, Org{ID: org.ID, Name: org.Name})
+}
+
+// ListOrgs lists all organizations, only the admin key may.
+// @Summary List organizations
+// @Tags orgs
+// @Success 200 {object} Response{data=[]Org} "Organizations"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /orgs [get]
+func (a *API) ListOrgs(w http.ResponseWriter, r *http.Request) {
+	orgs, err := a.service.ListOrgs(r.Context())
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, newOrgs(orgs))
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrg_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.createOrgFn = func(_ context.Context, name string) (*models.Org, error) {
+		require.Equal(t, "Acme", name)
+		return &models.Org{ID: 2, Name: name}, nil
+	}
+
+	res, err := http.Post(srv.URL+"/orgs", "application/json", strings.NewReader(`{"name": "Acme"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"id": 2, "name": "Acme"}}`, string(body))
+}
+
+func TestListOrgs_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.listOrgsFn = func(_ context.Context) ([]models.Org, error) {
+		return []models.Org{{ID: 1, Name: "Default"}, {ID: 2, Name: "Acme"}}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/orgs")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": [{"id": 1, "name": "Default"}, {"id": 2, "name": "Acme"}]}`, string(body))
+}
+
+func TestListOrgs_Forbidden(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.listOrgsFn = func(_ context.Context) ([]models.Org, error) {
+		return nil, usecase.ErrForbidden
+	}
+
+	res, err := http.Get(srv.URL + "/orgs")
+	require.NoError(t, err)
+	res.Body.Close()
+
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+}
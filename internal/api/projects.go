@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+)
+
+type Project struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func newProject(p models.Project) Project {
+	return Project{ID: p.ID, Name: p.Name, Description: p.Description}
+}
+
+type CreateProjectRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UpdateProjectRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// CreateProject creates a new project.
+// @Summary Create a project
+// @Tags projects
+// @Param project body CreateProjectRequest true "Project"
+// @Success 201 {object} Response{data=Project} "Project created"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 409 {object} Problem "Project with the name already exists"
+// @Failure 422 {object} Problem "Invalid project"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /projects [post]
+func (a *API) CreateProject(w http.ResponseWriter, r *http.Request) {
+	var req CreateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	project, err := a.service.CreateProject(r.Context(), req.Name, req.Description)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	a.writeResp(w, r, newProject(*project))
+}
+
+// ListProjects lists all projects.
+// @Summary List projects
+// @Tags projects
+// @Success 200 {object} Response{data=[]Project} "Projects"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /projects [get]
+func (a *API) ListProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := a.service.ListProjects(r.Context())
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	resp := make([]Project, len(projects))
+	for i, p := range projects {
+		resp[i] = newProject(p)
+	}
+
+	a.writeResp(w, r, resp)
+}
+
+// GetProject returns a project by ID.
+// @Summary Get a project
+// @Tags projects
+// @Param id path number true "Project ID"
+// @Success 200 {object} Response{data=Project} "Project"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "Project not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /projects/{id} [get]
+func (a *API) GetProject(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	project, err := a.service.GetProject(r.Context(), id)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, newProject(*project))
+}
+
+// UpdateProject changes fields of a project which are present in the body.
+// @Summary Update a project
+// @Tags projects
+// @Param id path number true "Project ID"
+// @Param project body UpdateProjectRequest true "Fields to update"
+// @Success 200 {object} Response{data=Project} "Updated project"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "Project not found"
+// @Failure 409 {object} Problem "Project with the name already exists"
+// @Failure 422 {object} Problem "Invalid project"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /projects/{id} [patch]
+func (a *API) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	var req UpdateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	project, err := a.service.UpdateProject(r.Context(), id, usecase.ProjectUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, newProject(*project))
+}
+
+// DeleteProject deletes a project, its tasks are kept without a project.
+// @Summary Delete a project
+// @Tags projects
+// @Param id path number true "Project ID"
+// @Success 200 {object} Response "Project deleted"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "Project not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /projects/{id} [delete]
+func (a *API) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	if err := a.service.DeleteProject(r.Context(), id); err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, nil)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProject_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.createProjectFn = func(_ context.Context, name, description string) (*models.Project, error) {
+		require.Equal(t, "Time tracker", name)
+		require.Equal(t, "Internal", description)
+		return &models.Project{ID: 3, Name: name, Description: description}, nil
+	}
+
+	res, err := http.Post(srv.URL+"/projects", "application/json", strings.NewReader(`{"name": "Time tracker", "description": "Internal"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"id": 3, "name": "Time tracker", "description": "Internal"}}`, string(body))
+}
+
+func TestCreateProject_MissingName(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.createProjectFn = func(_ context.Context, name, description string) (*models.Project, error) {
+		return nil, &usecase.Error{Kind: usecase.KindValidation, Code: "name_required", Message: "name is required"}
+	}
+
+	res, err := http.Post(srv.URL+"/projects", "application/json", strings.NewReader(`{"description": "Internal"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+}
+
+func TestCreateProject_Conflict(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.createProjectFn = func(_ context.Context, name, description string) (*models.Project, error) {
+		return nil, fmt.Errorf("project %q: %w", name, usecase.ErrConflict)
+	}
+
+	res, err := http.Post(srv.URL+"/projects", "application/json", strings.NewReader(`{"name": "Time tracker"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+}
+
+func TestListProjects_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.listProjectsFn = func(_ context.Context) ([]models.Project, error) {
+		return []models.Project{{ID: 3, Name: "Time tracker"}}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/projects")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": [{"id": 3, "name": "Time tracker", "description": ""}]}`, string(body))
+}
+
+func TestGetProject_NotFound(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.getProjectFn = func(_ context.Context, id int) (*models.Project, error) {
+		require.Equal(t, 3, id)
+		return nil, usecase.ErrNotFound
+	}
+
+	res, err := http.Get(srv.URL + "/projects/3")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestUpdateProject_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.updateProjectFn = func(_ context.Context, id int, upd usecase.ProjectUpdate) (*models.Project, error) {
+		require.Equal(t, 3, id)
+		require.Nil(t, upd.Name)
+		require.Equal(t, "Public", *upd.Description)
+		return &models.Project{ID: 3, Name: "Time tracker", Description: "Public"}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, srv.URL+"/projects/3", strings.NewReader(`{"description": "Public"}`))
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestDeleteProject_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.deleteProjectFn = func(_ context.Context, id int) error {
+		require.Equal(t, 3, id)
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL+"/projects/3", nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
@@ -2,14 +2,66 @@ package api
 
 import (
 	"context"
+	"time"
 
+	"github.com/Nicholas2012/time-tracker/internal/events"
 	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
 )
 
 type Service interface {
+	Authenticate(ctx context.Context, key string) (usecase.Actor, error)
+	AuthenticateToken(ctx context.Context, token string) (usecase.Actor, error)
+	IssueToken(ctx context.Context) (string, time.Time, error)
+	CreateAPIKey(ctx context.Context, userID int, name string) (*models.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID int) error
+	IssueFeedToken(ctx context.Context, userID int) (string, error)
+	RevokeFeedToken(ctx context.Context, userID int) error
+	ResolveOrg(ctx context.Context, requested int) (int, error)
+
+	CreateOrg(ctx context.Context, name string) (*models.Org, error)
+	ListOrgs(ctx context.Context) ([]models.Org, error)
+
 	CreateUser(ctx context.Context, passportNumber string) error
+	GetUser(ctx context.Context, id int) (*models.User, error)
+	GetUserByPassport(ctx context.Context, passport string) (*models.User, error)
+	ListUsers(ctx context.Context, opts models.UserListOpts) (*models.UserList, error)
+	UpdateUser(ctx context.Context, id int, upd usecase.UserUpdate) (*models.User, error)
+	DeleteUser(ctx context.Context, id int) error
+	RevealPassports(ctx context.Context) error
 
-	StartTask(ctx context.Context, userID int) (int, error)
+	StartTask(ctx context.Context, userID int, info usecase.TaskInfo) (int, error)
+	SwitchTask(ctx context.Context, userID int, info usecase.TaskInfo) (int, int, error)
 	EndTask(ctx context.Context, userID, taskID int) error
-	ListTasks(ctx context.Context, userID int) ([]models.Task, error)
+	PauseTask(ctx context.Context, userID, taskID int) error
+	ResumeTask(ctx context.Context, userID, taskID int) error
+	CreateTask(ctx context.Context, userID int, entry usecase.TaskEntry) (*models.Task, error)
+	UpdateTask(ctx context.Context, userID, taskID int, upd usecase.TaskUpdate) (*models.Task, error)
+	ListTasks(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error)
+	TaskFeed(ctx context.Context, userID int, token string) ([]models.Task, error)
+	Workload(ctx context.Context, userID int, from, to time.Time) (*models.Workload, error)
+	Timesheet(ctx context.Context, userID int, from, to time.Time, fn func(*models.TimesheetRow) error) error
+
+	CreateProject(ctx context.Context, name, description string) (*models.Project, error)
+	GetProject(ctx context.Context, id int) (*models.Project, error)
+	ListProjects(ctx context.Context) ([]models.Project, error)
+	UpdateProject(ctx context.Context, id int, upd usecase.ProjectUpdate) (*models.Project, error)
+	DeleteProject(ctx context.Context, id int) error
+
+	CreateTag(ctx context.Context, name string) (*models.Tag, error)
+	GetTag(ctx context.Context, id int) (*models.Tag, error)
+	ListTags(ctx context.Context) ([]models.Tag, error)
+	UpdateTag(ctx context.Context, id int, name string) (*models.Tag, error)
+	DeleteTag(ctx context.Context, id int) error
+
+	CreateTeam(ctx context.Context, info usecase.TeamInfo) (*models.Team, error)
+	ListTeams(ctx context.Context) ([]models.Team, error)
+	GetTeam(ctx context.Context, id int) (*models.Team, error)
+	UpdateTeam(ctx context.Context, id int, upd usecase.TeamUpdate) (*models.Team, error)
+	DeleteTeam(ctx context.Context, id int) error
+	TeamReport(ctx context.Context, teamID int, from, to time.Time) (*models.TeamReport, error)
+
+	ListAuditEntries(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error)
+	SubscribeEvents(ctx context.Context, filter models.EventFilter, lastEventID int) (*events.Subscription, error)
 }
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+type Tag struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newTags(tags []models.Tag) []Tag {
+	resp := make([]Tag, len(tags))
+	for i, t := range tags {
+		resp[i] = Tag{ID: t.ID, Name: t.Name}
+	}
+	return resp
+}
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateTag creates a new tag.
+// @Summary Create a tag
+// @Tags tags
+// @Param tag body TagRequest true "Tag"
+// @Success 201 {object} Response{data=Tag} "Tag created"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 409 {object} Problem "Tag already exists"
+// @Failure 422 {object} Problem "Invalid tag"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /tags [post]
+func (a *API) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	tag, err := a.service.CreateTag(r.Context(), req.Name)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	a.writeResp(w, r, Tag{ID: tag.ID, Name: tag.Name})
+}
+
+// ListTags lists all tags.
+// @Summary List tags
+// @Tags tags
+// @Success 200 {object} Response{data=[]Tag} "Tags"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /tags [get]
+func (a *API) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := a.service.ListTags(r.Context())
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, newTags(tags))
+}
+
+// GetTag returns a tag by ID.
+// @Summary Get a tag
+// @Tags tags
+// @Param id path number true "Tag ID"
+// @Success 200 {object} Response{data=Tag} "Tag"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "Tag not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /tags/{id} [get]
+func (a *API) GetTag(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	tag, err := a.service.GetTag(r.Context(), id)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, Tag{ID: tag.ID, Name: tag.Name})
+}
+
+// UpdateTag renames a tag.
+// @Summary Rename a tag
+// @Tags tags
+// @Param id path number true "Tag ID"
+// @Param tag body TagRequest true "Tag"
+// @Success 200 {object} Response{data=Tag} "Updated tag"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "Tag not found"
+// @Failure 409 {object} Problem "Tag already exists"
+// @Failure 422 {object} Problem "Invalid tag"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /tags/{id} [patch]
+func (a *API) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	tag, err := a.service.UpdateTag(r.Context(), id, req.Name)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, Tag{ID: tag.ID, Name: tag.Name})
+}
+
+// DeleteTag deletes a tag and removes it from all tasks.
+// @Summary Delete a tag
+// @Tags tags
+// @Param id path number true "Tag ID"
+// @Success 200 {object} Response "Tag deleted"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "Tag not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /tags/{id} [delete]
+func (a *API) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	if err := a.service.DeleteTag(r.Context(), id); err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, nil)
+}
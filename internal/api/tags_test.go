@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTag_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.createTagFn = func(_ context.Context, name string) (*models.Tag, error) {
+		require.Equal(t, "bug", name)
+		return &models.Tag{ID: 4, Name: name}, nil
+	}
+
+	res, err := http.Post(srv.URL+"/tags", "application/json", strings.NewReader(`{"name": "bug"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"id": 4, "name": "bug"}}`, string(body))
+}
+
+func TestListTags_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.listTagsFn = func(_ context.Context) ([]models.Tag, error) {
+		return []models.Tag{{ID: 4, Name: "bug"}, {ID: 5, Name: "feature"}}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/tags")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": [{"id": 4, "name": "bug"}, {"id": 5, "name": "feature"}]}`, string(body))
+}
+
+func TestUpdateTag_Conflict(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.updateTagFn = func(_ context.Context, id int, name string) (*models.Tag, error) {
+		require.Equal(t, 4, id)
+		require.Equal(t, "feature", name)
+		return nil, usecase.ErrConflict
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, srv.URL+"/tags/4", strings.NewReader(`{"name": "feature"}`))
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+}
+
+func TestDeleteTag_NotFound(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.deleteTagFn = func(_ context.Context, id int) error {
+		return usecase.ErrNotFound
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL+"/tags/4", nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
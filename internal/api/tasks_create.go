@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+)
+
+type CreateTaskRequest struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	ProjectID   int       `json:"projectId"`
+	TagIDs      []int     `json:"tagIds"`
+	Since       time.Time `json:"since"`
+	Until       time.Time `json:"until"`
+}
+
+// CreateTask logs a finished task with explicit bounds
+// @Summary Log a finished task
+// @Description The task must be in the past and must not overlap other tasks of the user.
+// @Tags tasks
+// @Param id path number true "User ID"
+// @Param task body CreateTaskRequest true "Task"
+// @Success 201 {object} Response{data=Task} "Created task"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 409 {object} Problem "Task overlaps another task"
+// @Failure 422 {object} Problem "Invalid task bounds, unknown project or tag"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/tasks [post]
+func (a *API) CreateTask(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	var req CreateTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	task, err := a.service.CreateTask(r.Context(), userID, usecase.TaskEntry{
+		TaskInfo: usecase.TaskInfo{
+			Title:       req.Title,
+			Description: req.Description,
+			ProjectID:   req.ProjectID,
+			TagIDs:      req.TagIDs,
+		},
+		Since: req.Since,
+		Until: req.Until,
+	})
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	a.writeResp(w, r, newTask(task))
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTasksCreate_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	since := time.Date(2021, 10, 1, 9, 0, 0, 0, time.UTC)
+	until := time.Date(2021, 10, 1, 10, 30, 0, 0, time.UTC)
+
+	sm.createTaskFn = func(_ context.Context, userID int, entry usecase.TaskEntry) (*models.Task, error) {
+		require.Equal(t, 51, userID)
+		require.Equal(t, usecase.TaskEntry{
+			TaskInfo: usecase.TaskInfo{Title: "Standup", TagIDs: []int{2}},
+			Since:    since,
+			Until:    until,
+		}, entry)
+
+		return &models.Task{
+			ID:       90,
+			UserID:   userID,
+			Title:    entry.Title,
+			Tags:     []models.Tag{{ID: 2, Name: "meeting"}},
+			Since:    since,
+			Until:    until,
+			Minutes:  90,
+			Segments: []models.TaskSegment{{Since: since, Until: until}},
+		}, nil
+	}
+
+	body := `{"title": "Standup", "tagIds": [2], "since": "2021-10-01T09:00:00Z", "until": "2021-10-01T10:30:00Z"}`
+	res, err := http.Post(srv.URL+"/users/51/tasks", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	resp, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {
+		"id": 90, "title": "Standup", "description": "", "tags": [{"id": 2, "name": "meeting"}],
+		"since": "2021-10-01T09:00:00Z", "until": "2021-10-01T10:30:00Z", "minutes": 90, "state": "finished",
+		"segments": [{"since": "2021-10-01T09:00:00Z", "until": "2021-10-01T10:30:00Z"}], "autoStopped": false
+	}}`, string(resp))
+}
+
+func TestTasksCreate_Overlap(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.createTaskFn = func(_ context.Context, userID int, entry usecase.TaskEntry) (*models.Task, error) {
+		return nil, &usecase.Error{Kind: usecase.KindConflict, Code: "task_overlap", Message: "task overlaps task 7"}
+	}
+
+	body := `{"since": "2021-10-01T09:00:00Z", "until": "2021-10-01T10:30:00Z"}`
+	res, err := http.Post(srv.URL+"/users/51/tasks", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+}
+
+func TestTasksCreate_BadRequest(t *testing.T) {
+	srv, _ := setup(t)
+
+	res, err := http.Post(srv.URL+"/users/51/tasks", "application/json", strings.NewReader(`{"since": "yesterday"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
@@ -11,9 +11,15 @@ import (
 // @Tags tasks
 // @Param id path number true "User ID"
 // @Param taskID path number true "Task ID"
-// @Success 200 "Task started"
-// @Failure 400 "Bad request"
-// @Failure 500 "Internal server error"
+// @Success 200 "Task ended"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User or task not found"
+// @Failure 409 {object} Problem "Task is already finished"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /users/{id}/tasks/{taskID}/end [post]
 func (a *API) EndTask(w http.ResponseWriter, r *http.Request) {
 	userStr := r.PathValue("id")
@@ -41,7 +47,7 @@ func (a *API) EndTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := a.service.EndTask(r.Context(), userID, taskID); err != nil {
-		a.internalServerError(w, r, err)
+		a.writeErr(w, r, err)
 		return
 	}
 
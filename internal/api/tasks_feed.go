@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+const (
+	icsContentType = "text/calendar; charset=utf-8"
+	icsTimeLayout  = "20060102T150405Z"
+	icsLineLength  = 75 // octets, longer lines are folded
+)
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "")
+
+// TaskFeed renders tasks of a user as an iCalendar feed.
+// @Summary Calendar feed of user tasks
+// @Description Every task of the last 3 months is an event with UID task-{id}@time-tracker. Running and paused tasks have no end and are tentative, they are in the feed whenever they started.
+// @Description The feed is authorized by the feed token of the user instead of credentials, see POST /users/{id}/feed-token.
+// @Tags tasks
+// @Produce text/calendar
+// @Param id path number true "User ID"
+// @Param token query string true "Feed token"
+// @Param org query number true "Organization of the user"
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Invalid feed token"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /users/{id}/tasks.ics [get]
+func (a *API) TaskFeed(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	// feeds are requested without credentials, the organization is a part of the URL of the feed
+	orgID, err := queryInt(r, "org")
+	if err != nil || orgID <= 0 {
+		a.badRequest(w, r, fmt.Errorf("invalid org: %q", r.URL.Query().Get("org")))
+		return
+	}
+
+	tasks, err := a.service.TaskFeed(tenant.WithOrg(r.Context(), orgID), userID, r.URL.Query().Get("token"))
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", icsContentType)
+
+	cal := &icsWriter{w: bufio.NewWriter(w)}
+	cal.line("BEGIN:VCALENDAR")
+	cal.line("VERSION:2.0")
+	cal.line("PRODID:-//time-tracker//tasks//EN")
+	cal.line("CALSCALE:GREGORIAN")
+	cal.line("X-WR-CALNAME:Tracked time")
+	for i := range tasks {
+		writeTaskEvent(cal, &tasks[i])
+	}
+	cal.line("END:VCALENDAR")
+
+	if err := cal.w.Flush(); err != nil {
+		a.internalServerError(w, r, err)
+	}
+}
+
+func writeTaskEvent(cal *icsWriter, t *models.Task) {
+	summary := t.Title
+	if summary == "" {
+		summary = "Task " + strconv.Itoa(t.ID)
+	}
+
+	cal.line("BEGIN:VEVENT")
+	cal.line("UID:task-" + strconv.Itoa(t.ID) + "@time-tracker")
+	if t.IsFinished() {
+		// the task doesn't change after it is finished, so the feed stays the same between requests
+		cal.line("DTSTAMP:" + icsTime(t.Until))
+		cal.line("DTSTART:" + icsTime(t.Since))
+		cal.line("DTEND:" + icsTime(t.Until))
+		cal.line("STATUS:CONFIRMED")
+	} else {
+		cal.line("DTSTAMP:" + icsTime(t.Since))
+		cal.line("DTSTART:" + icsTime(t.Since))
+		cal.line("STATUS:TENTATIVE")
+	}
+	cal.line("SUMMARY:" + icsEscaper.Replace(summary))
+	if t.Description != "" {
+		cal.line("DESCRIPTION:" + icsEscaper.Replace(t.Description))
+	}
+	cal.line("X-TIME-TRACKER-STATE:" + strings.ToUpper(string(t.State())))
+	cal.line("END:VEVENT")
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format(icsTimeLayout)
+}
+
+// icsWriter writes content lines terminated by CRLF and folds them as RFC 5545 requires.
+type icsWriter struct {
+	w *bufio.Writer
+}
+
+func (c *icsWriter) line(s string) {
+	limit := icsLineLength
+	for len(s) > limit {
+		// don't split multi-byte characters
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+
+		c.w.WriteString(s[:cut])
+		c.w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = icsLineLength - 1 // continuation lines start with a space
+	}
+
+	c.w.WriteString(s)
+	c.w.WriteString("\r\n")
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskFeed_OK(t *testing.T) {
+	srv, sm := setupAuth(t)
+
+	sm.taskFeedFn = func(ctx context.Context, userID int, token string) ([]models.Task, error) {
+		require.Equal(t, 51, userID)
+		require.Equal(t, "ttf_secret", token)
+		orgID, _ := tenant.OrgFrom(ctx)
+		require.Equal(t, 3, orgID)
+
+		moscow := time.FixedZone("MSK", 3*60*60)
+		return []models.Task{
+			{
+				ID:          81,
+				Title:       "Fix bug; again, and again",
+				Description: "Line one\nLine two",
+				Since:       time.Date(2021, 10, 1, 12, 0, 0, 0, moscow),
+				Until:       time.Date(2021, 10, 1, 13, 30, 0, 0, moscow),
+			},
+			{
+				ID:       82,
+				Since:    time.Date(2021, 10, 1, 11, 0, 0, 0, time.UTC),
+				Segments: []models.TaskSegment{{Since: time.Date(2021, 10, 1, 11, 0, 0, 0, time.UTC)}},
+			},
+		}, nil
+	}
+
+	// no credentials besides the feed token
+	res, err := http.Get(srv.URL + "/users/51/tasks.ics?token=ttf_secret&org=3")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, icsContentType, res.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//time-tracker//tasks//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Tracked time",
+		"BEGIN:VEVENT",
+		"UID:task-81@time-tracker",
+		"DTSTAMP:20211001T103000Z",
+		"DTSTART:20211001T090000Z",
+		"DTEND:20211001T103000Z",
+		"STATUS:CONFIRMED",
+		`SUMMARY:Fix bug\; again\, and again`,
+		`DESCRIPTION:Line one\nLine two`,
+		"X-TIME-TRACKER-STATE:FINISHED",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:task-82@time-tracker",
+		"DTSTAMP:20211001T110000Z",
+		"DTSTART:20211001T110000Z",
+		"STATUS:TENTATIVE",
+		"SUMMARY:Task 82",
+		"X-TIME-TRACKER-STATE:RUNNING",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), string(body))
+}
+
+func TestTaskFeed_InvalidToken(t *testing.T) {
+	srv, sm := setupAuth(t)
+
+	sm.taskFeedFn = func(_ context.Context, userID int, token string) ([]models.Task, error) {
+		return nil, &usecase.Error{Kind: usecase.KindUnauthorized, Code: "invalid_feed_token", Message: "invalid feed token"}
+	}
+
+	res, err := http.Get(srv.URL + "/users/51/tasks.ics?org=3")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestTaskFeed_Org(t *testing.T) {
+	srv, sm := setupAuth(t)
+
+	called := false
+	sm.taskFeedFn = func(ctx context.Context, userID int, token string) ([]models.Task, error) {
+		called = true
+		return nil, nil
+	}
+
+	for _, query := range []string{"", "&org=", "&org=0", "&org=abc"} {
+		res, err := http.Get(srv.URL + "/users/51/tasks.ics?token=ttf_secret" + query)
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, query)
+	}
+	require.False(t, called, "feed must not be read without its organization")
+}
+
+func TestICSWriter_Fold(t *testing.T) {
+	var b strings.Builder
+	cal := &icsWriter{w: bufio.NewWriter(&b)}
+	cal.line("SUMMARY:" + strings.Repeat("я", 40))
+	require.NoError(t, cal.w.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	require.Len(t, lines, 2)
+	require.LessOrEqual(t, len(lines[0]), icsLineLength)
+	require.True(t, strings.HasPrefix(lines[1], " "))
+	require.Equal(t, "SUMMARY:"+strings.Repeat("я", 40), lines[0]+lines[1][1:])
+}
@@ -2,27 +2,91 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
 )
 
-type ListTasksResponse []Task
+type ListTasksResponse struct {
+	Tasks      []Task `json:"tasks"`
+	Count      int    `json:"count"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor,omitempty"` // empty on the last page
+}
 
 type Task struct {
-	ID      int       `json:"id"`
-	Since   time.Time `json:"since"`
-	Until   time.Time `json:"until"`
-	Minutes int       `json:"minutes"`
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	ProjectID   int       `json:"projectId,omitempty"`
+	Tags        []Tag     `json:"tags"`
+	Since       time.Time `json:"since"`
+	Until       time.Time `json:"until"`
+	Minutes     int       `json:"minutes"`
+	State       string    `json:"state" enums:"running,paused,finished"`
+	Segments    []Segment `json:"segments"`
+	AutoStopped bool      `json:"autoStopped"` // ended by the auto-stop policy, the end may need a correction
+}
+
+// Segment is an interval of work on a task.
+type Segment struct {
+	Since time.Time  `json:"since"`
+	Until *time.Time `json:"until"` // null for running segment
+}
+
+func newTask(t *models.Task) Task {
+	return Task{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		ProjectID:   t.ProjectID,
+		Tags:        newTags(t.Tags),
+		Since:       t.Since,
+		Until:       t.Until,
+		Minutes:     t.Minutes,
+		State:       string(t.State()),
+		Segments:    newSegments(t.Segments),
+		AutoStopped: t.AutoStopped,
+	}
 }
 
-// ListTasks lists all tasks for the given user.
-// @Summary List all tasks for a user
+func newSegments(segments []models.TaskSegment) []Segment {
+	resp := make([]Segment, len(segments))
+	for i, s := range segments {
+		resp[i] = Segment{Since: s.Since}
+		if !s.Until.IsZero() {
+			resp[i].Until = &segments[i].Until
+		}
+	}
+	return resp
+}
+
+// ListTasks lists tasks of the user page by page.
+// @Summary List tasks of a user
+// @Description Pages are linked by cursors: pass nextCursor of a page to get the next one with the same sort and filters.
 // @Tags tasks
 // @Param id path number true "User ID"
-// @Success 200 {object} Response{data=ListTasksResponse} "Task started"
-// @Failure 400 "Bad request"
-// @Failure 500 "Internal server error"
+// @Param title query string false "Filter by title substring"
+// @Param project query int false "Filter by project ID"
+// @Param tag query int false "Filter by tag ID"
+// @Param from query string false "Tasks worked on after the time, RFC 3339 or date"
+// @Param to query string false "Tasks started before the time, RFC 3339 or date"
+// @Param state query string false "Filter by state" Enums(running, paused, finished)
+// @Param sort query string false "Sort field, id by default" Enums(id, since, until, minutes)
+// @Param order query string false "Sort direction, asc by default" Enums(asc, desc)
+// @Param limit query int false "Tasks per page"
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} Response{data=ListTasksResponse} "Tasks"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 422 {object} Problem "Invalid filter, sort or cursor"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /users/{id}/tasks/ [get]
 func (a *API) ListTasks(w http.ResponseWriter, r *http.Request) {
 	userStr := r.PathValue("id")
@@ -37,21 +101,60 @@ func (a *API) ListTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tasks, err := a.service.ListTasks(r.Context(), userID)
+	q := r.URL.Query()
+	opts := models.TaskListOpts{
+		TaskFilter: models.TaskFilter{
+			Title: q.Get("title"),
+			State: models.TaskState(q.Get("state")),
+		},
+		Sort:   models.TaskSort(q.Get("sort")),
+		Cursor: q.Get("cursor"),
+	}
+
+	for name, v := range map[string]*int{
+		"project": &opts.ProjectID,
+		"tag":     &opts.TagID,
+		"limit":   &opts.Limit,
+	} {
+		if *v, err = queryInt(r, name); err != nil {
+			a.badRequest(w, r, err)
+			return
+		}
+	}
+
+	if opts.From, err = queryTime(r, "from"); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+	if opts.To, err = queryTime(r, "to"); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	switch order := q.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		opts.Desc = true
+	default:
+		a.badRequest(w, r, fmt.Errorf("invalid order, must be asc or desc: %s", order))
+		return
+	}
+
+	list, err := a.service.ListTasks(r.Context(), userID, opts)
 	if err != nil {
-		a.internalServerError(w, r, err)
+		a.writeErr(w, r, err)
 		return
 	}
 
-	tasksItems := make([]Task, len(tasks))
-	for i, t := range tasks {
-		tasksItems[i] = Task{
-			ID:      t.ID,
-			Since:   t.Since,
-			Until:   t.Until,
-			Minutes: t.Minutes,
-		}
+	resp := ListTasksResponse{
+		Tasks:      make([]Task, len(list.Tasks)),
+		Count:      list.Count,
+		Limit:      list.Limit,
+		NextCursor: list.NextCursor,
+	}
+	for i := range list.Tasks {
+		resp.Tasks[i] = newTask(&list.Tasks[i])
 	}
 
-	a.writeResp(w, r, ListTasksResponse(tasksItems))
+	a.writeResp(w, r, resp)
 }
@@ -14,23 +14,32 @@ import (
 func TestTasksList_OK(t *testing.T) {
 	srv, sm := setup(t)
 
-	sm.listTasksFn = func(_ context.Context, userID int) ([]models.Task, error) {
-		return []models.Task{
+	sm.listTasksFn = func(_ context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+		require.Equal(t, models.TaskListOpts{}, opts)
+		return &models.TaskList{Count: 3, Limit: 2, NextCursor: "eyJzIjoiaWQifQ", Tasks: []models.Task{
 			{
-				ID:      81,
-				UserID:  51,
-				Since:   time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
-				Until:   time.Date(2021, 10, 1, 1, 0, 0, 0, time.UTC),
-				Minutes: 60,
+				ID:          81,
+				UserID:      51,
+				Title:       "Fix bug",
+				Tags:        []models.Tag{{ID: 1, Name: "bug"}},
+				AutoStopped: true,
+				Since:       time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+				Until:       time.Date(2021, 10, 1, 1, 0, 0, 0, time.UTC),
+				Minutes:     60,
+				Segments: []models.TaskSegment{
+					{Since: time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2021, 10, 1, 1, 0, 0, 0, time.UTC)},
+				},
 			},
 			{
-				ID:      82,
-				UserID:  51,
-				Since:   time.Date(2021, 10, 1, 2, 0, 0, 0, time.UTC),
-				Until:   time.Date(2021, 10, 1, 3, 0, 0, 0, time.UTC),
-				Minutes: 60,
+				ID:     82,
+				UserID: 51,
+				Since:  time.Date(2021, 10, 1, 2, 0, 0, 0, time.UTC),
+				Segments: []models.TaskSegment{
+					{Since: time.Date(2021, 10, 1, 2, 0, 0, 0, time.UTC), Until: time.Date(2021, 10, 1, 2, 30, 0, 0, time.UTC)},
+					{Since: time.Date(2021, 10, 1, 3, 0, 0, 0, time.UTC)},
+				},
 			},
-		}, nil
+		}}, nil
 	}
 
 	req, err := http.NewRequest(http.MethodGet, srv.URL+"/users/51/tasks", nil)
@@ -45,5 +54,57 @@ func TestTasksList_OK(t *testing.T) {
 	body, err := io.ReadAll(res.Body)
 	require.NoError(t, err)
 
-	require.JSONEq(t, `{"data": [{"id": 81, "since": "2021-10-01T00:00:00Z", "until": "2021-10-01T01:00:00Z", "minutes": 60}, {"id": 82, "since": "2021-10-01T02:00:00Z", "until": "2021-10-01T03:00:00Z", "minutes": 60}]}`, string(body))
+	require.JSONEq(t, `{"data": {"count": 3, "limit": 2, "nextCursor": "eyJzIjoiaWQifQ", "tasks": [
+		{
+			"id": 81, "title": "Fix bug", "description": "", "tags": [{"id": 1, "name": "bug"}],
+			"since": "2021-10-01T00:00:00Z", "until": "2021-10-01T01:00:00Z", "minutes": 60, "state": "finished",
+			"segments": [{"since": "2021-10-01T00:00:00Z", "until": "2021-10-01T01:00:00Z"}], "autoStopped": true
+		},
+		{
+			"id": 82, "title": "", "description": "", "tags": [],
+			"since": "2021-10-01T02:00:00Z", "until": "0001-01-01T00:00:00Z", "minutes": 0, "state": "running",
+			"segments": [{"since": "2021-10-01T02:00:00Z", "until": "2021-10-01T02:30:00Z"}, {"since": "2021-10-01T03:00:00Z", "until": null}], "autoStopped": false
+		}
+	]}}`, string(body))
+}
+
+func TestTasksList_Filter(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.listTasksFn = func(_ context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+		require.Equal(t, models.TaskListOpts{
+			TaskFilter: models.TaskFilter{
+				Title:     "bug",
+				ProjectID: 3,
+				TagID:     4,
+				From:      time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+				To:        time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC),
+				State:     models.TaskFinished,
+			},
+			Sort:   models.TaskSortMinutes,
+			Desc:   true,
+			Limit:  10,
+			Cursor: "abc",
+		}, opts)
+		return &models.TaskList{}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/users/51/tasks?title=bug&project=3&tag=4&from=2021-10-01&to=2021-10-02" +
+		"&state=finished&sort=minutes&order=desc&limit=10&cursor=abc")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestTasksList_BadRequest(t *testing.T) {
+	srv, _ := setup(t)
+
+	for _, query := range []string{"?order=up", "?limit=ten", "?from=yesterday", "?project=x"} {
+		res, err := http.Get(srv.URL + "/users/51/tasks" + query)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, query)
+	}
 }
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+)
+
+// PauseTask pauses a running task of a user
+// @Summary Pause a task
+// @Description Time of a paused task is not counted until it is resumed.
+// @Tags tasks
+// @Param id path number true "User ID"
+// @Param taskID path number true "Task ID"
+// @Success 200 "Task paused"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User or task not found"
+// @Failure 409 {object} Problem "Task is paused or finished"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/tasks/{taskID}/pause [post]
+func (a *API) PauseTask(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	taskID, err := pathInt(r, "taskID")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	if err := a.service.PauseTask(r.Context(), userID, taskID); err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
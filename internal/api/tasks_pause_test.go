@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTasksPause_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.pauseTaskFn = func(ctx context.Context, userID, taskID int) error {
+		require.Equal(t, 51, userID)
+		require.Equal(t, 69, taskID)
+		return nil
+	}
+
+	res, err := http.Post(srv.URL+"/users/51/tasks/69/pause", "", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestTasksPause_Conflict(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.pauseTaskFn = func(ctx context.Context, userID, taskID int) error {
+		return &usecase.Error{Kind: usecase.KindConflict, Code: "task_paused", Message: "task is already paused"}
+	}
+
+	res, err := http.Post(srv.URL+"/users/51/tasks/69/pause", "", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+}
+
+func TestTasksPause_BadRequest(t *testing.T) {
+	srv, _ := setup(t)
+
+	res, err := http.Post(srv.URL+"/users/51/tasks/last/pause", "", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+)
+
+// ResumeTask resumes a paused task of a user
+// @Summary Resume a task
+// @Description Time of the task is counted again in a new segment.
+// @Tags tasks
+// @Param id path number true "User ID"
+// @Param taskID path number true "Task ID"
+// @Success 200 "Task resumed"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User or task not found"
+// @Failure 409 {object} Problem "Task is not paused"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/tasks/{taskID}/resume [post]
+func (a *API) ResumeTask(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	taskID, err := pathInt(r, "taskID")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	if err := a.service.ResumeTask(r.Context(), userID, taskID); err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
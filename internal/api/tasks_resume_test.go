@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTasksResume_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.resumeTaskFn = func(ctx context.Context, userID, taskID int) error {
+		require.Equal(t, 51, userID)
+		require.Equal(t, 69, taskID)
+		return nil
+	}
+
+	res, err := http.Post(srv.URL+"/users/51/tasks/69/resume", "", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestTasksResume_NotFound(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.resumeTaskFn = func(ctx context.Context, userID, taskID int) error {
+		return usecase.ErrNotFound
+	}
+
+	res, err := http.Post(srv.URL+"/users/51/tasks/69/resume", "", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
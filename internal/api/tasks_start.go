@@ -1,11 +1,22 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
+
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
 )
 
+type StartTaskRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ProjectID   int    `json:"projectId"`
+	TagIDs      []int  `json:"tagIds"`
+}
+
 type StartTaskResponse struct {
 	TaskID int `json:"task_id"`
 }
@@ -14,10 +25,18 @@ type StartTaskResponse struct {
 // @Summary Create a new task and start it
 // @Tags tasks
 // @Param id path number true "User ID"
+// @Param task body StartTaskRequest false "Task description"
 // @Success 200 {object} Response{data=api.StartTaskResponse} "Task started"
-// @Failure 400 "Bad request"
-// @Failure 500 "Internal server error"
-// @Router /users/{id}/tasks [post]
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 409 {object} Problem "User already has a running task"
+// @Failure 422 {object} Problem "Unknown project or tag"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/tasks/start [post]
 func (a *API) StartTask(w http.ResponseWriter, r *http.Request) {
 	userStr := r.PathValue("id")
 	if userStr == "" {
@@ -31,9 +50,21 @@ func (a *API) StartTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := a.service.StartTask(r.Context(), userID)
+	// body is optional, a task may be started without description
+	var req StartTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	id, err := a.service.StartTask(r.Context(), userID, usecase.TaskInfo{
+		Title:       req.Title,
+		Description: req.Description,
+		ProjectID:   req.ProjectID,
+		TagIDs:      req.TagIDs,
+	})
 	if err != nil {
-		a.internalServerError(w, r, err)
+		a.writeErr(w, r, err)
 		return
 	}
 
@@ -4,16 +4,19 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTasksStart_OK(t *testing.T) {
 	s, sm := setup(t)
 
-	sm.startTaskFn = func(ctx context.Context, userID int) (int, error) {
+	sm.startTaskFn = func(ctx context.Context, userID int, info usecase.TaskInfo) (int, error) {
 		require.Equal(t, 51, userID)
+		require.Equal(t, usecase.TaskInfo{}, info)
 		return 69, nil
 	}
 
@@ -31,3 +34,38 @@ func TestTasksStart_OK(t *testing.T) {
 
 	require.JSONEq(t, `{"data": {"task_id": 69}}`, string(body))
 }
+
+func TestTasksStart_Described(t *testing.T) {
+	s, sm := setup(t)
+
+	sm.startTaskFn = func(ctx context.Context, userID int, info usecase.TaskInfo) (int, error) {
+		require.Equal(t, usecase.TaskInfo{
+			Title:       "Fix bug",
+			Description: "Reports are empty",
+			ProjectID:   3,
+			TagIDs:      []int{1, 2},
+		}, info)
+		return 69, nil
+	}
+
+	body := `{"title": "Fix bug", "description": "Reports are empty", "projectId": 3, "tagIds": [1, 2]}`
+	res, err := http.Post(s.URL+"/users/51/tasks/start", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestTasksStart_NotFound(t *testing.T) {
+	s, sm := setup(t)
+
+	sm.startTaskFn = func(ctx context.Context, userID int, info usecase.TaskInfo) (int, error) {
+		return 0, usecase.ErrNotFound
+	}
+
+	res, err := http.Post(s.URL+"/users/51/tasks/start", "application/json", strings.NewReader(`{"projectId": 3}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+)
+
+type SwitchTaskResponse struct {
+	TaskID        int `json:"task_id"`
+	StoppedTaskID int `json:"stopped_task_id,omitempty"`
+}
+
+// SwitchTask ends the running task of the user and starts a new one
+// @Summary Stop the running task and start a new one
+// @Description The running task is ended at the moment the new one starts, both happen atomically.
+// @Tags tasks
+// @Param id path number true "User ID"
+// @Param task body StartTaskRequest false "Task description"
+// @Success 200 {object} Response{data=api.SwitchTaskResponse} "Task switched"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 409 {object} Problem "Concurrent task start"
+// @Failure 422 {object} Problem "Unknown project or tag"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/tasks/switch [post]
+func (a *API) SwitchTask(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	var req StartTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	id, stoppedID, err := a.service.SwitchTask(r.Context(), userID, usecase.TaskInfo{
+		Title:       req.Title,
+		Description: req.Description,
+		ProjectID:   req.ProjectID,
+		TagIDs:      req.TagIDs,
+	})
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, SwitchTaskResponse{TaskID: id, StoppedTaskID: stoppedID})
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTasksSwitch_OK(t *testing.T) {
+	s, sm := setup(t)
+
+	sm.switchTaskFn = func(ctx context.Context, userID int, info usecase.TaskInfo) (int, int, error) {
+		require.Equal(t, 51, userID)
+		require.Equal(t, "Review", info.Title)
+		return 70, 69, nil
+	}
+
+	res, err := http.Post(s.URL+"/users/51/tasks/switch", "application/json", strings.NewReader(`{"title": "Review"}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"task_id": 70, "stopped_task_id": 69}}`, string(body))
+}
+
+func TestTasksStart_Conflict(t *testing.T) {
+	s, sm := setup(t)
+
+	sm.startTaskFn = func(ctx context.Context, userID int, info usecase.TaskInfo) (int, error) {
+		return 0, usecase.ErrConflict
+	}
+
+	res, err := http.Post(s.URL+"/users/51/tasks/start", "application/json", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+)
+
+type UpdateTaskRequest struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Since       *time.Time `json:"since"`
+	Until       *time.Time `json:"until"`
+}
+
+// UpdateTask changes fields of a task which are present in the body.
+// @Summary Update a task
+// @Description Setting until of a running task ends it. Minutes are recalculated from the new bounds.
+// @Tags tasks
+// @Param id path number true "User ID"
+// @Param taskID path number true "Task ID"
+// @Param task body UpdateTaskRequest true "Fields to update"
+// @Success 200 {object} Response{data=Task} "Updated task"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User or task not found"
+// @Failure 409 {object} Problem "Task overlaps another task"
+// @Failure 422 {object} Problem "Invalid task bounds"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/tasks/{taskID} [patch]
+func (a *API) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	taskID, err := pathInt(r, "taskID")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	var req UpdateTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	task, err := a.service.UpdateTask(r.Context(), userID, taskID, usecase.TaskUpdate{
+		Title:       req.Title,
+		Description: req.Description,
+		Since:       req.Since,
+		Until:       req.Until,
+	})
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, newTask(task))
+}
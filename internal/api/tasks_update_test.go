@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTasksUpdate_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	until := time.Date(2021, 10, 1, 18, 0, 0, 0, time.UTC)
+
+	sm.updateTaskFn = func(_ context.Context, userID, taskID int, upd usecase.TaskUpdate) (*models.Task, error) {
+		require.Equal(t, 51, userID)
+		require.Equal(t, 69, taskID)
+		require.Nil(t, upd.Title)
+		require.Nil(t, upd.Since)
+		require.Equal(t, until, *upd.Until)
+
+		return &models.Task{ID: taskID, UserID: userID, Since: until.Add(-time.Hour), Until: until, Minutes: 60}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, srv.URL+"/users/51/tasks/69", strings.NewReader(`{"until": "2021-10-01T18:00:00Z"}`))
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestTasksUpdate_Invalid(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.updateTaskFn = func(_ context.Context, userID, taskID int, upd usecase.TaskUpdate) (*models.Task, error) {
+		return nil, &usecase.Error{Kind: usecase.KindValidation, Code: "future_entry", Message: "until must not be in the future"}
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, srv.URL+"/users/51/tasks/69", strings.NewReader(`{"until": "2999-10-01T18:00:00Z"}`))
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+)
+
+type Team struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	ManagerID int    `json:"managerId,omitempty"`
+	MemberIDs []int  `json:"memberIds"`
+}
+
+func newTeam(t models.Team) Team {
+	return Team{ID: t.ID, Name: t.Name, ManagerID: t.ManagerID, MemberIDs: append([]int{}, t.MemberIDs...)}
+}
+
+type CreateTeamRequest struct {
+	Name      string `json:"name"`
+	ManagerID int    `json:"managerId"`
+	MemberIDs []int  `json:"memberIds"`
+}
+
+type UpdateTeamRequest struct {
+	Name      *string `json:"name"`
+	ManagerID *int    `json:"managerId"` // zero removes the manager
+	MemberIDs *[]int  `json:"memberIds"` // replaces all members
+}
+
+// CreateTeam creates a new team.
+// @Summary Create a team
+// @Tags teams
+// @Param team body CreateTeamRequest true "Team"
+// @Success 201 {object} Response{data=Team} "Team created"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 409 {object} Problem "Team with the name already exists"
+// @Failure 422 {object} Problem "Invalid team, manager or members"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /teams [post]
+func (a *API) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	var req CreateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	team, err := a.service.CreateTeam(r.Context(), usecase.TeamInfo{
+		Name:      req.Name,
+		ManagerID: req.ManagerID,
+		MemberIDs: req.MemberIDs,
+	})
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	a.writeResp(w, r, newTeam(*team))
+}
+
+// ListTeams lists teams with their members.
+// @Summary List teams
+// @Description Admins get all teams, other users get the teams they manage or are members of.
+// @Tags teams
+// @Success 200 {object} Response{data=[]Team} "Teams"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /teams [get]
+func (a *API) ListTeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := a.service.ListTeams(r.Context())
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	resp := make([]Team, len(teams))
+	for i, t := range teams {
+		resp[i] = newTeam(t)
+	}
+
+	a.writeResp(w, r, resp)
+}
+
+// GetTeam returns a team by ID.
+// @Summary Get a team
+// @Description Only admins, the manager and members of the team may see it.
+// @Tags teams
+// @Param id path number true "Team ID"
+// @Success 200 {object} Response{data=Team} "Team"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "Team not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /teams/{id} [get]
+func (a *API) GetTeam(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	team, err := a.service.GetTeam(r.Context(), id)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, newTeam(*team))
+}
+
+// UpdateTeam changes fields of a team which are present in the body.
+// @Summary Update a team
+// @Tags teams
+// @Param id path number true "Team ID"
+// @Param team body UpdateTeamRequest true "Fields to update, memberIds replaces all members"
+// @Success 200 {object} Response{data=Team} "Updated team"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "Team not found"
+// @Failure 409 {object} Problem "Team with the name already exists"
+// @Failure 422 {object} Problem "Invalid team, manager or members"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /teams/{id} [patch]
+func (a *API) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	var req UpdateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	team, err := a.service.UpdateTeam(r.Context(), id, usecase.TeamUpdate{
+		Name:      req.Name,
+		ManagerID: req.ManagerID,
+		MemberIDs: req.MemberIDs,
+	})
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, newTeam(*team))
+}
+
+// DeleteTeam deletes a team, its members are kept.
+// @Summary Delete a team
+// @Tags teams
+// @Param id path number true "Team ID"
+// @Success 200 {object} Response "Team deleted"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "Team not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /teams/{id} [delete]
+func (a *API) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	if err := a.service.DeleteTeam(r.Context(), id); err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, nil)
+}
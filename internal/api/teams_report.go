@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+type TeamReportResponse struct {
+	From    time.Time        `json:"from"`
+	To      time.Time        `json:"to"`
+	Members []MemberWorkload `json:"members"`
+	Total   Duration         `json:"total"`
+}
+
+type MemberWorkload struct {
+	UserID     int            `json:"userId"`
+	Name       string         `json:"name"`
+	Surname    string         `json:"surname"`
+	Patronymic string         `json:"patronymic"`
+	Tasks      []TaskWorkload `json:"tasks"`
+	Total      Duration       `json:"total"`
+}
+
+// TeamReport returns time spent by each member of a team on each task within a period.
+// @Summary Team workload report
+// @Description Time spent by members of the team within [from, to), sorted by total descending, and their tasks sorted by effort descending. Members who tracked nothing are included. Running tasks are counted up to now or to, whichever comes first. Only admins and the manager of the team may read it.
+// @Tags teams
+// @Param id path number true "Team ID"
+// @Param from query string true "Period start, RFC 3339 time or date"
+// @Param to query string false "Period end, RFC 3339 time or date, now by default"
+// @Success 200 {object} Response{data=TeamReportResponse} "Team report"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "Team not found"
+// @Failure 422 {object} Problem "Invalid period"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /teams/{id}/report [get]
+func (a *API) TeamReport(w http.ResponseWriter, r *http.Request) {
+	teamID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	from, err := queryTime(r, "from")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+	if from.IsZero() {
+		a.badRequest(w, r, errors.New("missing from"))
+		return
+	}
+
+	to, err := queryTime(r, "to")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	report, err := a.service.TeamReport(r.Context(), teamID, from, to)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	resp := TeamReportResponse{
+		From:    report.From,
+		To:      report.To,
+		Members: make([]MemberWorkload, len(report.Members)),
+		Total:   newDuration(report.Total),
+	}
+	for i, m := range report.Members {
+		resp.Members[i] = MemberWorkload{
+			UserID:     m.UserID,
+			Name:       m.Name,
+			Surname:    m.Surname,
+			Patronymic: m.Patronymic,
+			Tasks:      make([]TaskWorkload, len(m.Tasks)),
+			Total:      newDuration(m.Total),
+		}
+		for j, t := range m.Tasks {
+			resp.Members[i].Tasks[j] = newTaskWorkload(t)
+		}
+	}
+
+	a.writeResp(w, r, resp)
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTeam_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.createTeamFn = func(_ context.Context, info usecase.TeamInfo) (*models.Team, error) {
+		require.Equal(t, usecase.TeamInfo{Name: "Backend", ManagerID: 7, MemberIDs: []int{51, 52}}, info)
+		return &models.Team{ID: 5, Name: info.Name, ManagerID: info.ManagerID, MemberIDs: info.MemberIDs}, nil
+	}
+
+	res, err := http.Post(srv.URL+"/teams", "application/json", strings.NewReader(`{"name": "Backend", "managerId": 7, "memberIds": [51, 52]}`))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"id": 5, "name": "Backend", "managerId": 7, "memberIds": [51, 52]}}`, string(body))
+}
+
+func TestListTeams_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.listTeamsFn = func(_ context.Context) ([]models.Team, error) {
+		return []models.Team{{ID: 5, Name: "Backend"}}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/teams")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": [{"id": 5, "name": "Backend", "memberIds": []}]}`, string(body))
+}
+
+func TestUpdateTeam_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.updateTeamFn = func(_ context.Context, id int, upd usecase.TeamUpdate) (*models.Team, error) {
+		require.Equal(t, 5, id)
+		require.Nil(t, upd.Name)
+		require.Equal(t, 0, *upd.ManagerID)
+		require.Equal(t, []int{52}, *upd.MemberIDs)
+		return &models.Team{ID: id, Name: "Backend", MemberIDs: *upd.MemberIDs}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, srv.URL+"/teams/5", strings.NewReader(`{"managerId": 0, "memberIds": [52]}`))
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"id": 5, "name": "Backend", "memberIds": [52]}}`, string(body))
+}
+
+func TestDeleteTeam_NotFound(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.deleteTeamFn = func(_ context.Context, id int) error {
+		return usecase.ErrNotFound
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL+"/teams/5", nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestTeamReport_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.teamReportFn = func(_ context.Context, teamID int, from, to time.Time) (*models.TeamReport, error) {
+		require.Equal(t, 5, teamID)
+		require.Equal(t, time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC), from)
+		require.Equal(t, time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC), to)
+
+		return &models.TeamReport{
+			TeamID: 5,
+			From:   from,
+			To:     to,
+			Members: []models.MemberEffort{
+				{
+					UserID:  51,
+					Name:    "Ivan",
+					Surname: "Ivanov",
+					Tasks: []models.TaskEffort{{
+						TaskID:   81,
+						Since:    time.Date(2021, 10, 1, 1, 0, 0, 0, time.UTC),
+						Until:    time.Date(2021, 10, 1, 3, 30, 0, 0, time.UTC),
+						Duration: 150 * time.Minute,
+					}},
+					Total: 150 * time.Minute,
+				},
+				{UserID: 52, Name: "Petr"},
+			},
+			Total: 150 * time.Minute,
+		}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/teams/5/report?from=2021-10-01&to=2021-10-02")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {
+		"from": "2021-10-01T00:00:00Z",
+		"to": "2021-10-02T00:00:00Z",
+		"members": [
+			{"userId": 51, "name": "Ivan", "surname": "Ivanov", "patronymic": "", "tasks": [
+				{"taskId": 81, "since": "2021-10-01T01:00:00Z", "until": "2021-10-01T03:30:00Z", "running": false, "autoStopped": false, "hours": 2, "minutes": 30}
+			], "total": {"hours": 2, "minutes": 30}},
+			{"userId": 52, "name": "Petr", "surname": "", "patronymic": "", "tasks": [], "total": {"hours": 0, "minutes": 0}}
+		],
+		"total": {"hours": 2, "minutes": 30}
+	}}`, string(body))
+}
+
+func TestTeamReport_BadRequest(t *testing.T) {
+	srv, _ := setup(t)
+
+	for _, query := range []string{"/teams/abc/report?from=2021-10-01", "/teams/5/report", "/teams/5/report?from=yesterday"} {
+		res, err := http.Get(srv.URL + query)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, query)
+	}
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/pkg/xlsx"
+)
+
+const (
+	csvContentType  = "text/csv"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+var timesheetHeader = []any{"Full name", "Passport", "Task", "Start", "End", "Minutes", "Duration", "Auto-stopped"}
+
+// sheetWriter writes exported rows in a spreadsheet format.
+type sheetWriter interface {
+	WriteRow(cells ...any) error
+	Close() error
+}
+
+// csvWriter writes rows as CSV, times are written in UTC.
+// Strings which spreadsheet apps would take for formulas are escaped.
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (c *csvWriter) WriteRow(cells ...any) error {
+	c.record = c.record[:0]
+	for _, cell := range cells {
+		switch v := cell.(type) {
+		case string:
+			c.record = append(c.record, escapeFormula(v))
+		case int:
+			c.record = append(c.record, strconv.Itoa(v))
+		case time.Time:
+			if v.IsZero() {
+				c.record = append(c.record, "")
+			} else {
+				c.record = append(c.record, v.UTC().Format(time.DateTime))
+			}
+		default:
+			return fmt.Errorf("unsupported cell type %T", cell)
+		}
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula prefixes strings starting as formulas with a quote, so spreadsheet apps show them as text.
+// Titles and names are written by users, a formula in them would run on the machine of whoever opens the export.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// timesheetFormat returns the content type of the export requested by the format query parameter
+// or the Accept header, CSV by default.
+func timesheetFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "csv":
+		return csvContentType, nil
+	case "xlsx":
+		return xlsxContentType, nil
+	case "":
+	default:
+		return "", fmt.Errorf("invalid format, must be csv or xlsx: %s", format)
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), xlsxContentType) {
+		return xlsxContentType, nil
+	}
+	return csvContentType, nil
+}
+
+// Timesheet exports time spent by a user on each task within a period.
+// @Summary Export user timesheet
+// @Description Exports time spent on each task within [from, to) as CSV or XLSX. Running tasks are counted up to now or to, whichever comes first. CSV times are in UTC, CSV cells starting like formulas are prefixed with a quote.
+// @Tags export
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param id path number true "User ID"
+// @Param from query string true "Period start, RFC 3339 time or date"
+// @Param to query string false "Period end, RFC 3339 time or date, now by default"
+// @Param format query string false "Export format, overrides the Accept header" Enums(csv, xlsx)
+// @Param reveal query bool false "Show passports unmasked, requires the permission to reveal them"
+// @Success 200 {file} file "Timesheet"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 422 {object} Problem "Invalid period"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/timesheet [get]
+func (a *API) Timesheet(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	a.exportTimesheet(w, r, userID)
+}
+
+// TimesheetAll exports time spent by all users on each task within a period.
+// @Summary Export timesheet of all users
+// @Description Exports time spent by all users on each task within [from, to) as CSV or XLSX, rows are ordered by user full name. CSV times are in UTC, CSV cells starting like formulas are prefixed with a quote.
+// @Tags export
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param from query string true "Period start, RFC 3339 time or date"
+// @Param to query string false "Period end, RFC 3339 time or date, now by default"
+// @Param format query string false "Export format, overrides the Accept header" Enums(csv, xlsx)
+// @Param reveal query bool false "Show passports unmasked, requires the permission to reveal them"
+// @Success 200 {file} file "Timesheet"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 422 {object} Problem "Invalid period"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /timesheet [get]
+func (a *API) TimesheetAll(w http.ResponseWriter, r *http.Request) {
+	a.exportTimesheet(w, r, 0)
+}
+
+func (a *API) exportTimesheet(w http.ResponseWriter, r *http.Request, userID int) {
+	from, err := queryTime(r, "from")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+	if from.IsZero() {
+		a.badRequest(w, r, errors.New("missing from"))
+		return
+	}
+
+	to, err := queryTime(r, "to")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	contentType, err := timesheetFormat(r)
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	reveal, ok := a.revealPassports(w, r)
+	if !ok {
+		return
+	}
+
+	// the response starts with the first row, so errors before it are still written as problems
+	var (
+		sheet   sheetWriter
+		started bool
+	)
+	start := func() error {
+		started = true
+
+		name := "timesheet"
+		if userID != 0 {
+			name += "-" + strconv.Itoa(userID)
+		}
+		name += "-" + from.Format(time.DateOnly)
+
+		ext, header := "csv", contentType+"; charset=utf-8"
+		if contentType == xlsxContentType {
+			ext, header = "xlsx", contentType
+		}
+
+		// exports of long periods outlive the write timeout of the server
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			logging.FromContext(r.Context()).Warn("Failed to clear write deadline of the export", "error", err)
+		}
+
+		w.Header().Set("Content-Type", header)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, ext))
+		w.WriteHeader(http.StatusOK)
+
+		var err error
+		if sheet, err = newSheetWriter(w, contentType); err != nil {
+			return err
+		}
+		return sheet.WriteRow(timesheetHeader...)
+	}
+
+	err = a.service.Timesheet(r.Context(), userID, from, to, func(row *models.TimesheetRow) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		minutes := int(row.Duration / time.Minute)
+
+		passport := row.User.Passport.String()
+		if reveal {
+			passport = row.User.Passport.Reveal()
+		}
+
+		// the column is left empty for tasks ended by users to stand out
+		var autoStopped string
+		if row.AutoStopped {
+			autoStopped = "yes"
+		}
+
+		return sheet.WriteRow(
+			row.User.FullName(),
+			passport,
+			row.Title,
+			row.Since,
+			row.Until,
+			minutes,
+			fmt.Sprintf("%d:%02d", minutes/60, minutes%60),
+			autoStopped,
+		)
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = sheet.Close()
+	}
+
+	if err != nil {
+		if !started {
+			a.writeErr(w, r, err)
+			return
+		}
+
+		// the status is already sent, aborting the response tells the client the file is incomplete
+		logging.FromContext(r.Context()).Error("Export failed", "error", err.Error(), "url", r.URL.Path)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func newSheetWriter(w io.Writer, contentType string) (sheetWriter, error) {
+	if contentType == xlsxContentType {
+		return xlsx.NewWriter(w, "Timesheet")
+	}
+	return &csvWriter{w: csv.NewWriter(w)}, nil
+}
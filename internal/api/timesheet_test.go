@@ -0,0 +1,232 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func timesheetRows(rows ...models.TimesheetRow) func(context.Context, int, time.Time, time.Time, func(*models.TimesheetRow) error) error {
+	return func(_ context.Context, _ int, _, _ time.Time, fn func(*models.TimesheetRow) error) error {
+		for i := range rows {
+			if err := fn(&rows[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestTimesheet_CSV(t *testing.T) {
+	srv, sm := setup(t)
+
+	user := models.User{ID: 51, Passport: models.Passport{Series: "1234", Number: "056789"}, Name: "Иван", Surname: "Иванов", Patronymic: "Иванович"}
+	rows := timesheetRows(
+		models.TimesheetRow{
+			User:        user,
+			TaskID:      81,
+			Title:       "Fix bug, again",
+			Since:       time.Date(2021, 10, 1, 9, 0, 0, 0, time.UTC),
+			Until:       time.Date(2021, 10, 1, 10, 30, 0, 0, time.UTC),
+			Duration:    90 * time.Minute,
+			AutoStopped: true,
+		},
+		models.TimesheetRow{
+			User:     user,
+			TaskID:   82,
+			Title:    "Review",
+			Since:    time.Date(2021, 10, 1, 11, 0, 0, 0, time.UTC),
+			Duration: 5*time.Minute + 30*time.Second,
+		},
+	)
+	sm.timesheetFn = func(ctx context.Context, userID int, from, to time.Time, fn func(*models.TimesheetRow) error) error {
+		require.Equal(t, 51, userID)
+		require.Equal(t, time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC), from)
+		require.True(t, to.IsZero())
+		return rows(ctx, userID, from, to, fn)
+	}
+
+	revealed := false
+	sm.revealPassportsFn = func(_ context.Context) error {
+		revealed = true
+		return nil
+	}
+
+	res, err := http.Get(srv.URL + "/users/51/timesheet?from=2021-10-01&reveal=true")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.True(t, revealed)
+	require.Equal(t, "text/csv; charset=utf-8", res.Header.Get("Content-Type"))
+	require.Equal(t, `attachment; filename="timesheet-51-2021-10-01.csv"`, res.Header.Get("Content-Disposition"))
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, "Full name,Passport,Task,Start,End,Minutes,Duration,Auto-stopped\n"+
+		"Иванов Иван Иванович,1234 056789,\"Fix bug, again\",2021-10-01 09:00:00,2021-10-01 10:30:00,90,1:30,yes\n"+
+		"Иванов Иван Иванович,1234 056789,Review,2021-10-01 11:00:00,,5,0:05,\n", string(body))
+}
+
+func TestTimesheet_Masked(t *testing.T) {
+	srv, sm := setup(t)
+
+	user := models.User{ID: 51, Passport: models.Passport{Series: "1234", Number: "056789"}, Surname: "Иванов"}
+	sm.timesheetFn = func(ctx context.Context, userID int, from, to time.Time, fn func(*models.TimesheetRow) error) error {
+		return timesheetRows(models.TimesheetRow{User: user, Title: "Review", Duration: time.Hour})(ctx, userID, from, to, fn)
+	}
+	sm.revealPassportsFn = func(_ context.Context) error {
+		return &usecase.Error{Kind: usecase.KindForbidden, Code: "access_denied", Message: "access denied"}
+	}
+
+	res, err := http.Get(srv.URL + "/users/51/timesheet?from=2021-10-01")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	records, err := csv.NewReader(res.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, "**** ****89", records[1][1])
+
+	// revealing without the permission is denied before anything is exported
+	res, err = http.Get(srv.URL + "/users/51/timesheet?from=2021-10-01&reveal=true")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func TestTimesheet_XLSX(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.timesheetFn = func(ctx context.Context, userID int, from, to time.Time, fn func(*models.TimesheetRow) error) error {
+		require.Zero(t, userID)
+		return timesheetRows(models.TimesheetRow{User: models.User{Surname: "Петров"}, Title: "Report", Duration: time.Hour})(ctx, userID, from, to, fn)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/timesheet?from=2021-10-01&to=2021-11-01", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", xlsxContentType)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, xlsxContentType, res.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	z, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+
+	var sheet []byte
+	for _, f := range z.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			sheet, err = io.ReadAll(rc)
+			require.NoError(t, err)
+			rc.Close()
+		}
+	}
+	require.Contains(t, string(sheet), "Петров")
+	require.Contains(t, string(sheet), `<c r="F2"><v>60</v></c>`)
+}
+
+func TestTimesheet_Errors(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.timesheetFn = func(ctx context.Context, userID int, from, to time.Time, fn func(*models.TimesheetRow) error) error {
+		return &usecase.Error{Kind: usecase.KindForbidden, Message: "access denied"}
+	}
+
+	for query, status := range map[string]int{
+		"/timesheet":                                 http.StatusBadRequest,
+		"/timesheet?from=2021-10-01&format=pdf":      http.StatusBadRequest,
+		"/users/x/timesheet?from=2021-10-01":         http.StatusBadRequest,
+		"/users/51/timesheet?from=2021-10-01&to=now": http.StatusBadRequest,
+		"/timesheet?from=2021-10-01&format=xlsx":     http.StatusForbidden,
+	} {
+		res, err := http.Get(srv.URL + query)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		require.Equal(t, status, res.StatusCode, query)
+		require.Equal(t, problemContentType, res.Header.Get("Content-Type"), query)
+	}
+}
+
+func TestTimesheet_Formulas(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.timesheetFn = timesheetRows(
+		models.TimesheetRow{User: models.User{Surname: "=HYPERLINK(\"http://evil\")"}, Title: "+1", Duration: time.Minute},
+		models.TimesheetRow{User: models.User{Surname: "@SUM(A1)"}, Title: "-2", Duration: time.Minute},
+		models.TimesheetRow{User: models.User{Surname: "Tab"}, Title: "\tcmd", Duration: time.Minute},
+		models.TimesheetRow{User: models.User{Surname: "Plain"}, Title: "a=b", Duration: time.Minute},
+	)
+
+	res, err := http.Get(srv.URL + "/timesheet?from=2021-10-01")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	records, err := csv.NewReader(res.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+
+	var cells [][2]string
+	for _, record := range records[1:] {
+		cells = append(cells, [2]string{record[0], record[2]})
+	}
+	require.Equal(t, [][2]string{
+		{`'=HYPERLINK("http://evil")`, "'+1"},
+		{"'@SUM(A1)", "'-2"},
+		{"Tab", "'\tcmd"},
+		{"Plain", "a=b"},
+	}, cells)
+}
+
+// TestTimesheet_WriteTimeout checks that exports taking longer than the write timeout of the server are not cut off.
+func TestTimesheet_WriteTimeout(t *testing.T) {
+	sm := &serviceMock{}
+	mux := http.NewServeMux()
+	New(sm).AddRoutes(mux)
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	sm.timesheetFn = func(ctx context.Context, userID int, from, to time.Time, fn func(*models.TimesheetRow) error) error {
+		for i := range 3 {
+			if err := fn(&models.TimesheetRow{User: models.User{Surname: "Petrov"}, TaskID: i, Title: "Report", Duration: time.Hour}); err != nil {
+				return err
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return nil
+	}
+
+	res, err := http.Get(srv.URL + "/timesheet?from=2021-10-01")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	records, err := csv.NewReader(res.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Nicholas2012/time-tracker/internal/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// untracedPaths are polled by infrastructure, their spans would only be noise.
+var untracedPaths = []string{"/livez", "/readyz", "/metrics"}
+
+// Trace starts a span for each request named by the route pattern of mux it matches,
+// so span names don't depend on path values. The trace of the caller is continued from the traceparent header.
+func Trace(mux *http.ServeMux, next http.Handler) http.Handler {
+	withRoute := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trace.SpanFromContext(r.Context()).SetAttributes(semconv.HTTPRoute(metrics.Route(mux, r)))
+		next.ServeHTTP(w, r)
+	})
+
+	return otelhttp.NewHandler(withRoute, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + metrics.Route(mux, r)
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return !isUntraced(r)
+		}),
+	)
+}
+
+func isUntraced(r *http.Request) bool {
+	for _, p := range untracedPaths {
+		if r.URL.Path == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTrace(t *testing.T) (*httptest.Server, *serviceMock, *tracetest.SpanRecorder) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	mux := http.NewServeMux()
+	sm := &serviceMock{}
+	New(sm).AddRoutes(mux)
+
+	srv := httptest.NewServer(Trace(mux, mux))
+	t.Cleanup(srv.Close)
+
+	return srv, sm, rec
+}
+
+func TestTrace(t *testing.T) {
+	srv, sm, rec := setupTrace(t)
+
+	var handlerSpan trace.SpanContext
+	sm.getUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return &models.User{ID: id}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/users/51", nil)
+	require.NoError(t, err)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	require.Equal(t, "GET /users/{id}", span.Name())
+	require.Contains(t, span.Attributes(), semconv.HTTPRoute("/users/{id}"))
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	require.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID(), "service must be called within the request span")
+}
+
+func TestTrace_Unmatched(t *testing.T) {
+	srv, _, rec := setupTrace(t)
+
+	res, err := http.Get(srv.URL + "/unknown/path")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "GET unmatched", spans[0].Name())
+}
+
+func TestTrace_Probe(t *testing.T) {
+	srv, _, rec := setupTrace(t)
+
+	res, err := http.Get(srv.URL + "/livez")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Empty(t, rec.Ended())
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+)
+
+// DeleteUser deletes a user with all the user tasks.
+// @Summary Delete a user
+// @Tags users
+// @Param id path number true "User ID"
+// @Success 200 {object} Response "User deleted"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id} [delete]
+func (a *API) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	if err := a.service.DeleteUser(r.Context(), id); err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, nil)
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteUser_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.deleteUserFn = func(_ context.Context, id int) error {
+		require.Equal(t, 51, id)
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL+"/users/51", nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestDeleteUser_NotFound(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.deleteUserFn = func(_ context.Context, id int) error {
+		return usecase.ErrNotFound
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, srv.URL+"/users/51", nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+type User struct {
+	ID             int    `json:"id"`
+	PassportSerie  string `json:"passportSerie" example:"****"`    // masked unless revealed
+	PassportNumber string `json:"passportNumber" example:"****01"` // masked unless revealed
+	Name           string `json:"name"`
+	Surname        string `json:"surname"`
+	Patronymic     string `json:"patronymic"`
+	Address        string `json:"address"`
+	Role           string `json:"role" enums:"employee,manager,admin"`
+	ManagerID      int    `json:"managerId,omitempty"`
+}
+
+// newUser returns the user with the passport masked unless reveal is set.
+func newUser(u models.User, reveal bool) User {
+	series, number := u.Passport.MaskedSeries(), u.Passport.MaskedNumber()
+	if reveal {
+		series, number = u.Passport.Series, u.Passport.Number
+	}
+
+	return User{
+		ID:             u.ID,
+		PassportSerie:  series,
+		PassportNumber: number,
+		Name:           u.Name,
+		Surname:        u.Surname,
+		Patronymic:     u.Patronymic,
+		Address:        u.Address,
+		Role:           string(u.Role),
+		ManagerID:      u.ManagerID,
+	}
+}
+
+// revealPassports reports whether passports are asked to be shown unmasked with the reveal query parameter.
+// Revealing requires a permission, so the problem is written and ok is false if it's not granted.
+func (a *API) revealPassports(w http.ResponseWriter, r *http.Request) (reveal, ok bool) {
+	reveal, err := queryBool(r, "reveal")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return false, false
+	}
+	if !reveal {
+		return false, true
+	}
+
+	if err := a.service.RevealPassports(r.Context()); err != nil {
+		a.writeErr(w, r, err)
+		return false, false
+	}
+	return true, true
+}
+
+// GetUser returns a user by ID.
+// @Summary Get a user
+// @Tags users
+// @Param id path number true "User ID"
+// @Param reveal query bool false "Show passports unmasked, requires the permission to reveal them"
+// @Success 200 {object} Response{data=User} "User"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id} [get]
+func (a *API) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+	reveal, ok := a.revealPassports(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := a.service.GetUser(r.Context(), id)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, newUser(*user, reveal))
+}
+
+// GetUserByPassport returns the user registered with the passport.
+// @Summary Find a user by passport
+// @Tags users
+// @Param passport query string true "Passport series and number, e.g. 1234 567890, spaces and dashes are ignored"
+// @Param reveal query bool false "Show passports unmasked, requires the permission to reveal them"
+// @Success 200 {object} Response{data=User} "User"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/by-passport [get]
+func (a *API) GetUserByPassport(w http.ResponseWriter, r *http.Request) {
+	passport := r.URL.Query().Get("passport")
+	if passport == "" {
+		a.badRequest(w, r, errors.New("missing passport"))
+		return
+	}
+	reveal, ok := a.revealPassports(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := a.service.GetUserByPassport(r.Context(), passport)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, newUser(*user, reveal))
+}
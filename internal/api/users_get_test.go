@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUser_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.getUserFn = func(_ context.Context, id int) (*models.User, error) {
+		require.Equal(t, 51, id)
+		return &models.User{
+			ID:         51,
+			Passport:   models.Passport{Series: "0012", Number: "000001"},
+			Name:       "Ivan",
+			Surname:    "Ivanov",
+			Patronymic: "Ivanovich",
+			Address:    "Moscow",
+			Role:       models.RoleEmployee,
+			ManagerID:  7,
+		}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/users/51")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"id": 51, "passportSerie": "****", "passportNumber": "****01", "name": "Ivan", "surname": "Ivanov", "patronymic": "Ivanovich", "address": "Moscow", "role": "employee", "managerId": 7}}`, string(body))
+}
+
+func TestGetUser_Reveal(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.getUserFn = func(_ context.Context, id int) (*models.User, error) {
+		return &models.User{ID: 51, Passport: models.Passport{Series: "0012", Number: "000001"}}, nil
+	}
+
+	revealed := false
+	sm.revealPassportsFn = func(_ context.Context) error {
+		revealed = true
+		return nil
+	}
+
+	res, err := http.Get(srv.URL + "/users/51?reveal=true")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.True(t, revealed)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"id": 51, "passportSerie": "0012", "passportNumber": "000001", "name": "", "surname": "", "patronymic": "", "address": "", "role": ""}}`, string(body))
+}
+
+func TestGetUser_RevealForbidden(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.revealPassportsFn = func(_ context.Context) error {
+		return &usecase.Error{Kind: usecase.KindForbidden, Code: "access_denied", Message: "access denied"}
+	}
+
+	for _, query := range []string{"reveal=true", "reveal=1"} {
+		res, err := http.Get(srv.URL + "/users/51?" + query)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		require.Equal(t, http.StatusForbidden, res.StatusCode, query)
+	}
+
+	res, err := http.Get(srv.URL + "/users/51?reveal=maybe")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestGetUser_NotFound(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.getUserFn = func(_ context.Context, id int) (*models.User, error) {
+		return nil, usecase.ErrNotFound
+	}
+
+	res, err := http.Get(srv.URL + "/users/51")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestGetUser_BadID(t *testing.T) {
+	srv, _ := setup(t)
+
+	res, err := http.Get(srv.URL + "/users/abc")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestGetUserByPassport_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.getUserByPassportFn = func(_ context.Context, passport string) (*models.User, error) {
+		require.Equal(t, "0012 000001", passport)
+		return &models.User{ID: 51, Passport: models.Passport{Series: "0012", Number: "000001"}, Role: models.RoleEmployee}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/users/by-passport?passport=0012+000001")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"id": 51, "passportSerie": "****", "passportNumber": "****01", "name": "", "surname": "", "patronymic": "", "address": "", "role": "employee"}}`, string(body))
+}
+
+func TestGetUserByPassport_NotFound(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.getUserByPassportFn = func(_ context.Context, passport string) (*models.User, error) {
+		return nil, usecase.ErrNotFound
+	}
+
+	res, err := http.Get(srv.URL + "/users/by-passport?passport=0012000001")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestGetUserByPassport_Missing(t *testing.T) {
+	srv, _ := setup(t)
+
+	res, err := http.Get(srv.URL + "/users/by-passport")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+type ListUsersResponse struct {
+	Users []User `json:"users"`
+	Count int    `json:"count"`
+	Pages int    `json:"pages"`
+	Page  int    `json:"page"`
+}
+
+// ListUsers lists users page by page.
+// @Summary List users
+// @Tags users
+// @Param page query int false "Page number, starts from 1"
+// @Param limit query int false "Users per page"
+// @Param name query string false "Search by full name"
+// @Param firstName query string false "Filter by name"
+// @Param surname query string false "Filter by surname"
+// @Param patronymic query string false "Filter by patronymic"
+// @Param address query string false "Filter by address"
+// @Param passport query string false "Filter by passport series and number, e.g. 1234 567890"
+// @Param reveal query bool false "Show passports unmasked, requires the permission to reveal them"
+// @Success 200 {object} Response{data=ListUsersResponse} "Users"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users [get]
+func (a *API) ListUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := models.UserListOpts{
+		Name:       q.Get("name"),
+		FirstName:  q.Get("firstName"),
+		Surname:    q.Get("surname"),
+		Patronymic: q.Get("patronymic"),
+		Address:    q.Get("address"),
+	}
+
+	var err error
+	if v := q.Get("passport"); v != "" {
+		if opts.Passport, err = models.ParsePassport(v); err != nil {
+			a.badRequest(w, r, err)
+			return
+		}
+	}
+	for name, v := range map[string]*int{
+		"page":  &opts.Page,
+		"limit": &opts.Limit,
+	} {
+		if *v, err = queryInt(r, name); err != nil {
+			a.badRequest(w, r, err)
+			return
+		}
+	}
+	reveal, ok := a.revealPassports(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := a.service.ListUsers(r.Context(), opts)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	resp := ListUsersResponse{
+		Users: make([]User, len(list.Users)),
+		Count: list.Count,
+		Pages: list.Pages,
+		Page:  list.Page,
+	}
+	for i, u := range list.Users {
+		resp.Users[i] = newUser(u, reveal)
+	}
+
+	a.writeResp(w, r, resp)
+}
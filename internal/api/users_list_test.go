@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListUsers_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.listUsersFn = func(_ context.Context, opts models.UserListOpts) (*models.UserList, error) {
+		require.Equal(t, models.UserListOpts{
+			Page:     2,
+			Limit:    1,
+			Name:     "ivan",
+			Address:  "Moscow",
+			Passport: models.Passport{Series: "0012", Number: "000001"},
+		}, opts)
+
+		return &models.UserList{
+			Users: []models.User{{ID: 51, Name: "Ivan", Passport: models.Passport{Series: "0012", Number: "000001"}}},
+			Count: 2,
+			Pages: 2,
+			Page:  2,
+		}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/users?page=2&limit=1&name=ivan&address=Moscow&passport=0012+000001")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"users": [{"id": 51, "passportSerie": "****", "passportNumber": "****01", "name": "Ivan", "surname": "", "patronymic": "", "address": "", "role": ""}], "count": 2, "pages": 2, "page": 2}}`, string(body))
+}
+
+func TestListUsers_BadRequest(t *testing.T) {
+	srv, _ := setup(t)
+
+	for _, query := range []string{"page=abc", "passport=0012"} {
+		res, err := http.Get(srv.URL + "/users?" + query)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, query)
+	}
+}
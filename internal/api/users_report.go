@@ -0,0 +1,108 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+type WorkloadResponse struct {
+	From  time.Time      `json:"from"`
+	To    time.Time      `json:"to"`
+	Tasks []TaskWorkload `json:"tasks"`
+	Total Duration       `json:"total"`
+}
+
+type TaskWorkload struct {
+	TaskID      int        `json:"taskId"`
+	Since       time.Time  `json:"since"`
+	Until       *time.Time `json:"until"` // null for running task
+	Running     bool       `json:"running"`
+	AutoStopped bool       `json:"autoStopped"` // ended by the auto-stop policy
+	Duration
+}
+
+func newTaskWorkload(t models.TaskEffort) TaskWorkload {
+	w := TaskWorkload{
+		TaskID:      t.TaskID,
+		Since:       t.Since,
+		Running:     t.Until.IsZero(),
+		AutoStopped: t.AutoStopped,
+		Duration:    newDuration(t.Duration),
+	}
+	if !t.Until.IsZero() {
+		w.Until = &t.Until
+	}
+	return w
+}
+
+type Duration struct {
+	Hours   int `json:"hours"`
+	Minutes int `json:"minutes"`
+}
+
+func newDuration(d time.Duration) Duration {
+	m := int(d / time.Minute)
+	return Duration{Hours: m / 60, Minutes: m % 60}
+}
+
+// Workload returns time spent by a user on each task within a period.
+// @Summary User workload report
+// @Description Time spent on each task within [from, to), sorted by effort descending. Running tasks are counted up to now or to, whichever comes first.
+// @Tags users
+// @Param id path number true "User ID"
+// @Param from query string true "Period start, RFC 3339 time or date"
+// @Param to query string false "Period end, RFC 3339 time or date, now by default"
+// @Success 200 {object} Response{data=WorkloadResponse} "Workload"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Access denied"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 422 {object} Problem "Invalid period"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/report [get]
+func (a *API) Workload(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	from, err := queryTime(r, "from")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+	if from.IsZero() {
+		a.badRequest(w, r, errors.New("missing from"))
+		return
+	}
+
+	to, err := queryTime(r, "to")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	workload, err := a.service.Workload(r.Context(), userID, from, to)
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	resp := WorkloadResponse{
+		From:  workload.From,
+		To:    workload.To,
+		Tasks: make([]TaskWorkload, len(workload.Tasks)),
+		Total: newDuration(workload.Total),
+	}
+	for i, t := range workload.Tasks {
+		resp.Tasks[i] = newTaskWorkload(t)
+	}
+
+	a.writeResp(w, r, resp)
+}
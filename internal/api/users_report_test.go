@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkload_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.workloadFn = func(_ context.Context, userID int, from, to time.Time) (*models.Workload, error) {
+		require.Equal(t, 51, userID)
+		require.Equal(t, time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC), from)
+		require.Equal(t, time.Date(2021, 10, 2, 12, 0, 0, 0, time.UTC), to)
+
+		return &models.Workload{
+			UserID: 51,
+			From:   from,
+			To:     to,
+			Tasks: []models.TaskEffort{
+				{
+					TaskID:   81,
+					Since:    time.Date(2021, 10, 1, 1, 0, 0, 0, time.UTC),
+					Until:    time.Date(2021, 10, 1, 3, 30, 0, 0, time.UTC),
+					Duration: 150 * time.Minute,
+				},
+				{
+					TaskID:   82,
+					Since:    time.Date(2021, 10, 2, 11, 0, 0, 0, time.UTC),
+					Duration: 45 * time.Minute,
+				},
+			},
+			Total: 195 * time.Minute,
+		}, nil
+	}
+
+	res, err := http.Get(srv.URL + "/users/51/report?from=2021-10-01&to=2021-10-02T12:00:00Z")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {
+		"from": "2021-10-01T00:00:00Z",
+		"to": "2021-10-02T12:00:00Z",
+		"tasks": [
+			{"taskId": 81, "since": "2021-10-01T01:00:00Z", "until": "2021-10-01T03:30:00Z", "running": false, "autoStopped": false, "hours": 2, "minutes": 30},
+			{"taskId": 82, "since": "2021-10-02T11:00:00Z", "until": null, "running": true, "autoStopped": false, "hours": 0, "minutes": 45}
+		],
+		"total": {"hours": 3, "minutes": 15}
+	}}`, string(body))
+}
+
+func TestWorkload_BadRequest(t *testing.T) {
+	srv, _ := setup(t)
+
+	for _, query := range []string{"", "?from=yesterday", "?from=2021-10-01&to=tomorrow"} {
+		res, err := http.Get(srv.URL + "/users/51/report" + query)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, query)
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+)
+
+type UpdateUserRequest struct {
+	PassportNumber *string `json:"passportNumber"`
+	Name           *string `json:"name"`
+	Surname        *string `json:"surname"`
+	Patronymic     *string `json:"patronymic"`
+	Address        *string `json:"address"`
+	Role           *string `json:"role" enums:"employee,manager,admin"`
+	ManagerID      *int    `json:"managerId"` // zero removes the manager
+}
+
+// UpdateUser changes fields of a user which are present in the body.
+// @Summary Update a user
+// @Tags users
+// @Param id path number true "User ID"
+// @Param reveal query bool false "Show passports unmasked, requires the permission to reveal them"
+// @Param user body UpdateUserRequest true "Fields to update"
+// @Success 200 {object} Response{data=User} "Updated user"
+// @Failure 400 {object} Problem "Bad request"
+// @Failure 401 {object} Problem "Unauthenticated"
+// @Failure 403 {object} Problem "Only admins may update users"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 409 {object} Problem "User with the passport already exists"
+// @Failure 422 {object} Problem "Invalid passport number, role or manager"
+// @Failure 500 {object} Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id} [patch]
+func (a *API) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	reveal, ok := a.revealPassports(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.badRequest(w, r, err)
+		return
+	}
+
+	user, err := a.service.UpdateUser(r.Context(), id, usecase.UserUpdate{
+		Passport:   req.PassportNumber,
+		Name:       req.Name,
+		Surname:    req.Surname,
+		Patronymic: req.Patronymic,
+		Address:    req.Address,
+		Role:       (*models.Role)(req.Role),
+		ManagerID:  req.ManagerID,
+	})
+	if err != nil {
+		a.writeErr(w, r, err)
+		return
+	}
+
+	a.writeResp(w, r, newUser(*user, reveal))
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateUser_OK(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.updateUserFn = func(_ context.Context, id int, upd usecase.UserUpdate) (*models.User, error) {
+		require.Equal(t, 51, id)
+		require.Equal(t, "Moscow", *upd.Address)
+		require.Nil(t, upd.Name)
+		require.Nil(t, upd.Passport)
+		require.Equal(t, models.RoleManager, *upd.Role)
+
+		return &models.User{ID: 51, Name: "Ivan", Address: "Moscow", Role: *upd.Role}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, srv.URL+"/users/51", strings.NewReader(`{"address": "Moscow", "role": "manager"}`))
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"data": {"id": 51, "passportSerie": "", "passportNumber": "", "name": "Ivan", "surname": "", "patronymic": "", "address": "Moscow", "role": "manager"}}`, string(body))
+}
+
+func TestUpdateUser_NotFound(t *testing.T) {
+	srv, sm := setup(t)
+
+	sm.updateUserFn = func(_ context.Context, id int, upd usecase.UserUpdate) (*models.User, error) {
+		return nil, usecase.ErrNotFound
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, srv.URL+"/users/51", strings.NewReader(`{"name": "Ivan"}`))
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
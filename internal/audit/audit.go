@@ -0,0 +1,201 @@
+// Package audit builds entries of the audit log. Storage backends append them in the transaction of the change,
+// so a change is never stored without its entry.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+type (
+	actorKey    struct{}
+	recorderKey struct{}
+)
+
+type actor struct {
+	userID int
+	role   models.Role
+}
+
+// WithActor returns a copy of ctx whose changes are recorded as made by the user with the role.
+// Changes made with contexts without an actor are recorded as internal.
+func WithActor(ctx context.Context, userID int, role models.Role) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor{userID: userID, role: role})
+}
+
+// UserEntry returns the entry of the change of the user, before is nil for created users and after is nil for deleted ones.
+func UserEntry(ctx context.Context, action models.AuditAction, before, after *models.User) (*models.AuditEntry, error) {
+	entry := newEntry(ctx, action, models.AuditUser)
+
+	var err error
+	if before != nil {
+		entry.EntityID = before.ID
+		if entry.Before, err = json.Marshal(newUser(before)); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		entry.EntityID = after.ID
+		if entry.After, err = json.Marshal(newUser(after)); err != nil {
+			return nil, err
+		}
+	}
+
+	return entry, nil
+}
+
+// TaskEntry returns the entry of the change of the task, before is nil for created tasks and after is nil for deleted ones.
+func TaskEntry(ctx context.Context, action models.AuditAction, before, after *models.Task) (*models.AuditEntry, error) {
+	entry := newEntry(ctx, action, models.AuditTask)
+
+	var err error
+	if before != nil {
+		entry.EntityID = before.ID
+		if entry.Before, err = json.Marshal(newTask(before)); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		entry.EntityID = after.ID
+		if entry.After, err = json.Marshal(newTask(after)); err != nil {
+			return nil, err
+		}
+	}
+
+	return entry, nil
+}
+
+func newEntry(ctx context.Context, action models.AuditAction, entity models.AuditEntity) *models.AuditEntry {
+	a, _ := ctx.Value(actorKey{}).(actor)
+
+	entry := &models.AuditEntry{
+		// microseconds are kept by all backends
+		CreatedAt: time.Now().Truncate(time.Microsecond),
+		ActorID:   a.userID,
+		ActorRole: a.role,
+		Action:    action,
+		Entity:    entity,
+		RequestID: logging.RequestID(ctx),
+	}
+	if rec, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		rec.add(entry)
+	}
+
+	return entry
+}
+
+// Recorder collects entries built with a context, storage backends set IDs of the entries once they are appended.
+type Recorder struct {
+	parent *Recorder // recorder of the parent context, it collects the entries too
+
+	mu      sync.Mutex
+	entries []*models.AuditEntry
+}
+
+// Record returns a copy of ctx whose entries are collected by the returned recorder.
+func Record(ctx context.Context) (context.Context, *Recorder) {
+	parent, _ := ctx.Value(recorderKey{}).(*Recorder)
+	rec := &Recorder{parent: parent}
+	return context.WithValue(ctx, recorderKey{}, rec), rec
+}
+
+func (r *Recorder) add(entry *models.AuditEntry) {
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+
+	if r.parent != nil {
+		r.parent.add(entry)
+	}
+}
+
+// Entries returns the collected entries in the order they were built.
+func (r *Recorder) Entries() []*models.AuditEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.entries)
+}
+
+// user is a snapshot of a user in the audit log, the passport is masked.
+type user struct {
+	ID         int         `json:"id"`
+	Passport   string      `json:"passport"`
+	Name       string      `json:"name"`
+	Surname    string      `json:"surname"`
+	Patronymic string      `json:"patronymic"`
+	Address    string      `json:"address"`
+	Role       models.Role `json:"role"`
+	ManagerID  int         `json:"managerId,omitempty"`
+}
+
+func newUser(u *models.User) user {
+	return user{
+		ID:         u.ID,
+		Passport:   u.Passport.String(),
+		Name:       u.Name,
+		Surname:    u.Surname,
+		Patronymic: u.Patronymic,
+		Address:    u.Address,
+		Role:       u.Role,
+		ManagerID:  u.ManagerID,
+	}
+}
+
+// task is a snapshot of a task in the audit log, times are in UTC.
+type task struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"userId"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	ProjectID   int        `json:"projectId,omitempty"`
+	TagIDs      []int      `json:"tagIds"`
+	Since       time.Time  `json:"since"`
+	Until       *time.Time `json:"until"`
+	Minutes     int        `json:"minutes"`
+	Segments    []segment  `json:"segments"`
+	AutoStopped bool       `json:"autoStopped"`
+}
+
+type segment struct {
+	Since time.Time  `json:"since"`
+	Until *time.Time `json:"until"`
+}
+
+func newTask(t *models.Task) task {
+	s := task{
+		ID:          t.ID,
+		UserID:      t.UserID,
+		Title:       t.Title,
+		Description: t.Description,
+		ProjectID:   t.ProjectID,
+		TagIDs:      make([]int, len(t.Tags)),
+		Since:       t.Since.UTC(),
+		Until:       utc(t.Until),
+		Minutes:     t.Minutes,
+		Segments:    make([]segment, len(t.Segments)),
+		AutoStopped: t.AutoStopped,
+	}
+	for i, tag := range t.Tags {
+		s.TagIDs[i] = tag.ID
+	}
+	for i, seg := range t.Segments {
+		s.Segments[i] = segment{Since: seg.Since.UTC(), Until: utc(seg.Until)}
+	}
+	return s
+}
+
+// utc returns nil for zero time.
+func utc(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
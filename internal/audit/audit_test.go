@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskEntry(t *testing.T) {
+	ctx := logging.WithRequestID(WithActor(context.Background(), 3, models.RoleEmployee), "req-42")
+	since := time.Date(2024, 7, 10, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	task := &models.Task{
+		ID:       81,
+		UserID:   3,
+		Title:    "Report",
+		Tags:     []models.Tag{{ID: 1, Name: "bug"}},
+		Since:    since,
+		Segments: []models.TaskSegment{{Since: since}},
+	}
+
+	entry, err := TaskEntry(ctx, models.AuditCreate, nil, task)
+	require.NoError(t, err)
+	require.Equal(t, 3, entry.ActorID)
+	require.Equal(t, models.RoleEmployee, entry.ActorRole)
+	require.Equal(t, models.AuditTask, entry.Entity)
+	require.Equal(t, 81, entry.EntityID)
+	require.Equal(t, "req-42", entry.RequestID)
+	require.Nil(t, entry.Before)
+	require.JSONEq(t, `{
+		"id": 81, "userId": 3, "title": "Report", "description": "", "tagIds": [1],
+		"since": "2024-07-10T09:00:00Z", "until": null, "minutes": 0,
+		"segments": [{"since": "2024-07-10T09:00:00Z", "until": null}], "autoStopped": false
+	}`, string(entry.After))
+}
+
+func TestUserEntry_Internal(t *testing.T) {
+	user := &models.User{ID: 5, Name: "Иван", Role: models.RoleEmployee}
+
+	entry, err := UserEntry(context.Background(), models.AuditDelete, user, nil)
+	require.NoError(t, err)
+	require.Zero(t, entry.ActorID)
+	require.Empty(t, entry.ActorRole)
+	require.Empty(t, entry.RequestID)
+	require.Equal(t, 5, entry.EntityID)
+	require.Nil(t, entry.After)
+	require.Contains(t, string(entry.Before), `"name":"Иван"`)
+}
+
+func TestUserEntry_MasksPassport(t *testing.T) {
+	user := &models.User{ID: 5, Passport: models.Passport{Series: "1234", Number: "567890"}}
+
+	entry, err := UserEntry(context.Background(), models.AuditCreate, nil, user)
+	require.NoError(t, err)
+	require.Contains(t, string(entry.After), `"passport":"**** ****90"`)
+	require.NotContains(t, string(entry.After), "1234")
+}
+
+func TestRecord(t *testing.T) {
+	ctx, rec := Record(context.Background())
+
+	created, err := UserEntry(ctx, models.AuditCreate, nil, &models.User{ID: 5})
+	require.NoError(t, err)
+	_, err = UserEntry(context.Background(), models.AuditCreate, nil, &models.User{ID: 6})
+	require.NoError(t, err)
+
+	// backends set IDs of appended entries
+	created.ID = 42
+	entries := rec.Entries()
+	require.Len(t, entries, 1)
+	require.Equal(t, 42, entries[0].ID)
+
+	// recorders of parent contexts collect entries too
+	nested, inner := Record(ctx)
+	_, err = UserEntry(nested, models.AuditCreate, nil, &models.User{ID: 7})
+	require.NoError(t, err)
+	require.Len(t, inner.Entries(), 1)
+	require.Len(t, rec.Entries(), 2)
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// Event returns the event of live activity recorded by the entry, ok is false for changes which aren't streamed:
+// updates and deletions of users.
+func Event(entry *models.AuditEntry) (event models.Event, ok bool, err error) {
+	event = models.Event{
+		ID:   entry.ID,
+		Time: entry.CreatedAt,
+		Data: entry.After,
+	}
+
+	switch {
+	case entry.Entity == models.AuditUser && entry.Action == models.AuditCreate:
+		event.Type = models.EventUserCreated
+		event.UserID = entry.EntityID
+		return event, true, nil
+	case entry.Entity != models.AuditTask:
+		return event, false, nil
+	}
+
+	var before, after task
+	if entry.Before != nil {
+		if err := json.Unmarshal(entry.Before, &before); err != nil {
+			return event, false, fmt.Errorf("task snapshot of audit entry %d: %w", entry.ID, err)
+		}
+	}
+	event.TaskID = entry.EntityID
+
+	if entry.Action == models.AuditDelete {
+		event.Type = models.EventTaskDeleted
+		event.UserID = before.UserID
+		event.Data = entry.Before
+		return event, true, nil
+	}
+
+	if err := json.Unmarshal(entry.After, &after); err != nil {
+		return event, false, fmt.Errorf("task snapshot of audit entry %d: %w", entry.ID, err)
+	}
+	event.UserID = after.UserID
+
+	switch {
+	case entry.Action == models.AuditCreate && after.Until == nil:
+		event.Type = models.EventTaskStarted
+	case entry.Action == models.AuditCreate:
+		event.Type = models.EventTaskCreated
+	case before.Until == nil && after.Until != nil:
+		event.Type = models.EventTaskEnded
+	default:
+		event.Type = models.EventTaskUpdated
+	}
+
+	return event, true, nil
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent(t *testing.T) {
+	ctx := context.Background()
+	since := time.Date(2024, 7, 10, 9, 0, 0, 0, time.UTC)
+	running := &models.Task{ID: 81, UserID: 3, Title: "Report", Since: since, Segments: []models.TaskSegment{{Since: since}}}
+	renamed := *running
+	renamed.Title = "Monthly report"
+	ended := *running
+	ended.End(since.Add(time.Hour))
+
+	entry := func(e *models.AuditEntry, err error) *models.AuditEntry {
+		require.NoError(t, err)
+		e.ID = 7
+		return e
+	}
+
+	for _, tc := range []struct {
+		name  string
+		entry *models.AuditEntry
+		want  models.EventType
+	}{
+		{"TaskStarted", entry(TaskEntry(ctx, models.AuditCreate, nil, running)), models.EventTaskStarted},
+		{"TaskCreated", entry(TaskEntry(ctx, models.AuditCreate, nil, &ended)), models.EventTaskCreated},
+		{"TaskEnded", entry(TaskEntry(ctx, models.AuditUpdate, running, &ended)), models.EventTaskEnded},
+		{"TaskUpdated", entry(TaskEntry(ctx, models.AuditUpdate, running, &renamed)), models.EventTaskUpdated},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			event, ok, err := Event(tc.entry)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, tc.want, event.Type)
+			require.Equal(t, 7, event.ID)
+			require.Equal(t, 3, event.UserID)
+			require.Equal(t, 81, event.TaskID)
+			require.Equal(t, tc.entry.After, event.Data)
+		})
+	}
+
+	t.Run("UserCreated", func(t *testing.T) {
+		event, ok, err := Event(entry(UserEntry(ctx, models.AuditCreate, nil, &models.User{ID: 5})))
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, models.EventUserCreated, event.Type)
+		require.Equal(t, 5, event.UserID)
+		require.Zero(t, event.TaskID)
+	})
+
+	t.Run("NotStreamed", func(t *testing.T) {
+		_, ok, err := Event(entry(UserEntry(ctx, models.AuditUpdate, &models.User{ID: 5}, &models.User{ID: 5, Name: "Ivan"})))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+	})
+
+	t.Run("TaskDeleted", func(t *testing.T) {
+		deleted := entry(TaskEntry(ctx, models.AuditDelete, running, nil))
+		event, ok, err := Event(deleted)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, models.EventTaskDeleted, event.Type)
+		require.Equal(t, 3, event.UserID)
+		require.Equal(t, 81, event.TaskID)
+		require.Equal(t, deleted.Before, event.Data)
+	})
+}
@@ -0,0 +1,143 @@
+// Package auth generates API keys and signs bearer tokens.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+const (
+	keyPrefix       = "tt_"
+	feedTokenPrefix = "ttf_"
+	keyBytes        = 32
+	prefixLength    = len(keyPrefix) + 8
+)
+
+// GenerateKey returns a new random API key with its display prefix and hash.
+func GenerateKey() (key, prefix string, hash []byte, err error) {
+	key, err = generate(keyPrefix)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	return key, key[:prefixLength], HashKey(key), nil
+}
+
+// GenerateFeedToken returns a new random token of calendar feeds and its hash.
+// Feed tokens grant read access to the feed only and are passed in URLs.
+func GenerateFeedToken() (token string, hash []byte, err error) {
+	token, err = generate(feedTokenPrefix)
+	if err != nil {
+		return "", nil, fmt.Errorf("generate feed token: %w", err)
+	}
+
+	return token, HashKey(token), nil
+}
+
+func generate(prefix string) (string, error) {
+	b := make([]byte, keyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashKey returns hash of the key to store and look it up.
+// Keys are random with enough entropy, so a fast hash is sufficient.
+func HashKey(key string) []byte {
+	h := sha256.Sum256([]byte(key))
+	return h[:]
+}
+
+// Claims identify the user a token is issued for and the key it is issued with.
+type Claims struct {
+	UserID   int
+	Role     models.Role
+	OrgID    int    // organization of the user, zero for the admin key
+	KeyID    int    // API key the token is issued with, zero for the admin key
+	AdminKey string // fingerprint of the admin key the token is issued with, see Tokens.Fingerprint
+}
+
+type tokenClaims struct {
+	Role     models.Role `json:"role"`
+	OrgID    *int        `json:"org,omitempty"`
+	KeyID    int         `json:"key,omitempty"`
+	AdminKey string      `json:"adm,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Tokens issues and verifies bearer tokens signed with HMAC-SHA256.
+type Tokens struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewTokens(secret []byte, ttl time.Duration) *Tokens {
+	return &Tokens{
+		secret: secret,
+		ttl:    ttl,
+	}
+}
+
+// Issue returns a token for the user and its expiration time.
+func (t *Tokens) Issue(claims Claims) (string, time.Time, error) {
+	now := time.Now()
+	expires := now.Add(t.ttl)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims{
+		Role:     claims.Role,
+		OrgID:    &claims.OrgID,
+		KeyID:    claims.KeyID,
+		AdminKey: claims.AdminKey,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(claims.UserID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+	})
+
+	signed, err := token.SignedString(t.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("sign token: %w", err)
+	}
+
+	return signed, expires, nil
+}
+
+// Parse verifies the token and returns its claims, ErrInvalidToken is returned for bad or expired tokens.
+func (t *Tokens) Parse(token string) (Claims, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return t.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil || !claims.Role.IsValid() || claims.OrgID == nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	return Claims{UserID: userID, Role: claims.Role, OrgID: *claims.OrgID, KeyID: claims.KeyID, AdminKey: claims.AdminKey}, nil
+}
+
+// Fingerprint returns a fingerprint of the key hash to bind tokens to the key. It's keyed with the secret
+// of tokens, so it doesn't help to guess the key although tokens are readable by their holders.
+func (t *Tokens) Fingerprint(hash []byte) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write(hash)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
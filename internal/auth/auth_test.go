@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateKey(t *testing.T) {
+	key, prefix, hash, err := GenerateKey()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, prefix))
+	require.True(t, strings.HasPrefix(prefix, "tt_"))
+	require.Equal(t, HashKey(key), hash)
+
+	other, _, _, err := GenerateKey()
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+}
+
+func TestGenerateFeedToken(t *testing.T) {
+	token, hash, err := GenerateFeedToken()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "ttf_"))
+	require.Equal(t, HashKey(token), hash)
+}
+
+func TestTokens(t *testing.T) {
+	tokens := NewTokens([]byte("secret"), time.Hour)
+
+	token, expires, err := tokens.Issue(Claims{UserID: 5, Role: models.RoleManager, OrgID: 2, KeyID: 9})
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Hour), expires, time.Minute)
+
+	claims, err := tokens.Parse(token)
+	require.NoError(t, err)
+	require.Equal(t, Claims{UserID: 5, Role: models.RoleManager, OrgID: 2, KeyID: 9}, claims)
+
+	t.Run("AdminKey", func(t *testing.T) {
+		fingerprint := tokens.Fingerprint(HashKey("root"))
+		require.NotEqual(t, fingerprint, tokens.Fingerprint(HashKey("other")))
+		require.NotEqual(t, fingerprint, NewTokens([]byte("other"), time.Hour).Fingerprint(HashKey("root")))
+
+		token, _, err := tokens.Issue(Claims{Role: models.RoleAdmin, AdminKey: fingerprint})
+		require.NoError(t, err)
+
+		claims, err := tokens.Parse(token)
+		require.NoError(t, err)
+		require.Equal(t, Claims{Role: models.RoleAdmin, AdminKey: fingerprint}, claims)
+	})
+
+	t.Run("WithoutOrg", func(t *testing.T) {
+		unscoped, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":  "5",
+			"role": string(models.RoleManager),
+			"exp":  time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		_, err = tokens.Parse(unscoped)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("OtherSecret", func(t *testing.T) {
+		_, err := NewTokens([]byte("other"), time.Hour).Parse(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		expired, _, err := NewTokens([]byte("secret"), -time.Minute).Issue(Claims{UserID: 5, Role: models.RoleManager})
+		require.NoError(t, err)
+
+		_, err = tokens.Parse(expired)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Garbage", func(t *testing.T) {
+		_, err := tokens.Parse("not a token")
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
@@ -0,0 +1,299 @@
+// Package encryption encrypts personal data at rest with envelope encryption.
+//
+// Every value is encrypted with its own random data key, and the data key is encrypted (wrapped) with a key
+// of the keyring. Keys are rotated by putting a new key first: values are still decrypted with the key they
+// were wrapped with, and Rewrap moves them to the current key without re-encrypting the data.
+//
+// Encrypted values can't be compared, so they are looked up and kept unique by blind indexes:
+// keyed hashes which are the same for equal values.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown encryption key")
+	ErrMalformed  = errors.New("malformed ciphertext")
+	ErrDecrypt    = errors.New("ciphertext can't be decrypted")
+)
+
+const (
+	// KeySize is the size of keys, values are encrypted with AES-256-GCM.
+	KeySize = 32
+
+	version        = 1
+	maxKeyIDLength = 255
+	nonceSize      = 12
+	tagSize        = 16
+	wrappedKeySize = nonceSize + KeySize + tagSize
+)
+
+// Key encrypts data keys.
+type Key struct {
+	ID     string // stored with ciphertexts to find the key when decrypting
+	Secret []byte
+}
+
+// ParseKeys parses comma separated keys written as id:base64-secret.
+func ParseKeys(s string) ([]Key, error) {
+	var keys []Key
+	for i, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		// the part itself is not in errors as it contains the secret
+		id, secret, ok := strings.Cut(part, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid key #%d, must be id:base64-secret", i+1)
+		}
+		b, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret of key %s: %w", id, err)
+		}
+
+		keys = append(keys, Key{ID: id, Secret: b})
+	}
+	return keys, nil
+}
+
+// Keyring encrypts values with its current key and decrypts them with any of its keys.
+type Keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+	index   []byte
+}
+
+// NewKeyring returns a keyring encrypting with the first key, the others are only used to decrypt.
+// indexKey keys blind indexes, it can't be changed without recomputing indexes of stored values.
+func NewKeyring(indexKey []byte, keys ...Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no encryption keys")
+	}
+	if len(indexKey) < KeySize {
+		return nil, fmt.Errorf("index key must be at least %d bytes, got %d", KeySize, len(indexKey))
+	}
+
+	k := &Keyring{
+		current: keys[0].ID,
+		keys:    make(map[string]cipher.AEAD, len(keys)),
+		index:   indexKey,
+	}
+	for _, key := range keys {
+		if len(key.ID) > maxKeyIDLength {
+			return nil, fmt.Errorf("key ID must be at most %d bytes", maxKeyIDLength)
+		}
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key %s", key.ID)
+		}
+
+		aead, err := newAEAD(key.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", key.ID, err)
+		}
+		k.keys[key.ID] = aead
+	}
+
+	return k, nil
+}
+
+// CurrentKeyID returns ID of the key new values are encrypted with.
+func (k *Keyring) CurrentKeyID() string {
+	return k.current
+}
+
+// Encrypt encrypts the plaintext with a new data key wrapped with the current key.
+// Additional data is authenticated but not encrypted, the same must be passed to Decrypt.
+func (k *Keyring) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := seal(aead, plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.envelope(dataKey, data)
+}
+
+// Decrypt decrypts the ciphertext returned by Encrypt.
+func (k *Keyring) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	e, err := parse(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := k.unwrap(e)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(aead, e.data, additionalData)
+}
+
+// Rewrap returns the ciphertext with its data key wrapped with the current key, the data itself is kept.
+func (k *Keyring) Rewrap(ciphertext []byte) ([]byte, error) {
+	e, err := parse(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if e.keyID == k.current {
+		return ciphertext, nil
+	}
+
+	dataKey, err := k.unwrap(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.envelope(dataKey, e.data)
+}
+
+// BlindIndex returns a keyed hash of the value to look up and deduplicate values without decrypting them.
+func (k *Keyring) BlindIndex(value []byte) []byte {
+	mac := hmac.New(sha256.New, k.index)
+	mac.Write(value)
+	return mac.Sum(nil)
+}
+
+// PassportIndex returns the blind index of the passport.
+func (k *Keyring) PassportIndex(p models.Passport) []byte {
+	return k.BlindIndex([]byte(p.Reveal()))
+}
+
+// EncryptPassport returns the encrypted passport and its blind index.
+// The ciphertext is bound to the index, so it can't be moved to a row of another passport.
+func (k *Keyring) EncryptPassport(p models.Passport) (ciphertext, index []byte, err error) {
+	index = k.PassportIndex(p)
+	ciphertext, err = k.Encrypt([]byte(p.Reveal()), index)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ciphertext, index, nil
+}
+
+// DecryptPassport decrypts the passport returned by EncryptPassport.
+func (k *Keyring) DecryptPassport(ciphertext, index []byte) (models.Passport, error) {
+	b, err := k.Decrypt(ciphertext, index)
+	if err != nil {
+		return models.Passport{}, err
+	}
+
+	series, number, ok := strings.Cut(string(b), " ")
+	if !ok {
+		return models.Passport{}, ErrMalformed
+	}
+	return models.Passport{Series: series, Number: number}, nil
+}
+
+// KeyID returns ID of the key the data key of the ciphertext is wrapped with.
+func KeyID(ciphertext []byte) (string, error) {
+	e, err := parse(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return e.keyID, nil
+}
+
+// envelope is a parsed ciphertext: version, key ID length, key ID, wrapped data key and encrypted data.
+// Wrapped key and data start with their nonces.
+type envelope struct {
+	keyID      string
+	wrappedKey []byte
+	data       []byte
+}
+
+func parse(b []byte) (envelope, error) {
+	if len(b) < 2 || b[0] != version {
+		return envelope{}, ErrMalformed
+	}
+
+	n := int(b[1])
+	if len(b) < 2+n+wrappedKeySize+nonceSize+tagSize {
+		return envelope{}, ErrMalformed
+	}
+
+	return envelope{
+		keyID:      string(b[2 : 2+n]),
+		wrappedKey: b[2+n : 2+n+wrappedKeySize],
+		data:       b[2+n+wrappedKeySize:],
+	}, nil
+}
+
+// envelope wraps the data key with the current key and joins it with the data.
+func (k *Keyring) envelope(dataKey, data []byte) ([]byte, error) {
+	// the key ID is authenticated, so a wrapped key can't be passed off as wrapped with another key
+	wrapped, err := seal(k.keys[k.current], dataKey, []byte(k.current))
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 0, 2+len(k.current)+len(wrapped)+len(data))
+	b = append(b, version, byte(len(k.current)))
+	b = append(b, k.current...)
+	b = append(b, wrapped...)
+	return append(b, data...), nil
+}
+
+func (k *Keyring) unwrap(e envelope) ([]byte, error) {
+	aead, ok := k.keys[e.keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownKey, e.keyID)
+	}
+	return open(aead, e.wrappedKey, []byte(e.keyID))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext with a random nonce and prepends the nonce.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < nonceSize {
+		return nil, ErrMalformed
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
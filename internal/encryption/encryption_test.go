@@ -0,0 +1,132 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	indexKey = bytes.Repeat([]byte{1}, KeySize)
+	oldKey   = Key{ID: "2025", Secret: bytes.Repeat([]byte{2}, KeySize)}
+	newKey   = Key{ID: "2026", Secret: bytes.Repeat([]byte{3}, KeySize)}
+)
+
+func newKeyring(t *testing.T, keys ...Key) *Keyring {
+	k, err := NewKeyring(indexKey, keys...)
+	require.NoError(t, err)
+	return k
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	k := newKeyring(t, oldKey)
+
+	ciphertext, err := k.Encrypt([]byte("secret"), []byte("ad"))
+	require.NoError(t, err)
+	require.NotContains(t, string(ciphertext), "secret")
+
+	plaintext, err := k.Decrypt(ciphertext, []byte("ad"))
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(plaintext))
+
+	// every value has its own data key and nonce
+	other, err := k.Encrypt([]byte("secret"), []byte("ad"))
+	require.NoError(t, err)
+	require.NotEqual(t, ciphertext, other)
+
+	_, err = k.Decrypt(ciphertext, []byte("other"))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	tampered := bytes.Clone(ciphertext)
+	tampered[len(tampered)-1] ^= 1
+	_, err = k.Decrypt(tampered, []byte("ad"))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = k.Decrypt(ciphertext[:10], []byte("ad"))
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	ciphertext, err := newKeyring(t, oldKey).Encrypt([]byte("secret"), nil)
+	require.NoError(t, err)
+
+	_, err = newKeyring(t, newKey).Decrypt(ciphertext, nil)
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	rotated := newKeyring(t, newKey, oldKey)
+	require.Equal(t, "2026", rotated.CurrentKeyID())
+
+	plaintext, err := rotated.Decrypt(ciphertext, nil)
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(plaintext))
+
+	rewrapped, err := rotated.Rewrap(ciphertext)
+	require.NoError(t, err)
+	id, err := KeyID(rewrapped)
+	require.NoError(t, err)
+	require.Equal(t, "2026", id)
+
+	// the old key is not needed any more
+	plaintext, err = newKeyring(t, newKey).Decrypt(rewrapped, nil)
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(plaintext))
+
+	again, err := rotated.Rewrap(rewrapped)
+	require.NoError(t, err)
+	require.Equal(t, rewrapped, again)
+}
+
+func TestKeyring_Passport(t *testing.T) {
+	k := newKeyring(t, oldKey)
+	passport := models.Passport{Series: "0012", Number: "000001"}
+
+	ciphertext, index, err := k.EncryptPassport(passport)
+	require.NoError(t, err)
+	require.Equal(t, k.PassportIndex(passport), index)
+	require.NotEqual(t, k.PassportIndex(models.Passport{Series: "0012", Number: "000010"}), index)
+
+	decrypted, err := k.DecryptPassport(ciphertext, index)
+	require.NoError(t, err)
+	require.Equal(t, passport, decrypted)
+
+	// the ciphertext is bound to its index
+	_, err = k.DecryptPassport(ciphertext, k.PassportIndex(models.Passport{Series: "1234", Number: "567890"}))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	// the index depends on the index key
+	other, err := NewKeyring(bytes.Repeat([]byte{9}, KeySize), oldKey)
+	require.NoError(t, err)
+	require.NotEqual(t, index, other.PassportIndex(passport))
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	_, err := NewKeyring(indexKey)
+	require.Error(t, err)
+
+	_, err = NewKeyring(indexKey[:16], oldKey)
+	require.Error(t, err)
+
+	_, err = NewKeyring(indexKey, Key{ID: "short", Secret: []byte("short")})
+	require.Error(t, err)
+
+	_, err = NewKeyring(indexKey, oldKey, oldKey)
+	require.Error(t, err)
+}
+
+func TestParseKeys(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString(newKey.Secret)
+
+	keys, err := ParseKeys(" 2026:" + secret + ", 2025:" + base64.StdEncoding.EncodeToString(oldKey.Secret) + ",")
+	require.NoError(t, err)
+	require.Equal(t, []Key{newKey, oldKey}, keys)
+
+	_, err = ParseKeys(secret)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), secret)
+
+	_, err = ParseKeys("2026:not base64")
+	require.Error(t, err)
+}
@@ -0,0 +1,32 @@
+// Package encryptiontest provides keyrings for tests.
+package encryptiontest
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/encryption"
+)
+
+// Keyring returns a keyring of keys derived from their IDs, the first one is current.
+// A keyring with the "test" key is returned without IDs. Keys are not secret, they are only good for tests.
+func Keyring(t testing.TB, ids ...string) *encryption.Keyring {
+	t.Helper()
+
+	if len(ids) == 0 {
+		ids = []string{"test"}
+	}
+
+	keys := make([]encryption.Key, len(ids))
+	for i, id := range ids {
+		secret := sha256.Sum256([]byte("key " + id))
+		keys[i] = encryption.Key{ID: id, Secret: secret[:]}
+	}
+
+	index := sha256.Sum256([]byte("index"))
+	k, err := encryption.NewKeyring(index[:], keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
@@ -0,0 +1,259 @@
+// Package events fans changes recorded in the audit log out to subscribers of live activity.
+// Entries of the log are events, their IDs let subscribers resume after reconnecting.
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/audit"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+// ErrClosed is returned by Subscribe once the broker is closed.
+var ErrClosed = errors.New("event broker is closed")
+
+const (
+	// maxReplay bounds events replayed to a resuming subscriber, subscribers which missed more are reset.
+	maxReplay = 1000
+
+	// bufferSize is the number of events queued for a subscriber, slower subscribers are dropped
+	// and resume after reconnecting.
+	bufferSize = 64
+)
+
+// Source reads the audit log of the organization of the context.
+type Source interface {
+	ListAuditEntries(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error)
+	ListAuditEntriesAfter(ctx context.Context, afterID, limit int) ([]models.AuditEntry, error)
+}
+
+// Broker delivers events of each organization to its subscribers.
+// Entries are published once the changes are committed, possibly out of the order of their IDs
+// when concurrent changes are committed in a different order than they were made.
+type Broker struct {
+	source Source
+
+	mu     sync.Mutex
+	subs   map[int]map[*Subscription]struct{} // by organization
+	closed bool
+}
+
+func NewBroker(source Source) *Broker {
+	return &Broker{
+		source: source,
+		subs:   make(map[int]map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives events of an organization matching its filter.
+type Subscription struct {
+	broker *Broker
+	orgID  int
+	match  func(*models.Event) bool
+	c      chan models.Event
+	replay []models.Event
+
+	// the fields are guarded by the mutex of the broker
+	closed    bool
+	replaying bool
+	published map[int]bool // IDs of events published while replaying
+	replayed  map[int]bool
+}
+
+// Replay returns the missed events, they must be delivered before the ones of Events.
+func (s *Subscription) Replay() []models.Event {
+	return s.replay
+}
+
+// Events returns the channel of live events, it's closed when the subscription is dropped for being too slow
+// or the broker is closed.
+func (s *Subscription) Events() <-chan models.Event {
+	return s.c
+}
+
+// Close stops the delivery of events, it may be called more than once.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.drop(s)
+}
+
+// Subscribe subscribes to events of the organization of the context matching the filter.
+// Events after lastEventID are replayed unless it's zero, subscribers which missed more than can be replayed
+// get EventReset instead.
+func (b *Broker) Subscribe(ctx context.Context, lastEventID int, match func(*models.Event) bool) (*Subscription, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		broker:    b,
+		orgID:     orgID,
+		match:     match,
+		c:         make(chan models.Event, bufferSize),
+		replaying: lastEventID > 0,
+		published: make(map[int]bool),
+		replayed:  make(map[int]bool),
+	}
+
+	// the subscription starts before the replay is read, so events committed meanwhile are not lost
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if b.subs[orgID] == nil {
+		b.subs[orgID] = make(map[*Subscription]struct{})
+	}
+	b.subs[orgID][sub] = struct{}{}
+	b.mu.Unlock()
+
+	if lastEventID <= 0 {
+		return sub, nil
+	}
+
+	replay, err := b.replay(ctx, lastEventID)
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, e := range replay {
+		// events published while replaying are already queued, resets are sent regardless of the filter
+		if e.Type != models.EventReset && (sub.published[e.ID] || !match(&e)) {
+			continue
+		}
+		sub.replayed[e.ID] = true
+		sub.replay = append(sub.replay, e)
+	}
+	sub.replaying, sub.published = false, nil
+
+	return sub, nil
+}
+
+// replay returns events after lastEventID, or EventReset with the ID of the latest entry if there are too many.
+func (b *Broker) replay(ctx context.Context, lastEventID int) ([]models.Event, error) {
+	entries, err := b.source.ListAuditEntriesAfter(ctx, lastEventID, maxReplay+1)
+	if err != nil {
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+
+	if len(entries) > maxReplay {
+		log, err := b.source.ListAuditEntries(ctx, models.AuditFilter{Limit: 1})
+		if err != nil {
+			return nil, fmt.Errorf("list audit entries: %w", err)
+		}
+		return []models.Event{{ID: log.Entries[0].ID, Type: models.EventReset, Time: time.Now()}}, nil
+	}
+
+	replay := make([]models.Event, 0, len(entries))
+	for i := range entries {
+		event, ok, err := audit.Event(&entries[i])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			replay = append(replay, event)
+		}
+	}
+
+	return replay, nil
+}
+
+// Publish delivers the event of the committed entry to subscribers of the organization.
+func (b *Broker) Publish(orgID int, entry *models.AuditEntry) {
+	event, ok, err := audit.Event(entry)
+	if err != nil {
+		slog.Warn("Failed to build event", "org", orgID, "id", entry.ID, "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs[orgID] {
+		if !sub.match(&event) || sub.replayed[event.ID] {
+			continue
+		}
+		if sub.replaying {
+			sub.published[event.ID] = true
+		}
+
+		select {
+		case sub.c <- event:
+		default:
+			slog.Warn("Event subscriber is too slow, dropping it", "org", orgID)
+			b.drop(sub)
+		}
+	}
+}
+
+// Notify publishes the committed entry with the ID, it's read from the source.
+func (b *Broker) Notify(ctx context.Context, orgID, entryID int) error {
+	b.mu.Lock()
+	subscribed := len(b.subs[orgID]) > 0
+	b.mu.Unlock()
+	if !subscribed {
+		return nil
+	}
+
+	entries, err := b.source.ListAuditEntriesAfter(tenant.WithOrg(ctx, orgID), entryID-1, 1)
+	if err != nil {
+		return fmt.Errorf("list audit entries: %w", err)
+	}
+	if len(entries) == 0 || entries[0].ID != entryID {
+		return fmt.Errorf("audit entry %d of organization %d not found", entryID, orgID)
+	}
+
+	b.Publish(orgID, &entries[0])
+	return nil
+}
+
+// Reset drops all subscriptions, so subscribers resume and replay events which could have been missed.
+func (b *Broker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, subs := range b.subs {
+		for sub := range subs {
+			b.drop(sub)
+		}
+	}
+}
+
+// Close drops all subscriptions and rejects new ones, it's called on shutdown so streams end.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	b.Reset()
+}
+
+// drop removes the subscription and closes its channel, it's called holding the mutex.
+func (b *Broker) drop(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.c)
+
+	delete(b.subs[sub.orgID], sub)
+	if len(b.subs[sub.orgID]) == 0 {
+		delete(b.subs, sub.orgID)
+	}
+}
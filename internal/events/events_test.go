@@ -0,0 +1,188 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/audit"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/repository/memory"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/stretchr/testify/require"
+)
+
+var all = func(*models.Event) bool { return true }
+
+// setup returns the broker of a memory repository with a user.
+func setup(t *testing.T) (*Broker, *memory.Repository, *models.User) {
+	repo := memory.New()
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, repo.CreateUser(background(), user))
+
+	return NewBroker(repo), repo, user
+}
+
+func background() context.Context {
+	return tenant.WithOrg(context.Background(), models.DefaultOrgID)
+}
+
+// addTask adds an ended task of the user and returns the audit entry of the change.
+func addTask(t *testing.T, repo *memory.Repository, userID int, title string) *models.AuditEntry {
+	task := &models.Task{UserID: userID, Title: title, Since: time.Now().Add(-time.Hour)}
+	task.End(time.Now())
+
+	ctx, rec := audit.Record(background())
+	require.NoError(t, repo.CreateTask(ctx, task))
+
+	entries := rec.Entries()
+	require.Len(t, entries, 1)
+	return entries[0]
+}
+
+func TestBroker_Publish(t *testing.T) {
+	b, repo, user := setup(t)
+
+	other := &models.User{Passport: models.Passport{Series: "1234", Number: "000000"}}
+	require.NoError(t, repo.CreateUser(background(), other))
+
+	sub, err := b.Subscribe(background(), 0, func(e *models.Event) bool { return e.UserID == user.ID })
+	require.NoError(t, err)
+	defer sub.Close()
+	require.Empty(t, sub.Replay())
+
+	b.Publish(models.DefaultOrgID, addTask(t, repo, other.ID, "Hidden"))
+	entry := addTask(t, repo, user.ID, "Report")
+	b.Publish(models.DefaultOrgID, entry)
+	// events of other organizations are never delivered
+	b.Publish(2, entry)
+
+	e := <-sub.Events()
+	require.Equal(t, entry.ID, e.ID)
+	require.Equal(t, models.EventTaskCreated, e.Type)
+	require.Equal(t, user.ID, e.UserID)
+	require.Empty(t, sub.Events())
+}
+
+func TestBroker_Replay(t *testing.T) {
+	b, repo, user := setup(t)
+
+	first := addTask(t, repo, user.ID, "Report")
+	second := addTask(t, repo, user.ID, "Review")
+
+	sub, err := b.Subscribe(background(), first.ID-1, all)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	var ids []int
+	for _, e := range sub.Replay() {
+		ids = append(ids, e.ID)
+	}
+	require.Equal(t, []int{first.ID, second.ID}, ids)
+
+	// replayed events published late are not delivered twice
+	b.Publish(models.DefaultOrgID, second)
+	third := addTask(t, repo, user.ID, "Deploy")
+	b.Publish(models.DefaultOrgID, third)
+
+	e := <-sub.Events()
+	require.Equal(t, third.ID, e.ID)
+	require.Empty(t, sub.Events())
+}
+
+// source returns more entries than can be replayed.
+type source struct{}
+
+func (source) ListAuditEntries(context.Context, models.AuditFilter) (*models.AuditLog, error) {
+	return &models.AuditLog{Entries: []models.AuditEntry{{ID: 5000}}}, nil
+}
+
+func (source) ListAuditEntriesAfter(_ context.Context, afterID, limit int) ([]models.AuditEntry, error) {
+	return make([]models.AuditEntry, limit), nil
+}
+
+func TestBroker_Reset(t *testing.T) {
+	b := NewBroker(source{})
+
+	sub, err := b.Subscribe(background(), 1, func(*models.Event) bool { return false })
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.Len(t, sub.Replay(), 1)
+	require.Equal(t, 5000, sub.Replay()[0].ID)
+	require.Equal(t, models.EventReset, sub.Replay()[0].Type)
+}
+
+func TestBroker_SlowSubscriber(t *testing.T) {
+	b, repo, user := setup(t)
+
+	sub, err := b.Subscribe(background(), 0, all)
+	require.NoError(t, err)
+
+	entry := addTask(t, repo, user.ID, "Report")
+	for i := 0; i <= bufferSize; i++ {
+		e := *entry
+		e.ID += i
+		b.Publish(models.DefaultOrgID, &e)
+	}
+
+	// queued events are delivered before the channel is closed
+	var n int
+	for range sub.Events() {
+		n++
+	}
+	require.Equal(t, bufferSize, n)
+
+	sub.Close()
+}
+
+func TestBroker_Notify(t *testing.T) {
+	b, repo, user := setup(t)
+	entry := addTask(t, repo, user.ID, "Report")
+
+	// entries are not read without subscribers
+	require.NoError(t, b.Notify(context.Background(), models.DefaultOrgID, 404))
+
+	sub, err := b.Subscribe(background(), 0, all)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, b.Notify(context.Background(), models.DefaultOrgID, entry.ID))
+	e := <-sub.Events()
+	require.Equal(t, entry.ID, e.ID)
+
+	require.Error(t, b.Notify(context.Background(), models.DefaultOrgID, 404))
+}
+
+func TestBroker_Close(t *testing.T) {
+	b, _, _ := setup(t)
+
+	sub, err := b.Subscribe(background(), 0, all)
+	require.NoError(t, err)
+
+	b.Close()
+	_, ok := <-sub.Events()
+	require.False(t, ok)
+	sub.Close()
+
+	_, err = b.Subscribe(background(), 0, all)
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+// TestListen_Unavailable checks that a listener waiting for the database stops when ctx is cancelled.
+func TestListen_Unavailable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	listened := make(chan struct{})
+	go func() {
+		Listen(ctx, "postgres://127.0.0.1:1/postgres?sslmode=disable&connect_timeout=1", NewBroker(memory.New()))
+		close(listened)
+	}()
+
+	select {
+	case <-listened:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener is not stopped")
+	}
+}
@@ -0,0 +1,106 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Channel is the PostgreSQL notification channel the audit_log_notify trigger notifies of appended entries.
+const Channel = "audit_log"
+
+const (
+	minReconnect = time.Second
+	maxReconnect = time.Minute
+
+	// pingInterval is how often an idle listener checks its connection, notifications sent
+	// while it's broken are lost.
+	pingInterval = 90 * time.Second
+)
+
+// notification is the payload of a notification of the channel.
+type notification struct {
+	OrgID int `json:"org"`
+	ID    int `json:"id"`
+}
+
+// Listen publishes entries appended by any instance of the service until ctx is cancelled.
+// Notifications are lost while the connection is broken, so subscriptions are reset once it's restored
+// and subscribers replay what they missed.
+func Listen(ctx context.Context, dsn string, b *Broker) {
+	listener := pq.NewListener(dsn, minReconnect, maxReconnect, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Warn("Event listener connection failed", "error", err)
+		}
+	})
+	defer listener.Close()
+
+	if !listen(ctx, listener) {
+		return
+	}
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			if n == nil {
+				slog.Info("Event listener reconnected, resetting subscriptions")
+				b.Reset()
+				continue
+			}
+
+			var payload notification
+			if err := json.Unmarshal([]byte(n.Extra), &payload); err != nil {
+				slog.Warn("Invalid event notification", "payload", n.Extra, "error", err)
+				continue
+			}
+			if err := b.Notify(ctx, payload.OrgID, payload.ID); err != nil {
+				slog.Warn("Failed to publish event", "org", payload.OrgID, "id", payload.ID, "error", err)
+			}
+		case <-ticker.C:
+			if err := listener.Ping(); err != nil {
+				slog.Warn("Event listener ping failed", "error", err)
+			}
+		}
+	}
+}
+
+// listen subscribes the listener to the channel, retrying with backoff if the server refuses, and reports
+// whether it's subscribed before ctx is cancelled. The listener re-subscribes by itself after reconnects,
+// while it's not connected the subscription waits for the connection, it's unblocked by closing the listener.
+func listen(ctx context.Context, listener *pq.Listener) bool {
+	delay := minReconnect
+	for {
+		errc := make(chan error, 1)
+		go func() {
+			errc <- listener.Listen(Channel)
+		}()
+
+		var err error
+		select {
+		case <-ctx.Done():
+			return false
+		case err = <-errc:
+		}
+		if err == nil || errors.Is(err, pq.ErrChannelAlreadyOpen) {
+			return true
+		}
+
+		slog.Warn("Failed to listen for events, retrying", "channel", Channel, "error", err, "delay", delay)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxReconnect)
+	}
+}
@@ -0,0 +1,159 @@
+// Package health serves liveness and readiness probes of the service.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+)
+
+// Statuses of the readiness report and its checks.
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+)
+
+// hiddenError replaces errors of failed checks in responses, the probe is public and errors may reveal internals.
+const hiddenError = "check failed"
+
+// checkTimeout limits each check, probes of orchestrators usually time out in a few seconds.
+const checkTimeout = 2 * time.Second
+
+// Check returns an error if a dependency of the service can't be used.
+type Check func(ctx context.Context) error
+
+// Report is the readiness of the service with results of each check.
+type Report struct {
+	Status       string                 `json:"status"`
+	ShuttingDown bool                   `json:"shuttingDown,omitempty"`
+	Checks       map[string]CheckResult `json:"checks"`
+}
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Checker runs named checks of the service dependencies.
+type Checker struct {
+	names        []string
+	checks       map[string]Check
+	shuttingDown atomic.Bool
+}
+
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]Check)}
+}
+
+// Add registers the check under the name, it must be called before the checker is used.
+func (c *Checker) Add(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks[name] = check
+	sort.Strings(c.names)
+}
+
+// ShutDown makes the service not ready, so traffic is routed elsewhere while requests are drained.
+func (c *Checker) ShutDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Check runs all checks concurrently and reports the service ready if all of them pass and it's not shutting down.
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{
+		Status:       StatusReady,
+		ShuttingDown: c.shuttingDown.Load(),
+		Checks:       make(map[string]CheckResult, len(c.checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, name := range c.names {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(ctx)
+			res := CheckResult{
+				Status:    StatusOK,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				res.Status = StatusFail
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Checks[name] = res
+			mu.Unlock()
+		}(name, c.checks[name])
+	}
+	wg.Wait()
+
+	for _, res := range report.Checks {
+		if res.Status != StatusOK {
+			report.Status = StatusNotReady
+		}
+	}
+	if report.ShuttingDown {
+		report.Status = StatusNotReady
+	}
+
+	return report
+}
+
+// Livez reports the process is alive, dependencies are not checked
+// so their outage doesn't make the orchestrator restart the service.
+// @Summary Liveness probe
+// @Tags health
+// @Produce plain
+// @Success 200 {string} string "OK"
+// @Router /livez [get]
+func (c *Checker) Livez(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("OK"))
+}
+
+// Readyz reports whether the service can serve requests, the status is 503 if it can't.
+// Errors of failed checks are logged, the response only tells which checks failed.
+// @Summary Readiness probe
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report "Service is ready"
+// @Failure 503 {object} health.Report "Service is not ready"
+// @Router /readyz [get]
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusReady {
+		status = http.StatusServiceUnavailable
+		logging.FromContext(r.Context()).Warn("Service is not ready", slog.Any("checks", report.Checks), "shuttingDown", report.ShuttingDown)
+	}
+	for name, res := range report.Checks {
+		if res.Error != "" {
+			res.Error = hiddenError
+			report.Checks[name] = res
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to write response", "error", err)
+	}
+}
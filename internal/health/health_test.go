@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readyz(t *testing.T, c *Checker) (int, Report) {
+	rec := httptest.NewRecorder()
+	c.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	return rec.Code, report
+}
+
+func TestReadyz(t *testing.T) {
+	c := NewChecker()
+	c.Add("database", func(context.Context) error { return nil })
+	c.Add("name_service", func(context.Context) error { return nil })
+
+	status, report := readyz(t, c)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, StatusReady, report.Status)
+	require.False(t, report.ShuttingDown)
+	require.Len(t, report.Checks, 2)
+	require.Equal(t, StatusOK, report.Checks["database"].Status)
+	require.Empty(t, report.Checks["database"].Error)
+}
+
+func TestReadyz_CheckFailed(t *testing.T) {
+	c := NewChecker()
+	c.Add("database", func(context.Context) error { return nil })
+	c.Add("migrations", func(context.Context) error { return errors.New("schema version is 1, expected 2") })
+
+	status, report := readyz(t, c)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, StatusNotReady, report.Status)
+	require.Equal(t, StatusOK, report.Checks["database"].Status)
+	require.Equal(t, CheckResult{Status: StatusFail, LatencyMs: report.Checks["migrations"].LatencyMs, Error: hiddenError}, report.Checks["migrations"])
+}
+
+func TestReadyz_Timeout(t *testing.T) {
+	c := NewChecker()
+	c.Add("name_service", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := c.Check(ctx)
+	require.Equal(t, StatusNotReady, report.Status)
+	require.Equal(t, context.Canceled.Error(), report.Checks["name_service"].Error)
+}
+
+func TestReadyz_ShuttingDown(t *testing.T) {
+	c := NewChecker()
+	c.Add("database", func(context.Context) error { return nil })
+	c.ShutDown()
+
+	status, report := readyz(t, c)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, StatusNotReady, report.Status)
+	require.True(t, report.ShuttingDown)
+	require.Equal(t, StatusOK, report.Checks["database"].Status)
+}
+
+func TestLivez(t *testing.T) {
+	c := NewChecker()
+	c.Add("database", func(context.Context) error { return errors.New("connection refused") })
+
+	rec := httptest.NewRecorder()
+	c.Livez(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
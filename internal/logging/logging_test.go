@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "WARN", FormatJSON)
+	require.NoError(t, err)
+
+	logger.Info("skipped")
+	logger.Warn("written", "key", "value")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "written", record["msg"])
+	require.Equal(t, "value", record["key"])
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "verbose", FormatText)
+	require.ErrorContains(t, err, "unknown log level")
+
+	_, err = New(&bytes.Buffer{}, "info", "xml")
+	require.ErrorContains(t, err, "unknown log format")
+}
+
+func TestFromContext(t *testing.T) {
+	require.Same(t, slog.Default(), FromContext(context.Background()))
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	require.Same(t, logger, FromContext(WithLogger(context.Background(), logger)))
+}
+
+func TestRequestID(t *testing.T) {
+	require.Empty(t, RequestID(context.Background()))
+	require.Equal(t, "req-42", RequestID(WithRequestID(context.Background(), "req-42")))
+}
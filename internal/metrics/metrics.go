@@ -0,0 +1,213 @@
+// Package metrics exposes Prometheus metrics of HTTP requests, the database pool and business events.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "time_tracker"
+
+// unmatchedRoute labels requests which match no registered route, so unknown paths don't add label values.
+const unmatchedRoute = "unmatched"
+
+// Route returns the path pattern of mux the request matches, "unmatched" if there is none.
+// The method of the pattern is left out, it's labelled on its own.
+// Metrics, spans and logs use it alike to label requests by route.
+func Route(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	if pattern == "" {
+		return unmatchedRoute
+	}
+
+	// patterns may start with a method
+	if _, path, found := strings.Cut(pattern, " "); found {
+		return path
+	}
+	return pattern
+}
+
+// otherMethod labels requests with methods beyond the standard ones, clients may send any method.
+const otherMethod = "OTHER"
+
+// methodLabel returns the label of the request method.
+func methodLabel(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return r.Method
+	}
+	return otherMethod
+}
+
+// scrapeTimeout limits queries made to collect metrics.
+const scrapeTimeout = 5 * time.Second
+
+// Metrics holds collectors of the service in its own registry.
+// It implements usecase.Metrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+
+	tasksStarted prometheus.Counter
+	tasksEnded   prometheus.Counter
+	usersCreated prometheus.Counter
+	nameService  *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route template and status.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by route template.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		tasksStarted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_started_total",
+			Help:      "Number of started tasks.",
+		}),
+		tasksEnded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_ended_total",
+			Help:      "Number of ended tasks.",
+		}),
+		usersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "users_created_total",
+			Help:      "Number of created users.",
+		}),
+		nameService: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "name_service_calls_total",
+			Help:      "Number of name service calls by outcome: ok, not_found, unavailable or error.",
+		}, []string{"outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.tasksStarted,
+		m.tasksEnded,
+		m.usersCreated,
+		m.nameService,
+	)
+
+	return m
+}
+
+// Handler serves metrics in Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB adds connection pool gauges of the database.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterRunningTasks adds the gauge of currently running tasks, count is called on every scrape.
+func (m *Metrics) RegisterRunningTasks(count func(ctx context.Context) (int, error)) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tasks_running",
+		Help:      "Number of currently running tasks, paused tasks are not counted.",
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+		defer cancel()
+
+		n, err := count(ctx)
+		if err != nil {
+			slog.Warn("Failed to count running tasks", "error", err)
+			return 0
+		}
+		return float64(n)
+	}))
+}
+
+func (m *Metrics) TaskStarted() {
+	m.tasksStarted.Inc()
+}
+
+func (m *Metrics) TaskEnded() {
+	m.tasksEnded.Inc()
+}
+
+func (m *Metrics) UserCreated() {
+	m.usersCreated.Inc()
+}
+
+func (m *Metrics) NameServiceCalled(outcome string) {
+	m.nameService.WithLabelValues(outcome).Inc()
+}
+
+// Instrument counts requests served by next and measures their latency.
+// Requests are labelled by the pattern of mux they match, not by the raw path, and by the standard method
+// or OTHER to keep cardinality bounded.
+func (m *Metrics) Instrument(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := Route(mux, r)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		defer func() {
+			method := methodLabel(r)
+			m.requests.WithLabelValues(method, route, strconv.Itoa(rec.status)).Inc()
+			m.duration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// statusRecorder remembers the status written to the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	r.wroteHeader = true
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach other features of the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestInstrument_RouteTemplate(t *testing.T) {
+	m := New()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	h := m.Instrument(mux, mux)
+
+	for _, path := range []string{"/users/1", "/users/2", "/health", "/unknown/3"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t, m)
+	require.Contains(t, body, `time_tracker_http_requests_total{method="GET",route="/users/{id}",status="404"} 2`)
+	require.Contains(t, body, `time_tracker_http_requests_total{method="GET",route="/health",status="200"} 1`)
+	require.Contains(t, body, `time_tracker_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	require.Contains(t, body, `time_tracker_http_request_duration_seconds_count{method="GET",route="/users/{id}"} 2`)
+	require.NotContains(t, body, "/users/1")
+}
+
+func TestInstrument_Method(t *testing.T) {
+	m := New()
+
+	mux := http.NewServeMux()
+	h := m.Instrument(mux, mux)
+
+	for _, method := range []string{"FOO", "BAR", http.MethodDelete} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/", nil))
+	}
+
+	body := scrape(t, m)
+	require.Contains(t, body, `time_tracker_http_requests_total{method="OTHER",route="unmatched",status="404"} 2`)
+	require.Contains(t, body, `time_tracker_http_requests_total{method="DELETE",route="unmatched",status="404"} 1`)
+	require.NotContains(t, body, "FOO")
+}
+
+func TestBusinessMetrics(t *testing.T) {
+	m := New()
+	m.RegisterRunningTasks(func(context.Context) (int, error) { return 3, nil })
+
+	m.TaskStarted()
+	m.TaskStarted()
+	m.TaskEnded()
+	m.UserCreated()
+	m.NameServiceCalled("unavailable")
+
+	body := scrape(t, m)
+	require.Contains(t, body, "time_tracker_tasks_started_total 2")
+	require.Contains(t, body, "time_tracker_tasks_ended_total 1")
+	require.Contains(t, body, "time_tracker_tasks_running 3")
+	require.Contains(t, body, "time_tracker_users_created_total 1")
+	require.Contains(t, body, `time_tracker_name_service_calls_total{outcome="unavailable"} 1`)
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// APIKey authenticates requests of a user, only a hash of the key is stored.
+type APIKey struct {
+	ID        int
+	UserID    int
+	OrgID     int // organization of the user, keys are looked up before the organization is known
+	Name      string
+	Prefix    string // first characters of the key to tell keys apart
+	Hash      []byte
+	CreatedAt time.Time
+	RevokedAt time.Time // zero for active key
+}
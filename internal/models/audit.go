@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction is a kind of change recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditEntity is a kind of entity whose changes are recorded in the audit log.
+type AuditEntity string
+
+const (
+	AuditUser AuditEntity = "user"
+	AuditTask AuditEntity = "task"
+)
+
+func (e AuditEntity) IsValid() bool {
+	switch e {
+	case AuditUser, AuditTask:
+		return true
+	}
+	return false
+}
+
+// AuditEntry is a change of an entity, entries are never changed or deleted.
+type AuditEntry struct {
+	ID        int
+	CreatedAt time.Time
+	ActorID   int  // zero for the admin key and internal calls
+	ActorRole Role // empty for internal calls such as background workers
+	Action    AuditAction
+	Entity    AuditEntity
+	EntityID  int
+	Before    json.RawMessage // nil for created entities
+	After     json.RawMessage // nil for deleted entities
+	RequestID string          // empty for changes made outside of requests
+}
+
+// AuditFilter selects entries of the audit log, zero values are ignored.
+type AuditFilter struct {
+	Entity   AuditEntity
+	EntityID int
+	ActorID  int
+	From, To time.Time // entries created in [From, To)
+	Limit    int
+	Cursor   string // NextCursor of the previous page, empty for the first page
+}
+
+// AuditLog is a page of audit entries, the newest first.
+type AuditLog struct {
+	Entries    []AuditEntry
+	NextCursor string // empty on the last page
+}
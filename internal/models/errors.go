@@ -0,0 +1,12 @@
+package models
+
+import "errors"
+
+// ErrDuplicate is returned by storage when an entity violates a uniqueness rule.
+var ErrDuplicate = errors.New("duplicate")
+
+// ErrStale is returned by storage when an entity was changed after the caller has read it.
+var ErrStale = errors.New("stale")
+
+// ErrInvalidCursor is returned by storage when a pagination cursor can't be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
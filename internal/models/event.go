@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType is a kind of change streamed to subscribers of live activity.
+type EventType string
+
+const (
+	EventTaskStarted EventType = "task.started"
+	EventTaskCreated EventType = "task.created" // task added after the fact, it's already ended
+	EventTaskEnded   EventType = "task.ended"
+	EventTaskUpdated EventType = "task.updated" // task edited, paused or resumed
+	EventTaskDeleted EventType = "task.deleted" // task deleted with its user
+	EventUserCreated EventType = "user.created"
+
+	// EventReset tells the subscriber that missed events can't be replayed, so it must reload the state.
+	EventReset EventType = "reset"
+)
+
+// Event is a change streamed to subscribers, events are built from entries of the audit log.
+type Event struct {
+	ID     int // ID of the audit entry of the change
+	Type   EventType
+	Time   time.Time
+	UserID int             // user of the task or the created user, zero for EventReset
+	TaskID int             // zero for events of users
+	Data   json.RawMessage // snapshot of the entity after the change, before it for EventTaskDeleted, nil for EventReset
+}
+
+// EventFilter selects events streamed to a subscriber, zero values are ignored.
+type EventFilter struct {
+	UserID int
+	TeamID int
+}
@@ -0,0 +1,11 @@
+package models
+
+// DefaultOrgID is the organization which data stored before organizations were introduced belongs to.
+const DefaultOrgID = 1
+
+// Org is an organization, a tenant of the service. Users, their tasks and everything else belong to one organization
+// and are never visible to another one.
+type Org struct {
+	ID   int
+	Name string
+}
@@ -0,0 +1,12 @@
+package models
+
+type Project struct {
+	ID          int
+	Name        string
+	Description string
+}
+
+type Tag struct {
+	ID   int
+	Name string
+}
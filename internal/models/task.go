@@ -3,16 +3,199 @@ package models
 import "time"
 
 type Task struct {
-	ID      int
-	UserID  int
-	Since   time.Time
-	Until   time.Time
-	Minutes int
+	ID          int
+	UserID      int
+	Title       string
+	Description string
+	ProjectID   int // zero if the task is not in a project
+	Tags        []Tag
+	Since       time.Time
+	Until       time.Time
+	Minutes     int
+	Segments    []TaskSegment // worked intervals ordered by time, the last one is open while the task is running
+	AutoStopped bool          // ended by the auto-stop policy rather than by the user
+}
+
+// TaskSegment is an interval of work on a task, Until is zero while the work goes on.
+type TaskSegment struct {
+	Since time.Time
+	Until time.Time
+}
+
+// TaskState is a state of a task derived from its end time and segments.
+type TaskState string
+
+const (
+	TaskRunning  TaskState = "running"
+	TaskPaused   TaskState = "paused"
+	TaskFinished TaskState = "finished"
+)
+
+// TaskRevision identifies the progress of a task, ending, pausing and resuming the task changes it.
+type TaskRevision struct {
+	State    TaskState
+	Segments int
 }
 
 func NewTask(userID int) *Task {
+	now := time.Now()
 	return &Task{
-		UserID: userID,
-		Since:  time.Now(),
+		UserID:   userID,
+		Since:    now,
+		Segments: []TaskSegment{{Since: now}},
 	}
 }
+
+func (t *Task) State() TaskState {
+	switch {
+	case !t.Until.IsZero():
+		return TaskFinished
+	case len(t.Segments) > 0 && !t.Segments[len(t.Segments)-1].Until.IsZero():
+		return TaskPaused
+	default:
+		return TaskRunning
+	}
+}
+
+func (t *Task) Revision() TaskRevision {
+	// a task without segments is stored with one spanning the task
+	return TaskRevision{State: t.State(), Segments: max(len(t.Segments), 1)}
+}
+
+// IsRunning reports whether the task is neither ended nor paused.
+func (t *Task) IsRunning() bool {
+	return t.State() == TaskRunning
+}
+
+// IsFinished reports whether the task is ended.
+func (t *Task) IsFinished() bool {
+	return t.State() == TaskFinished
+}
+
+// Pause closes the current segment of the running task.
+func (t *Task) Pause(at time.Time) {
+	if len(t.Segments) == 0 {
+		t.Segments = []TaskSegment{{Since: t.Since}}
+	}
+	t.Segments[len(t.Segments)-1].Until = at
+}
+
+// Resume opens a new segment of the paused task.
+func (t *Task) Resume(at time.Time) {
+	t.Segments = append(t.Segments, TaskSegment{Since: at})
+}
+
+// End stops the task at the given time and calculates minutes spent in its segments.
+func (t *Task) End(at time.Time) {
+	if t.State() == TaskRunning {
+		t.Pause(at)
+	}
+	t.Until = at
+	t.Minutes = int(t.Worked().Minutes())
+}
+
+// SetPeriod moves bounds of the task, segments are clipped or stretched to fit them.
+// Zero until keeps the task running.
+func (t *Task) SetPeriod(since, until time.Time) {
+	var segments []TaskSegment
+	for _, s := range t.Segments {
+		if (!until.IsZero() && !s.Since.Before(until)) || (!s.Until.IsZero() && !s.Until.After(since)) {
+			continue
+		}
+		if s.Since.Before(since) {
+			s.Since = since
+		}
+		if !until.IsZero() && (s.Until.IsZero() || s.Until.After(until)) {
+			s.Until = until
+		}
+		segments = append(segments, s)
+	}
+
+	if len(segments) == 0 {
+		segments = []TaskSegment{{Since: since, Until: until}}
+	}
+	segments[0].Since = since
+	if !until.IsZero() {
+		segments[len(segments)-1].Until = until
+	}
+
+	t.Since = since
+	t.Until = until
+	t.Segments = segments
+	t.Minutes = 0
+	if !until.IsZero() {
+		t.Minutes = int(t.Worked().Minutes())
+	}
+}
+
+// Worked returns time spent in the closed segments of the task.
+func (t *Task) Worked() time.Duration {
+	var d time.Duration
+	for _, s := range t.Segments {
+		if !s.Until.IsZero() {
+			d += s.Until.Sub(s.Since)
+		}
+	}
+	return d
+}
+
+// TaskFilter narrows down listed tasks, zero fields are ignored.
+type TaskFilter struct {
+	Title     string // substring of the title
+	ProjectID int
+	TagID     int
+	From      time.Time // tasks which end after from or are not ended
+	To        time.Time // tasks which start before to
+	State     TaskState
+}
+
+// TaskSort is a field tasks are sorted by.
+type TaskSort string
+
+const (
+	TaskSortID      TaskSort = "id"
+	TaskSortSince   TaskSort = "since"
+	TaskSortUntil   TaskSort = "until" // running tasks go after the ended ones
+	TaskSortMinutes TaskSort = "minutes"
+)
+
+func (s TaskSort) IsValid() bool {
+	switch s {
+	case TaskSortID, TaskSortSince, TaskSortUntil, TaskSortMinutes:
+		return true
+	}
+	return false
+}
+
+type TaskListOpts struct {
+	TaskFilter
+	Sort   TaskSort
+	Desc   bool
+	Limit  int    // zero means no limit
+	Cursor string // NextCursor of the previous page, empty for the first page
+}
+
+type TaskList struct {
+	Tasks      []Task
+	Count      int // number of tasks matching the filter on all pages
+	Limit      int
+	NextCursor string // empty on the last page
+}
+
+// TaskEffort is time spent on a task within a period.
+type TaskEffort struct {
+	TaskID      int
+	Since       time.Time
+	Until       time.Time // zero for running task
+	Duration    time.Duration
+	AutoStopped bool
+}
+
+// Workload is time spent by a user on tasks within a period.
+type Workload struct {
+	UserID int
+	From   time.Time
+	To     time.Time
+	Tasks  []TaskEffort // sorted by duration descending
+	Total  time.Duration
+}
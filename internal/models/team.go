@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type Team struct {
+	ID        int
+	Name      string
+	ManagerID int   // zero if the team has no manager
+	MemberIDs []int // sorted, a user may be a member of several teams
+}
+
+// MemberEffort is time spent by a team member on each task within a period.
+type MemberEffort struct {
+	UserID     int
+	Name       string
+	Surname    string
+	Patronymic string
+	Tasks      []TaskEffort // sorted by duration descending
+	Total      time.Duration
+}
+
+// TeamReport is time spent by members of a team within a period.
+type TeamReport struct {
+	TeamID  int
+	From    time.Time
+	To      time.Time
+	Members []MemberEffort // sorted by total descending, members who tracked nothing are included
+	Total   time.Duration
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TimesheetRow is time spent by a user on a task within a period.
+type TimesheetRow struct {
+	User        User
+	TaskID      int
+	Title       string
+	Since       time.Time
+	Until       time.Time // zero for running task
+	Duration    time.Duration
+	AutoStopped bool
+}
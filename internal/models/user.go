@@ -1,11 +1,165 @@
 package models
 
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode"
+)
+
+// Role defines what a user is allowed to do.
+type Role string
+
+const (
+	RoleEmployee Role = "employee" // tracks own tasks
+	RoleManager  Role = "manager"  // reads reports of the team
+	RoleAdmin    Role = "admin"    // manages users
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleEmployee, RoleManager, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Permission is a privilege beyond reading and changing data, granted to roles.
+type Permission string
+
+const (
+	PermissionRevealPassports Permission = "reveal_passports" // see passports unmasked
+)
+
+// Can reports whether the role is granted the permission.
+func (r Role) Can(p Permission) bool {
+	switch p {
+	case PermissionRevealPassports:
+		return r == RoleAdmin
+	}
+	return false
+}
+
+// EnrichStatus is the state of filling a user with data from the name service.
+type EnrichStatus string
+
+const (
+	EnrichDone     EnrichStatus = "done"      // filled, or created without the name service
+	EnrichPending  EnrichStatus = "pending"   // to be filled, the name service was unavailable
+	EnrichNotFound EnrichStatus = "not_found" // the name service doesn't know the person
+	EnrichFailed   EnrichStatus = "failed"    // attempts to fill the user are exhausted
+)
+
 type User struct {
-	ID             int
-	PassportSerie  int
-	PassportNumber int
-	Name           string
-	Surname        string
-	Patronymic     string
-	Address        string
+	ID         int
+	Passport   Passport
+	Name       string
+	Surname    string
+	Patronymic string
+	Address    string
+	Role       Role
+	ManagerID  int // zero if the user has no manager
+
+	EnrichStatus   EnrichStatus // EnrichDone if empty
+	EnrichAttempts int          // failed attempts to fill the user
+}
+
+// FullName returns surname, name and patronymic separated by spaces, missing parts are skipped.
+func (u *User) FullName() string {
+	return strings.Join(strings.Fields(u.Surname+" "+u.Name+" "+u.Patronymic), " ")
+}
+
+// ErrInvalidPassport is returned by ParsePassport for strings which are not a passport.
+var ErrInvalidPassport = errors.New("passport must be a 4-digit series and a 6-digit number")
+
+// Passport is a series and a number of a passport. They are kept as strings, as leading zeros are significant.
+type Passport struct {
+	Series string // 4 digits
+	Number string // 6 digits
+}
+
+// ParsePassport parses a passport written as "1234 567890". Spaces and dashes are ignored wherever they are,
+// so "1234567890", "12 34 567890" and "1234-567890" are the same passport.
+// Errors don't contain the input, so they are safe to log.
+func ParsePassport(s string) (Passport, error) {
+	digits := make([]rune, 0, 10)
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, r)
+		case unicode.IsSpace(r) || unicode.Is(unicode.Pd, r):
+		default:
+			return Passport{}, ErrInvalidPassport
+		}
+	}
+	if len(digits) != 10 {
+		return Passport{}, ErrInvalidPassport
+	}
+
+	return Passport{Series: string(digits[:4]), Number: string(digits[4:])}, nil
+}
+
+// Reveal returns the passport in the form it is written in the document.
+func (p Passport) Reveal() string {
+	return p.Series + " " + p.Number
+}
+
+// String returns the passport masked except for the last digits of the number, so it is safe to print.
+func (p Passport) String() string {
+	if p.IsZero() {
+		return ""
+	}
+	return p.MaskedSeries() + " " + p.MaskedNumber()
+}
+
+// GoString masks the passport in %#v output.
+func (p Passport) GoString() string {
+	return "models.Passport{" + p.String() + "}"
+}
+
+// LogValue masks the passport in logs.
+func (p Passport) LogValue() slog.Value {
+	return slog.StringValue(p.String())
+}
+
+// MaskedSeries returns the series with all digits masked.
+func (p Passport) MaskedSeries() string {
+	return mask(p.Series, 0)
+}
+
+// MaskedNumber returns the number with all but the last two digits masked.
+func (p Passport) MaskedNumber() string {
+	return mask(p.Number, 2)
+}
+
+func (p Passport) IsZero() bool {
+	return p.Series == "" && p.Number == ""
+}
+
+// mask replaces all but the last visible characters with asterisks.
+func mask(s string, visible int) string {
+	if len(s) <= visible {
+		return strings.Repeat("*", len(s))
+	}
+	return strings.Repeat("*", len(s)-visible) + s[len(s)-visible:]
+}
+
+type UserList struct {
+	Users []User
+	Count int
+	Pages int
+	Page  int
+}
+
+type UserListOpts struct {
+	Page  int
+	Limit int
+	Name  string // поиск по ФИО
+
+	// filters by user fields, empty values are ignored
+	FirstName  string
+	Surname    string
+	Patronymic string
+	Address    string
+	Passport   Passport // matched exactly, passports are stored encrypted
 }
@@ -0,0 +1,208 @@
+package nameservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+var (
+	// ErrNotFound is returned when the service does not know the passport.
+	ErrNotFound = errors.New("person not found")
+	// ErrUnavailable is returned when the service can't be reached or keeps failing after all retries.
+	ErrUnavailable = errors.New("name service unavailable")
+)
+
+type Options struct {
+	Timeout time.Duration // timeout of a single request
+	Retries int           // number of retries after the first attempt
+	Backoff time.Duration // delay before the first retry, doubled on every next one
+}
+
+type Client struct {
+	baseURL string
+	http    *http.Client
+	retries int
+	backoff time.Duration
+}
+
+type People struct {
+	Surname    string `json:"surname"`
+	Name       string `json:"name"`
+	Patronymic string `json:"patronymic"`
+	Address    string `json:"address"`
+}
+
+func New(baseURL string, opts Options) *Client {
+	return &Client{
+		baseURL: baseURL,
+		// the transport makes a span per attempt and propagates the trace context of ctx to the service,
+		// spans record the URL of the request, so the query with the passport is hidden from them
+		http: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: hideQuery{otelhttp.NewTransport(restoreQuery{http.DefaultTransport})},
+		},
+		retries: opts.Retries,
+		backoff: opts.Backoff,
+	}
+}
+
+type queryKey struct{}
+
+// hideQuery passes requests to next without the query, restoreQuery puts it back before they are sent.
+type hideQuery struct {
+	next http.RoundTripper
+}
+
+func (t hideQuery) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.RawQuery == "" {
+		return t.next.RoundTrip(r)
+	}
+
+	r = r.Clone(context.WithValue(r.Context(), queryKey{}, r.URL.RawQuery))
+	r.URL.RawQuery = ""
+	return t.next.RoundTrip(r)
+}
+
+type restoreQuery struct {
+	next http.RoundTripper
+}
+
+func (t restoreQuery) RoundTrip(r *http.Request) (*http.Response, error) {
+	if query, ok := r.Context().Value(queryKey{}).(string); ok {
+		r = r.Clone(r.Context())
+		r.URL.RawQuery = query
+	}
+	return t.next.RoundTrip(r)
+}
+
+// Enrich fills user name and address using passport series and number of the user.
+func (c *Client) Enrich(ctx context.Context, user *models.User) error {
+	people, err := c.GetPeople(ctx, user.Passport)
+	if err != nil {
+		return err
+	}
+
+	user.Name = people.Name
+	user.Surname = people.Surname
+	user.Patronymic = people.Patronymic
+	user.Address = people.Address
+
+	return nil
+}
+
+// GetPeople requests person info, retrying with exponential backoff on network errors and 5xx responses.
+func (c *Client) GetPeople(ctx context.Context, passport models.Passport) (*People, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse name service url: %w", err)
+	}
+	u = u.JoinPath("info")
+	u.RawQuery = url.Values{
+		"passportSerie":  {passport.Series},
+		"passportNumber": {passport.Number},
+	}.Encode()
+
+	delay := c.backoff
+	for attempt := 0; ; attempt++ {
+		people, err := c.do(ctx, u.String())
+		if err == nil {
+			return people, nil
+		}
+
+		var rerr retryableError
+		if !errors.As(err, &rerr) {
+			return nil, err
+		}
+		if attempt >= c.retries {
+			return nil, fmt.Errorf("%w: %w", ErrUnavailable, rerr.err)
+		}
+
+		logging.FromContext(ctx).Warn("Name service request failed, retrying", "error", rerr.err, "attempt", attempt+1, "delay", delay)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ErrUnavailable, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// Ping checks that the service responds, it makes a single request without retries.
+// The request has no passport, so any response but a server error means the service is up.
+func (c *Client) Ping(ctx context.Context) error {
+	u, err := url.JoinPath(c.baseURL, "info")
+	if err != nil {
+		return fmt.Errorf("parse name service url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode >= 500 {
+		return fmt.Errorf("%w: unexpected status: %d", ErrUnavailable, res.StatusCode)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, rawURL string) (*People, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		// the query has the passport, it must not get to logs
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			redacted := *req.URL
+			redacted.RawQuery = ""
+			uerr.URL = redacted.String()
+		}
+		return nil, retryableError{err: err}
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusOK:
+	case res.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		return nil, retryableError{err: fmt.Errorf("unexpected status: %d", res.StatusCode)}
+	default:
+		return nil, fmt.Errorf("unexpected status: %d", res.StatusCode)
+	}
+
+	var people People
+	if err := json.NewDecoder(res.Body).Decode(&people); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &people, nil
+}
+
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
@@ -0,0 +1,146 @@
+package nameservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/nameservice"
+	"github.com/Nicholas2012/time-tracker/internal/nameservice/nameservicetest"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEnrich_OK(t *testing.T) {
+	srv := nameservicetest.NewServer(t)
+	srv.Add(models.Passport{Series: "1234", Number: "567890"}, nameservice.People{Name: "Ivan", Surname: "Ivanov", Patronymic: "Ivanovich", Address: "Moscow"})
+
+	c := nameservice.New(srv.URL, nameservice.Options{Timeout: time.Second})
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, c.Enrich(context.TODO(), user))
+	require.Equal(t, "Ivan", user.Name)
+	require.Equal(t, "Ivanov", user.Surname)
+	require.Equal(t, "Ivanovich", user.Patronymic)
+	require.Equal(t, "Moscow", user.Address)
+}
+
+func TestEnrich_NotFound(t *testing.T) {
+	srv := nameservicetest.NewServer(t)
+	c := nameservice.New(srv.URL, nameservice.Options{Timeout: time.Second, Retries: 3})
+
+	err := c.Enrich(context.TODO(), &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}})
+	require.ErrorIs(t, err, nameservice.ErrNotFound)
+	require.Equal(t, 1, srv.Requests())
+}
+
+func TestEnrich_Retry(t *testing.T) {
+	srv := nameservicetest.NewServer(t)
+	srv.Add(models.Passport{Series: "1234", Number: "567890"}, nameservice.People{Name: "Ivan"})
+	srv.FailNext(2)
+
+	c := nameservice.New(srv.URL, nameservice.Options{Timeout: time.Second, Retries: 2, Backoff: time.Millisecond})
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, c.Enrich(context.TODO(), user))
+	require.Equal(t, "Ivan", user.Name)
+	require.Equal(t, 3, srv.Requests())
+}
+
+func TestEnrich_Unavailable(t *testing.T) {
+	srv := nameservicetest.NewServer(t)
+	srv.FailNext(10)
+
+	c := nameservice.New(srv.URL, nameservice.Options{Timeout: time.Second, Retries: 2, Backoff: time.Millisecond})
+
+	err := c.Enrich(context.TODO(), &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}})
+	require.ErrorIs(t, err, nameservice.ErrUnavailable)
+	require.Equal(t, 3, srv.Requests())
+}
+
+func TestEnrich_Down(t *testing.T) {
+	srv := nameservicetest.NewServer(t)
+	srv.Close()
+
+	c := nameservice.New(srv.URL, nameservice.Options{Timeout: time.Second, Retries: 1, Backoff: time.Millisecond})
+
+	err := c.Enrich(context.TODO(), &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}})
+	require.ErrorIs(t, err, nameservice.ErrUnavailable)
+	require.Contains(t, err.Error(), "/info")
+	require.NotContains(t, err.Error(), "567890")
+}
+
+func TestEnrich_TraceContext(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	srv := nameservicetest.NewServer(t)
+	srv.Add(models.Passport{Series: "1234", Number: "567890"}, nameservice.People{Name: "Ivan"})
+
+	c := nameservice.New(srv.URL, nameservice.Options{Timeout: time.Second})
+
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	require.NoError(t, c.Enrich(ctx, &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}))
+
+	traceparent := srv.LastHeader().Get("traceparent")
+	require.NotEmpty(t, traceparent)
+	require.Contains(t, traceparent, span.SpanContext().TraceID().String())
+}
+
+func TestEnrich_SpansHidePassport(t *testing.T) {
+	srv := nameservicetest.NewServer(t)
+	srv.Add(models.Passport{Series: "1234", Number: "567890"}, nameservice.People{Name: "Ivan"})
+	srv.FailNext(1)
+
+	c := nameservice.New(srv.URL, nameservice.Options{Timeout: time.Second, Retries: 1})
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, c.Enrich(ctx, user))
+	require.Equal(t, "Ivan", user.Name)
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3) // the request and two attempts
+	for _, s := range spans {
+		texts := []string{s.Name(), s.Status().Description}
+		for _, attr := range s.Attributes() {
+			texts = append(texts, attr.Value.Emit())
+		}
+		for _, event := range s.Events() {
+			for _, attr := range event.Attributes {
+				texts = append(texts, attr.Value.Emit())
+			}
+		}
+		for _, text := range texts {
+			// the series alone may be part of the server port
+			require.NotContains(t, text, "passportSerie", s.Name())
+			require.NotContains(t, text, "567890", s.Name())
+		}
+	}
+}
+
+func TestPing(t *testing.T) {
+	srv := nameservicetest.NewServer(t)
+	c := nameservice.New(srv.URL, nameservice.Options{Timeout: time.Second, Retries: 3})
+
+	require.NoError(t, c.Ping(context.TODO()))
+
+	srv.FailNext(1)
+	require.ErrorIs(t, c.Ping(context.TODO()), nameservice.ErrUnavailable)
+	require.Equal(t, 2, srv.Requests(), "ping must not retry")
+
+	srv.Close()
+	require.ErrorIs(t, c.Ping(context.TODO()), nameservice.ErrUnavailable)
+}
@@ -0,0 +1,93 @@
+// Package nameservicetest provides an in-process fake of the name service for tests.
+package nameservicetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/nameservice"
+)
+
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	people   map[models.Passport]nameservice.People
+	failures int
+	requests int
+	header   http.Header
+}
+
+// NewServer starts a fake name service, it is closed when the test finishes.
+func NewServer(t testing.TB) *Server {
+	s := &Server{people: make(map[models.Passport]nameservice.People)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Add registers a person returned for the given passport.
+func (s *Server) Add(passport models.Passport, p nameservice.People) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.people[passport] = p
+}
+
+// FailNext makes the next n requests fail with 503.
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+}
+
+// Requests returns the number of handled requests.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// LastHeader returns headers of the last handled request.
+func (s *Server) LastHeader() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.header
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	s.header = r.Header.Clone()
+
+	if r.Method != http.MethodGet || r.URL.Path != "/info" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	serie, number := r.URL.Query().Get("passportSerie"), r.URL.Query().Get("passportNumber")
+	if serie == "" || number == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	p, ok := s.people[models.Passport{Series: serie, Number: number}]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(p)
+}
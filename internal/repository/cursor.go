@@ -0,0 +1,16 @@
+package repository
+
+import "github.com/Nicholas2012/time-tracker/internal/models"
+
+// taskSortKey is an SQL expression tasks are sorted by and its type to cast cursor values to.
+type taskSortKey struct {
+	expr string
+	typ  string
+}
+
+var taskSortKeys = map[models.TaskSort]taskSortKey{
+	models.TaskSortID:      {expr: "t.id", typ: "INT"},
+	models.TaskSortSince:   {expr: "t.start_time", typ: "TIMESTAMPTZ"},
+	models.TaskSortUntil:   {expr: "COALESCE(t.end_time, 'infinity')", typ: "TIMESTAMPTZ"},
+	models.TaskSortMinutes: {expr: "t.minutes", typ: "INT"},
+}
@@ -0,0 +1,123 @@
+// Package cursor encodes positions in sorted lists of tasks and audit entries for keyset pagination shared by storage backends.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// Infinity is the until value of running tasks, they go after the ended ones.
+const Infinity = "infinity"
+
+// Task is a position in a sorted list of tasks: the sort value of the last task on a page and its ID.
+type Task struct {
+	Sort  models.TaskSort `json:"s"`
+	Value string          `json:"v"`
+	ID    int             `json:"i"`
+}
+
+// Encode returns the cursor pointing after the task in the list sorted by the field.
+func Encode(sort models.TaskSort, task *models.Task) string {
+	c := Task{Sort: sort, ID: task.ID}
+
+	switch sort {
+	case models.TaskSortID:
+		c.Value = strconv.Itoa(task.ID)
+	case models.TaskSortSince:
+		c.Value = task.Since.UTC().Format(time.RFC3339Nano)
+	case models.TaskSortUntil:
+		c.Value = Infinity
+		if !task.Until.IsZero() {
+			c.Value = task.Until.UTC().Format(time.RFC3339Nano)
+		}
+	case models.TaskSortMinutes:
+		c.Value = strconv.Itoa(task.Minutes)
+	}
+
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode returns models.ErrInvalidCursor if the cursor is malformed or was issued for another sort.
+func Decode(s string, sort models.TaskSort) (Task, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Task{}, models.ErrInvalidCursor
+	}
+
+	var c Task
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sort {
+		return Task{}, models.ErrInvalidCursor
+	}
+	if _, err := c.Key(); err != nil {
+		return Task{}, models.ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// Key returns the sort value of the cursor as it is returned by Key function for tasks.
+func (c Task) Key() (int64, error) {
+	switch c.Sort {
+	case models.TaskSortSince, models.TaskSortUntil:
+		if c.Value == Infinity {
+			return math.MaxInt64, nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return 0, err
+		}
+		return t.UnixMicro(), nil
+	default:
+		return strconv.ParseInt(c.Value, 10, 64)
+	}
+}
+
+// Key returns the value the task is sorted by: IDs and minutes as is, times as unix microseconds.
+// Running tasks have the maximum until value.
+func Key(sort models.TaskSort, task *models.Task) int64 {
+	switch sort {
+	case models.TaskSortSince:
+		return task.Since.UnixMicro()
+	case models.TaskSortUntil:
+		if task.Until.IsZero() {
+			return math.MaxInt64
+		}
+		return task.Until.UnixMicro()
+	case models.TaskSortMinutes:
+		return int64(task.Minutes)
+	default:
+		return int64(task.ID)
+	}
+}
+
+// Audit is a position in the audit log: the ID of the last entry on a page, entries go from the newest.
+type Audit struct {
+	ID int `json:"a"`
+}
+
+// EncodeAudit returns the cursor pointing after the audit entry.
+func EncodeAudit(entry *models.AuditEntry) string {
+	b, _ := json.Marshal(Audit{ID: entry.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeAudit returns models.ErrInvalidCursor if the cursor is malformed or was issued for another list.
+func DecodeAudit(s string) (Audit, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Audit{}, models.ErrInvalidCursor
+	}
+
+	var c Audit
+	if err := json.Unmarshal(b, &c); err != nil || c.ID <= 0 {
+		return Audit{}, models.ErrInvalidCursor
+	}
+
+	return c, nil
+}
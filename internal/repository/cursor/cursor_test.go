@@ -0,0 +1,49 @@
+package cursor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	since := time.Date(2024, 7, 10, 12, 0, 0, 123456000, time.FixedZone("MSK", 3*60*60))
+	task := &models.Task{ID: 7, Since: since, Minutes: 15}
+
+	for sort, key := range map[models.TaskSort]int64{
+		models.TaskSortID:      7,
+		models.TaskSortSince:   since.UnixMicro(),
+		models.TaskSortUntil:   math.MaxInt64,
+		models.TaskSortMinutes: 15,
+	} {
+		c, err := Decode(Encode(sort, task), sort)
+		require.NoError(t, err)
+		require.Equal(t, 7, c.ID)
+
+		got, err := c.Key()
+		require.NoError(t, err)
+		require.Equal(t, key, got)
+		require.Equal(t, key, Key(sort, task))
+	}
+
+	_, err := Decode(Encode(models.TaskSortID, task), models.TaskSortSince)
+	require.ErrorIs(t, err, models.ErrInvalidCursor)
+
+	_, err = Decode("garbage", models.TaskSortID)
+	require.ErrorIs(t, err, models.ErrInvalidCursor)
+}
+
+func TestAuditCursor(t *testing.T) {
+	c, err := DecodeAudit(EncodeAudit(&models.AuditEntry{ID: 42}))
+	require.NoError(t, err)
+	require.Equal(t, 42, c.ID)
+
+	_, err = DecodeAudit(Encode(models.TaskSortID, &models.Task{ID: 7}))
+	require.ErrorIs(t, err, models.ErrInvalidCursor)
+
+	_, err = DecodeAudit("garbage")
+	require.ErrorIs(t, err, models.ErrInvalidCursor)
+}
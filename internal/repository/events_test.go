@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/encryption/encryptiontest"
+	"github.com/Nicholas2012/time-tracker/internal/events"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListen checks that changes are fanned out to every instance of the service through LISTEN/NOTIFY.
+func TestListen(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), models.DefaultOrgID)
+	admin, db, dsn := setupDB(t)
+	repo := New(db, encryptiontest.Keyring(t))
+
+	listenCtx, cancel := context.WithCancel(context.Background())
+	listened := make(chan struct{}, 2)
+	t.Cleanup(func() {
+		cancel()
+		for range 2 {
+			<-listened
+		}
+	})
+
+	// brokers of two instances
+	var subs []*events.Subscription
+	for range 2 {
+		b := events.NewBroker(repo)
+		sub, err := b.Subscribe(ctx, 0, func(*models.Event) bool { return true })
+		require.NoError(t, err)
+		subs = append(subs, sub)
+
+		go func() {
+			events.Listen(listenCtx, dsn, b)
+			listened <- struct{}{}
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		var n int
+		require.NoError(t, admin.QueryRow(`SELECT count(*) FROM pg_stat_activity WHERE query LIKE 'LISTEN %'`).Scan(&n))
+		return n == 2
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// changes of other organizations are not delivered
+	acme := &models.Org{Name: "Acme"}
+	require.NoError(t, repo.CreateOrg(ctx, acme))
+	require.NoError(t, repo.CreateUser(tenant.WithOrg(ctx, acme.ID), &models.User{Passport: models.Passport{Series: "1234", Number: "000000"}}))
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	for _, sub := range subs {
+		select {
+		case e := <-sub.Events():
+			require.Equal(t, models.EventUserCreated, e.Type)
+			require.Equal(t, user.ID, e.UserID)
+		case <-time.After(5 * time.Second):
+			t.Fatal("event is not delivered")
+		}
+	}
+}
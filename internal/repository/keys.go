@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+// CreateAPIKey stores the key of the user in the organization of the context.
+func (r *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+	key.OrgID = orgID
+
+	query := `INSERT INTO api_keys (user_id, name, prefix, hash, org_id) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+
+	row := r.db.QueryRowContext(ctx, query, key.UserID, key.Name, key.Prefix, key.Hash, key.OrgID)
+	if err := row.Scan(&key.ID, &key.CreatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetAPIKeyByHash returns the active key with the given hash. Keys of all organizations are searched,
+// the organization of the key is the one its requests act within.
+func (r *Repository) GetAPIKeyByHash(ctx context.Context, hash []byte) (*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE hash = $1 AND revoked_at IS NULL`
+
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 AND org_id = $2 ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "api_keys")
+		}
+	}()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes the active key of the user, sql.ErrNoRows is returned if there is no such key.
+func (r *Repository) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND org_id = $3 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID, orgID)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(result)
+}
+
+// apiKeyColumns are selected by scanAPIKey. Keys are not restricted by row-level security
+// as they are looked up before the organization is known, queries filter them.
+const apiKeyColumns = `id, user_id, org_id, name, prefix, hash, created_at, revoked_at`
+
+func scanAPIKey(s scanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var revokedAt sql.NullTime
+
+	if err := s.Scan(&key.ID, &key.UserID, &key.OrgID, &key.Name, &key.Prefix, &key.Hash, &key.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	key.RevokedAt = revokedAt.Time
+
+	return &key, nil
+}
+
+// SetFeedToken replaces the calendar feed token of the user, nil hash removes it.
+// sql.ErrNoRows is returned if there is no such user.
+func (r *Repository) SetFeedToken(ctx context.Context, userID int, hash []byte) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	query := `UPDATE users SET feed_token_hash = $1 WHERE id = $2 AND org_id = $3`
+
+	result, err := tx.ExecContext(ctx, query, hash, userID, orgID)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetFeedToken returns hash of the calendar feed token of the user,
+// sql.ErrNoRows is returned if there is no such user or the user has no token.
+func (r *Repository) GetFeedToken(ctx context.Context, userID int) ([]byte, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT feed_token_hash FROM users WHERE id = $1 AND org_id = $2 AND feed_token_hash IS NOT NULL`
+
+	var hash []byte
+	if err := tx.QueryRowContext(ctx, query, userID, orgID).Scan(&hash); err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"hash/fnv"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+)
+
+// WithLock runs fn holding the session advisory lock of the name, so only one instance of the service runs it at a time.
+// It returns false without running fn if another session holds the lock.
+func (r *Repository) WithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	// the lock belongs to the session, so it's taken and released on the same connection
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	key := lockKey(name)
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil {
+		return false, err
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, key); err != nil {
+			logging.FromContext(ctx).Warn("Failed to release advisory lock, closing the connection", "lock", name, "error", err)
+			// the lock is held until the session ends, so the connection must not return to the pool
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	return true, fn(ctx)
+}
+
+// lockKey maps the lock name to a key of PostgreSQL advisory locks.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/repository/cursor"
+)
+
+// ListAuditEntries returns a page of the audit log matching the filter, the newest entries first.
+func (r *Repository) ListAuditEntries(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeID := 0
+	if filter.Cursor != "" {
+		c, err := cursor.DecodeAudit(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		beforeID = c.ID
+	}
+
+	log := &models.AuditLog{}
+	for _, e := range s.auditLog {
+		switch {
+		case filter.Entity != "" && e.Entity != filter.Entity,
+			filter.EntityID != 0 && e.EntityID != filter.EntityID,
+			filter.ActorID != 0 && e.ActorID != filter.ActorID,
+			!filter.From.IsZero() && e.CreatedAt.Before(filter.From),
+			!filter.To.IsZero() && !e.CreatedAt.Before(filter.To),
+			beforeID != 0 && e.ID >= beforeID:
+			continue
+		}
+		log.Entries = append(log.Entries, e)
+	}
+	sort.Slice(log.Entries, func(i, j int) bool { return log.Entries[i].ID > log.Entries[j].ID })
+
+	if filter.Limit > 0 && len(log.Entries) > filter.Limit {
+		log.Entries = log.Entries[:filter.Limit]
+		log.NextCursor = cursor.EncodeAudit(&log.Entries[filter.Limit-1])
+	}
+
+	return log, nil
+}
+
+// ListAuditEntriesAfter returns up to limit entries with IDs greater than afterID, the oldest first.
+func (r *Repository) ListAuditEntriesAfter(ctx context.Context, afterID, limit int) ([]models.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// entries are appended in the order of IDs
+	var entries []models.AuditEntry
+	for _, e := range s.auditLog {
+		if len(entries) == limit {
+			break
+		}
+		if e.ID > afterID {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
+
+// appendAudit appends the entry returned by audit.UserEntry or audit.TaskEntry, it's called holding the write lock.
+// Snapshots of models always marshal, so changes made before the entry is appended need no rollback.
+func (s *orgStore) appendAudit(entry *models.AuditEntry, err error) error {
+	if err != nil {
+		return fmt.Errorf("audit entry: %w", err)
+	}
+
+	s.auditSeq++
+	entry.ID = s.auditSeq
+	s.auditLog = append(s.auditLog, *entry)
+
+	return nil
+}
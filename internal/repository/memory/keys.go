@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// CreateAPIKey stores the key of the user in the organization of the context.
+func (r *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.users[key.UserID]; !ok {
+		return errNoReference
+	}
+	// hashes are unique across organizations, as keys are looked up by them before the organization is known
+	for _, other := range r.orgs {
+		for _, k := range other.keys {
+			if bytes.Equal(k.Hash, key.Hash) {
+				return models.ErrDuplicate
+			}
+		}
+	}
+
+	s.keySeq++
+	key.ID = s.keySeq
+	key.OrgID = s.org.ID
+	key.CreatedAt = time.Now()
+
+	stored := *key
+	stored.Hash = bytes.Clone(key.Hash)
+	s.keys[key.ID] = stored
+
+	return nil
+}
+
+// GetAPIKeyByHash returns the active key with the given hash. Keys of all organizations are searched,
+// the organization of the key is the one its requests act within.
+func (r *Repository) GetAPIKeyByHash(_ context.Context, hash []byte) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, s := range r.orgs {
+		for _, key := range s.keys {
+			if key.RevokedAt.IsZero() && bytes.Equal(key.Hash, hash) {
+				return &key, nil
+			}
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []models.APIKey
+	for _, key := range s.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes the active key of the user, sql.ErrNoRows is returned if there is no such key.
+func (r *Repository) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, ok := s.keys[id]
+	if !ok || key.UserID != userID || !key.RevokedAt.IsZero() {
+		return sql.ErrNoRows
+	}
+
+	key.RevokedAt = time.Now()
+	s.keys[id] = key
+
+	return nil
+}
+
+// SetFeedToken replaces the calendar feed token of the user, nil hash removes it.
+// sql.ErrNoRows is returned if there is no such user.
+func (r *Repository) SetFeedToken(ctx context.Context, userID int, hash []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	u, ok := s.users[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	u.feedTokenHash = bytes.Clone(hash)
+	return nil
+}
+
+// GetFeedToken returns hash of the calendar feed token of the user,
+// sql.ErrNoRows is returned if there is no such user or the user has no token.
+func (r *Repository) GetFeedToken(ctx context.Context, userID int) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u, ok := s.users[userID]
+	if !ok || u.feedTokenHash == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return bytes.Clone(u.feedTokenHash), nil
+}
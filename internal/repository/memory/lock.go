@@ -0,0 +1,23 @@
+package memory
+
+import "context"
+
+// WithLock runs fn holding the lock of the name, it returns false without running fn if the lock is held.
+// Locks only exclude callers sharing the repository, as its data is not shared between processes anyway.
+func (r *Repository) WithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	r.mu.Lock()
+	if r.locks[name] {
+		r.mu.Unlock()
+		return false, nil
+	}
+	r.locks[name] = true
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.locks, name)
+		r.mu.Unlock()
+	}()
+
+	return true, fn(ctx)
+}
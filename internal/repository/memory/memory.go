@@ -0,0 +1,357 @@
+// Package memory is a thread-safe in-memory implementation of usecase.Repository.
+// Data is lost on exit, it's meant for local development and tests.
+// Passports are not encrypted as nothing is stored at rest.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Nicholas2012/time-tracker/internal/audit"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+// errNoReference is returned when an entity refers to one which doesn't exist, as a foreign key violation in SQL.
+var errNoReference = errors.New("referenced entity does not exist")
+
+type user struct {
+	models.User
+	feedTokenHash []byte
+}
+
+type Repository struct {
+	mu sync.RWMutex
+
+	ids   *ids
+	orgs  map[int]*orgStore
+	locks map[string]bool // names of held locks
+}
+
+// ids are last IDs of entities, they are shared by organizations as sequences in SQL and never reused.
+type ids struct {
+	orgSeq, userSeq, taskSeq, projectSeq, tagSeq, teamSeq, keySeq, auditSeq int
+}
+
+// orgStore holds data of an organization, calls only see the store of the organization of their context.
+type orgStore struct {
+	*ids
+	org models.Org
+
+	users    map[int]*user
+	tasks    map[int]*models.Task // tags of stored tasks keep only IDs
+	projects map[int]models.Project
+	tags     map[int]models.Tag
+	teams    map[int]models.Team // member IDs are not shared with callers
+	keys     map[int]models.APIKey
+	auditLog []models.AuditEntry
+}
+
+// New returns an empty repository with the default organization.
+func New() *Repository {
+	r := &Repository{
+		ids:   &ids{},
+		orgs:  make(map[int]*orgStore),
+		locks: make(map[string]bool),
+	}
+	r.ids.orgSeq = models.DefaultOrgID
+	r.addOrg(models.Org{ID: models.DefaultOrgID, Name: "Default"})
+
+	return r
+}
+
+func (r *Repository) addOrg(org models.Org) {
+	r.orgs[org.ID] = &orgStore{
+		ids:      r.ids,
+		org:      org,
+		users:    make(map[int]*user),
+		tasks:    make(map[int]*models.Task),
+		projects: make(map[int]models.Project),
+		tags:     make(map[int]models.Tag),
+		teams:    make(map[int]models.Team),
+		keys:     make(map[int]models.APIKey),
+	}
+}
+
+// store returns the store of the organization of the context, it's called holding the lock.
+func (r *Repository) store(ctx context.Context) (*orgStore, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := r.orgs[orgID]
+	if !ok {
+		return nil, errNoReference
+	}
+	return s, nil
+}
+
+func (r *Repository) CreateUser(ctx context.Context, u *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if u.Role == "" {
+		u.Role = models.RoleEmployee
+	}
+	if u.EnrichStatus == "" {
+		u.EnrichStatus = models.EnrichDone
+	}
+	if u.ManagerID != 0 && s.users[u.ManagerID] == nil {
+		return errNoReference
+	}
+	if s.userWithPassport(u.Passport) != 0 {
+		return models.ErrDuplicate
+	}
+
+	s.userSeq++
+	u.ID = s.userSeq
+	u.EnrichAttempts = 0
+	s.users[u.ID] = &user{User: *u}
+
+	return s.appendAudit(audit.UserEntry(ctx, models.AuditCreate, nil, u))
+}
+
+func (r *Repository) GetUser(ctx context.Context, id int) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u, ok := s.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	copied := u.User
+	return &copied, nil
+}
+
+func (r *Repository) GetUserByPassport(ctx context.Context, passport models.Passport) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id := s.userWithPassport(passport)
+	if id == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	copied := s.users[id].User
+	return &copied, nil
+}
+
+// userWithPassport returns ID of the user with the passport, zero if there is none.
+func (s *orgStore) userWithPassport(passport models.Passport) int {
+	for id, u := range s.users {
+		if u.Passport == passport {
+			return id
+		}
+	}
+	return 0
+}
+
+func (r *Repository) UpdateUser(ctx context.Context, u *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	stored, ok := s.users[u.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if u.ManagerID != 0 && s.users[u.ManagerID] == nil {
+		return errNoReference
+	}
+	if id := s.userWithPassport(u.Passport); id != 0 && id != u.ID {
+		return models.ErrDuplicate
+	}
+
+	before := stored.User
+	stored.User = *u
+	if u.EnrichStatus == "" {
+		stored.EnrichStatus = before.EnrichStatus
+	}
+	stored.EnrichAttempts = before.EnrichAttempts
+
+	return s.appendAudit(audit.UserEntry(ctx, models.AuditUpdate, &before, u))
+}
+
+// DeleteUser deletes the user with tasks and keys, members of the team are left without a manager.
+func (r *Repository) DeleteUser(ctx context.Context, u *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	stored, ok := s.users[u.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	delete(s.users, u.ID)
+
+	for _, other := range s.users {
+		if other.ManagerID == u.ID {
+			other.ManagerID = 0
+		}
+	}
+	// tasks are deleted with the user, each one is recorded in order of IDs
+	var taskIDs []int
+	for id, task := range s.tasks {
+		if task.UserID == u.ID {
+			taskIDs = append(taskIDs, id)
+		}
+	}
+	slices.Sort(taskIDs)
+	for _, id := range taskIDs {
+		if err := s.appendAudit(audit.TaskEntry(ctx, models.AuditDelete, s.task(s.tasks[id]), nil)); err != nil {
+			return err
+		}
+		delete(s.tasks, id)
+	}
+	for id, key := range s.keys {
+		if key.UserID == u.ID {
+			delete(s.keys, id)
+		}
+	}
+	for id, team := range s.teams {
+		if team.ManagerID == u.ID {
+			team.ManagerID = 0
+		}
+		team.MemberIDs = slices.DeleteFunc(team.MemberIDs, func(memberID int) bool { return memberID == u.ID })
+		s.teams[id] = team
+	}
+
+	return s.appendAudit(audit.UserEntry(ctx, models.AuditDelete, &stored.User, nil))
+}
+
+func (r *Repository) ListUsers(ctx context.Context, opts models.UserListOpts) (*models.UserList, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	contains := func(s, substr string) bool {
+		return substr == "" || strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+	}
+
+	var users []models.User
+	for _, u := range s.users {
+		switch {
+		case !contains(u.Name+" "+u.Surname+" "+u.Patronymic, opts.Name),
+			!contains(u.Name, opts.FirstName),
+			!contains(u.Surname, opts.Surname),
+			!contains(u.Patronymic, opts.Patronymic),
+			!contains(u.Address, opts.Address),
+			!opts.Passport.IsZero() && u.Passport != opts.Passport:
+			continue
+		}
+		users = append(users, u.User)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	count := len(users)
+	list := &models.UserList{
+		Users: make([]models.User, 0, opts.Limit),
+		Count: count,
+		Pages: count / opts.Limit,
+		Page:  opts.Page,
+	}
+
+	offset := (opts.Page - 1) * opts.Limit
+	if offset < 0 {
+		offset = 0
+		list.Page = 1
+	}
+	if count%opts.Limit > 0 {
+		list.Pages++
+	}
+	if list.Pages == 0 {
+		list.Page = 0
+		return list, nil
+	}
+
+	if offset < count {
+		list.Users = append(list.Users, users[offset:min(offset+opts.Limit, count)]...)
+	}
+
+	return list, nil
+}
+
+// ListPendingUsers returns users waiting for data from the name service, the ones tried less often first.
+func (r *Repository) ListPendingUsers(ctx context.Context, limit int) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	for _, u := range s.users {
+		if u.EnrichStatus == models.EnrichPending {
+			users = append(users, u.User)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].EnrichAttempts != users[j].EnrichAttempts {
+			return users[i].EnrichAttempts < users[j].EnrichAttempts
+		}
+		return users[i].ID < users[j].ID
+	})
+
+	if len(users) > limit {
+		users = users[:limit]
+	}
+
+	return users, nil
+}
+
+// FailEnrichment counts a failed attempt to fill the user from the name service and sets the status,
+// pending users are retried. sql.ErrNoRows is returned if there is no such user.
+func (r *Repository) FailEnrichment(ctx context.Context, id int, status models.EnrichStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	u, ok := s.users[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	u.EnrichStatus = status
+	u.EnrichAttempts++
+	return nil
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/repository/repositorytest"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) usecase.Repository {
+		return New()
+	})
+}
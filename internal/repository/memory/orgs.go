@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+func (r *Repository) CreateOrg(_ context.Context, org *models.Org) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.orgs {
+		if s.org.Name == org.Name {
+			return models.ErrDuplicate
+		}
+	}
+
+	r.ids.orgSeq++
+	org.ID = r.ids.orgSeq
+	r.addOrg(*org)
+
+	return nil
+}
+
+func (r *Repository) GetOrg(_ context.Context, id int) (*models.Org, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.orgs[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	org := s.org
+	return &org, nil
+}
+
+// ListOrgs returns all organizations ordered by ID.
+func (r *Repository) ListOrgs(_ context.Context) ([]models.Org, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var orgs []models.Org
+	for _, s := range r.orgs {
+		orgs = append(orgs, s.org)
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID < orgs[j].ID })
+
+	return orgs, nil
+}
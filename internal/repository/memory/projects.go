@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+func (r *Repository) CreateProject(ctx context.Context, project *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.projectNamed(project.Name) != 0 {
+		return models.ErrDuplicate
+	}
+
+	s.projectSeq++
+	project.ID = s.projectSeq
+	s.projects[project.ID] = *project
+
+	return nil
+}
+
+func (r *Repository) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	project, ok := s.projects[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &project, nil
+}
+
+func (r *Repository) ListProjects(ctx context.Context) ([]models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []models.Project
+	for _, project := range s.projects {
+		projects = append(projects, project)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+
+	return projects, nil
+}
+
+func (r *Repository) UpdateProject(ctx context.Context, project *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.projects[project.ID]; !ok {
+		return sql.ErrNoRows
+	}
+	if id := s.projectNamed(project.Name); id != 0 && id != project.ID {
+		return models.ErrDuplicate
+	}
+
+	s.projects[project.ID] = *project
+	return nil
+}
+
+// DeleteProject deletes the project, its tasks are left without a project.
+func (r *Repository) DeleteProject(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.projects[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(s.projects, id)
+
+	for _, task := range s.tasks {
+		if task.ProjectID == id {
+			task.ProjectID = 0
+		}
+	}
+
+	return nil
+}
+
+// projectNamed returns ID of the project with the name, zero if there is none.
+func (s *orgStore) projectNamed(name string) int {
+	for id, project := range s.projects {
+		if project.Name == name {
+			return id
+		}
+	}
+	return 0
+}
+
+func (r *Repository) CreateTag(ctx context.Context, tag *models.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.tagNamed(tag.Name) != 0 {
+		return models.ErrDuplicate
+	}
+
+	s.tagSeq++
+	tag.ID = s.tagSeq
+	s.tags[tag.ID] = *tag
+
+	return nil
+}
+
+func (r *Repository) GetTag(ctx context.Context, id int) (*models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, ok := s.tags[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &tag, nil
+}
+
+func (r *Repository) ListTags(ctx context.Context) ([]models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []models.Tag
+	for _, tag := range s.tags {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return tags, nil
+}
+
+func (r *Repository) UpdateTag(ctx context.Context, tag *models.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.tags[tag.ID]; !ok {
+		return sql.ErrNoRows
+	}
+	if id := s.tagNamed(tag.Name); id != 0 && id != tag.ID {
+		return models.ErrDuplicate
+	}
+
+	s.tags[tag.ID] = *tag
+	return nil
+}
+
+// DeleteTag deletes the tag and removes it from tasks.
+func (r *Repository) DeleteTag(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.tags[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(s.tags, id)
+
+	for _, task := range s.tasks {
+		tags := task.Tags[:0]
+		for _, tag := range task.Tags {
+			if tag.ID != id {
+				tags = append(tags, tag)
+			}
+		}
+		task.Tags = tags
+	}
+
+	return nil
+}
+
+// tagNamed returns ID of the tag with the name, zero if there is none.
+func (s *orgStore) tagNamed(name string) int {
+	for id, tag := range s.tags {
+		if tag.Name == name {
+			return id
+		}
+	}
+	return 0
+}
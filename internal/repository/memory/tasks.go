@@ -0,0 +1,517 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/audit"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/repository/cursor"
+)
+
+// CreateTask creates the task, models.ErrDuplicate is returned if the user already has a running task.
+func (r *Repository) CreateTask(ctx context.Context, task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.insertTask(task); err != nil {
+		return err
+	}
+
+	return s.appendAudit(audit.TaskEntry(ctx, models.AuditCreate, nil, task))
+}
+
+// SwitchTask atomically ends the running task of the user and creates the new one.
+// The running task is ended at the start time of the new task and returned, nil if there was none.
+func (r *Repository) SwitchTask(ctx context.Context, task *models.Task) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := s.users[task.UserID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	var running *models.Task
+	if stored := s.unfinishedTask(task.UserID); stored != nil {
+		before := s.task(stored)
+		stored.End(task.Since)
+		running = s.task(stored)
+
+		if err := s.appendAudit(audit.TaskEntry(ctx, models.AuditUpdate, before, running)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.insertTask(task); err != nil {
+		return nil, err
+	}
+
+	if err := s.appendAudit(audit.TaskEntry(ctx, models.AuditCreate, nil, task)); err != nil {
+		return nil, err
+	}
+
+	return running, nil
+}
+
+func (r *Repository) GetTask(ctx context.Context, userID, id int) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, ok := s.tasks[id]
+	if !ok || stored.UserID != userID {
+		return nil, sql.ErrNoRows
+	}
+
+	return s.task(stored), nil
+}
+
+// OverlappingTask returns ID of a task of the user other than excludeID overlapping [since, until).
+// Running tasks last up to now. sql.ErrNoRows is returned if there is no such task.
+func (r *Repository) OverlappingTask(ctx context.Context, userID, excludeID int, since, until, now time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var found *models.Task
+	for _, t := range s.tasks {
+		if t.UserID != userID || t.ID == excludeID || !t.Since.Before(until) || !orNow(t.Until, now).After(since) {
+			continue
+		}
+		if found == nil || t.Since.Before(found.Since) {
+			found = t
+		}
+	}
+	if found == nil {
+		return 0, sql.ErrNoRows
+	}
+
+	return found.ID, nil
+}
+
+// UpdateTask stores the task if it's still at the revision read, models.ErrStale is returned otherwise.
+func (r *Repository) UpdateTask(ctx context.Context, task *models.Task, read models.TaskRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	stored, ok := s.tasks[task.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	before := s.task(stored)
+	if before.Revision() != read {
+		return models.ErrStale
+	}
+	*stored = *store(task)
+	stored.UserID = before.UserID
+
+	return s.appendAudit(audit.TaskEntry(ctx, models.AuditUpdate, before, s.task(stored)))
+}
+
+// ListTasks returns a page of tasks of the user matching the filter and the total number of such tasks.
+func (r *Repository) ListTasks(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sortBy := opts.Sort
+	if sortBy == "" {
+		sortBy = models.TaskSortID
+	}
+
+	var after *cursor.Task
+	if opts.Cursor != "" {
+		c, err := cursor.Decode(opts.Cursor, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		after = &c
+	}
+
+	var tasks []*models.Task
+	for _, t := range s.tasks {
+		if t.UserID == userID && matches(t, opts.TaskFilter) {
+			tasks = append(tasks, t)
+		}
+	}
+
+	less := func(a, b *models.Task) bool {
+		ka, kb := cursor.Key(sortBy, a), cursor.Key(sortBy, b)
+		if ka != kb {
+			return ka < kb
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if opts.Desc {
+			return less(tasks[j], tasks[i])
+		}
+		return less(tasks[i], tasks[j])
+	})
+
+	list := &models.TaskList{Count: len(tasks), Limit: opts.Limit}
+
+	if after != nil {
+		key, _ := after.Key()
+		rest := tasks[:0:0]
+		for _, t := range tasks {
+			k := cursor.Key(sortBy, t)
+			if (!opts.Desc && (k > key || k == key && t.ID > after.ID)) || (opts.Desc && (k < key || k == key && t.ID < after.ID)) {
+				rest = append(rest, t)
+			}
+		}
+		tasks = rest
+	}
+
+	for _, t := range tasks {
+		if opts.Limit > 0 && len(list.Tasks) == opts.Limit {
+			list.NextCursor = cursor.Encode(sortBy, &list.Tasks[opts.Limit-1])
+			break
+		}
+		list.Tasks = append(list.Tasks, *s.task(t))
+	}
+
+	return list, nil
+}
+
+// TaskEfforts returns time spent on each task of the user within [from, to) sorted by the time descending.
+// Only worked segments are counted, running segments are counted up to now.
+func (r *Repository) TaskEfforts(ctx context.Context, userID int, from, to, now time.Time) ([]models.TaskEffort, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.taskEfforts(userID, from, to, now), nil
+}
+
+func (s *orgStore) taskEfforts(userID int, from, to, now time.Time) []models.TaskEffort {
+	var efforts []models.TaskEffort
+	for _, t := range s.tasks {
+		if t.UserID != userID {
+			continue
+		}
+		if d, ok := worked(t, from, to, now); ok {
+			efforts = append(efforts, models.TaskEffort{TaskID: t.ID, Since: t.Since, Until: t.Until, Duration: d, AutoStopped: t.AutoStopped})
+		}
+	}
+
+	sort.Slice(efforts, func(i, j int) bool {
+		if efforts[i].Duration != efforts[j].Duration {
+			return efforts[i].Duration > efforts[j].Duration
+		}
+		return efforts[i].TaskID < efforts[j].TaskID
+	})
+
+	return efforts
+}
+
+// Timesheet calls fn for each task worked on within [from, to) ordered by user full name and task start.
+// Zero userID selects tasks of all users.
+func (r *Repository) Timesheet(ctx context.Context, userID int, from, to, now time.Time, fn func(*models.TimesheetRow) error) error {
+	r.mu.RLock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		r.mu.RUnlock()
+		return err
+	}
+
+	var rows []models.TimesheetRow
+	for _, t := range s.tasks {
+		if userID != 0 && t.UserID != userID {
+			continue
+		}
+		if d, ok := worked(t, from, to, now); ok {
+			rows = append(rows, models.TimesheetRow{
+				User:        s.users[t.UserID].User,
+				TaskID:      t.ID,
+				Title:       t.Title,
+				Since:       t.Since,
+				Until:       t.Until,
+				Duration:    d,
+				AutoStopped: t.AutoStopped,
+			})
+		}
+	}
+
+	// fn may be slow, so it's called without the lock
+	r.mu.RUnlock()
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		for _, c := range []int{
+			strings.Compare(a.User.Surname, b.User.Surname),
+			strings.Compare(a.User.Name, b.User.Name),
+			strings.Compare(a.User.Patronymic, b.User.Patronymic),
+			a.User.ID - b.User.ID,
+			a.Since.Compare(b.Since),
+		} {
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return a.TaskID < b.TaskID
+	})
+
+	for i := range rows {
+		if err := fn(&rows[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CountRunningTasks returns the number of running tasks of all users, paused tasks are not counted.
+func (r *Repository) CountRunningTasks(ctx context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, t := range s.tasks {
+		if t.State() == models.TaskRunning {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// ListRunningTasks returns running tasks of all users started before the time,
+// ordered by the start of their current segment.
+func (r *Repository) ListRunningTasks(ctx context.Context, startedBefore time.Time) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []models.Task
+	for _, t := range s.tasks {
+		if t.State() == models.TaskRunning && t.Since.Before(startedBefore) {
+			tasks = append(tasks, *s.task(t))
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := lastSegment(&tasks[i]).Since, lastSegment(&tasks[j]).Since
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	return tasks, nil
+}
+
+// AutoStopTask stores the end of the task made by the auto-stop policy and marks it auto-stopped.
+// sql.ErrNoRows is returned if the task is not running in the same segment anymore, e.g. the user has ended it meanwhile.
+func (r *Repository) AutoStopTask(ctx context.Context, task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	stored, ok := s.tasks[task.ID]
+	if !ok || stored.State() != models.TaskRunning || len(task.Segments) == 0 {
+		return sql.ErrNoRows
+	}
+
+	stopped := lastSegment(task)
+	open := lastSegment(stored)
+	if !open.Since.Equal(stopped.Since) {
+		return sql.ErrNoRows
+	}
+
+	before := s.task(stored)
+	open.Until = stopped.Until
+	stored.Until = task.Until
+	stored.Minutes = task.Minutes
+	stored.AutoStopped = true
+
+	return s.appendAudit(audit.TaskEntry(ctx, models.AuditUpdate, before, s.task(stored)))
+}
+
+// insertTask stores the task, models.ErrDuplicate is returned if the user already has a running task.
+func (s *orgStore) insertTask(task *models.Task) error {
+	if _, ok := s.users[task.UserID]; !ok {
+		return errNoReference
+	}
+	if task.ProjectID != 0 {
+		if _, ok := s.projects[task.ProjectID]; !ok {
+			return errNoReference
+		}
+	}
+	if task.Until.IsZero() && s.unfinishedTask(task.UserID) != nil {
+		return models.ErrDuplicate
+	}
+
+	s.taskSeq++
+	task.ID = s.taskSeq
+	s.tasks[task.ID] = store(task)
+
+	return nil
+}
+
+// unfinishedTask returns the stored task of the user which is running or paused, nil if there is none.
+func (s *orgStore) unfinishedTask(userID int) *models.Task {
+	for _, t := range s.tasks {
+		if t.UserID == userID && t.Until.IsZero() {
+			return t
+		}
+	}
+	return nil
+}
+
+// task returns a copy of the stored task with tags resolved and ordered by name.
+func (s *orgStore) task(stored *models.Task) *models.Task {
+	task := *stored
+	task.Segments = append([]models.TaskSegment{}, stored.Segments...)
+	task.Tags = []models.Tag{}
+	for _, tag := range stored.Tags {
+		if t, ok := s.tags[tag.ID]; ok {
+			task.Tags = append(task.Tags, t)
+		}
+	}
+	sort.Slice(task.Tags, func(i, j int) bool { return task.Tags[i].Name < task.Tags[j].Name })
+
+	if task.ProjectID != 0 {
+		if _, ok := s.projects[task.ProjectID]; !ok {
+			task.ProjectID = 0
+		}
+	}
+
+	return &task
+}
+
+// store returns a copy of the task to keep, a task without segments is worked in a single one.
+func store(task *models.Task) *models.Task {
+	stored := *task
+	stored.Segments = append([]models.TaskSegment{}, task.Segments...)
+	if len(stored.Segments) == 0 {
+		stored.Segments = []models.TaskSegment{{Since: task.Since, Until: task.Until}}
+	}
+	sort.Slice(stored.Segments, func(i, j int) bool { return stored.Segments[i].Since.Before(stored.Segments[j].Since) })
+
+	stored.Tags = make([]models.Tag, 0, len(task.Tags))
+	for _, tag := range task.Tags {
+		stored.Tags = append(stored.Tags, models.Tag{ID: tag.ID})
+	}
+
+	return &stored
+}
+
+// matches reports whether the task matches the filter.
+func matches(t *models.Task, f models.TaskFilter) bool {
+	switch {
+	case f.Title != "" && !strings.Contains(strings.ToLower(t.Title), strings.ToLower(f.Title)),
+		f.ProjectID != 0 && t.ProjectID != f.ProjectID,
+		!f.From.IsZero() && !t.Until.IsZero() && !t.Until.After(f.From),
+		!f.To.IsZero() && !t.Since.Before(f.To),
+		f.State != "" && t.State() != f.State:
+		return false
+	}
+
+	if f.TagID != 0 {
+		for _, tag := range t.Tags {
+			if tag.ID == f.TagID {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// worked returns time spent on the task within [from, to), ok is false if no segment overlaps the period.
+func worked(t *models.Task, from, to, now time.Time) (time.Duration, bool) {
+	var (
+		d  time.Duration
+		ok bool
+	)
+	for _, s := range t.Segments {
+		until := orNow(s.Until, now)
+		if !s.Since.Before(to) || !until.After(from) {
+			continue
+		}
+
+		ok = true
+		d += minTime(until, to).Sub(maxTime(s.Since, from))
+	}
+
+	// time is accounted in whole seconds as in SQL backends
+	return time.Duration(math.Round(d.Seconds())) * time.Second, ok
+}
+
+// lastSegment returns the latest segment of the task, stored tasks always have one.
+func lastSegment(t *models.Task) *models.TaskSegment {
+	return &t.Segments[len(t.Segments)-1]
+}
+
+func orNow(t, now time.Time) time.Time {
+	if t.IsZero() {
+		return now
+	}
+	return t
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+func (r *Repository) CreateTeam(ctx context.Context, team *models.Team) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.teamNamed(team.Name) != 0 {
+		return models.ErrDuplicate
+	}
+	if err := s.checkTeamUsers(team); err != nil {
+		return err
+	}
+
+	s.teamSeq++
+	team.ID = s.teamSeq
+	s.teams[team.ID] = cloneTeam(*team)
+
+	return nil
+}
+
+func (r *Repository) GetTeam(ctx context.Context, id int) (*models.Team, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	team, ok := s.teams[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	team = cloneTeam(team)
+	return &team, nil
+}
+
+// ListTeams returns all teams ordered by name with their members.
+func (r *Repository) ListTeams(ctx context.Context) ([]models.Team, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var teams []models.Team
+	for _, team := range s.teams {
+		teams = append(teams, cloneTeam(team))
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+
+	return teams, nil
+}
+
+// UpdateTeam updates the team and replaces its members.
+func (r *Repository) UpdateTeam(ctx context.Context, team *models.Team) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.teams[team.ID]; !ok {
+		return sql.ErrNoRows
+	}
+	if id := s.teamNamed(team.Name); id != 0 && id != team.ID {
+		return models.ErrDuplicate
+	}
+	if err := s.checkTeamUsers(team); err != nil {
+		return err
+	}
+
+	s.teams[team.ID] = cloneTeam(*team)
+	return nil
+}
+
+// DeleteTeam deletes the team, its members are kept.
+func (r *Repository) DeleteTeam(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.teams[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(s.teams, id)
+
+	return nil
+}
+
+// TeamEfforts returns time spent by each member of the team on each task within [from, to).
+// Members are sorted by their total time descending, their tasks by time descending; members who
+// worked on nothing in the period are included without tasks. Running segments are counted up to now.
+func (r *Repository) TeamEfforts(ctx context.Context, teamID int, from, to, now time.Time) ([]models.MemberEffort, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, err := r.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []models.MemberEffort
+	for _, userID := range s.teams[teamID].MemberIDs {
+		u := s.users[userID]
+		member := models.MemberEffort{
+			UserID:     userID,
+			Name:       u.Name,
+			Surname:    u.Surname,
+			Patronymic: u.Patronymic,
+			Tasks:      s.taskEfforts(userID, from, to, now),
+		}
+		for _, e := range member.Tasks {
+			member.Total += e.Duration
+		}
+		members = append(members, member)
+	}
+
+	sort.SliceStable(members, func(i, j int) bool { return members[i].Total > members[j].Total })
+
+	return members, nil
+}
+
+// teamNamed returns ID of the team with the name or zero.
+func (s *orgStore) teamNamed(name string) int {
+	for id, team := range s.teams {
+		if team.Name == name {
+			return id
+		}
+	}
+	return 0
+}
+
+// checkTeamUsers returns errNoReference if the manager or a member of the team doesn't exist.
+func (s *orgStore) checkTeamUsers(team *models.Team) error {
+	if team.ManagerID != 0 && s.users[team.ManagerID] == nil {
+		return errNoReference
+	}
+	for _, userID := range team.MemberIDs {
+		if s.users[userID] == nil {
+			return errNoReference
+		}
+	}
+	return nil
+}
+
+// cloneTeam copies member IDs of the team sorted, as they are returned by SQL storages.
+func cloneTeam(team models.Team) models.Team {
+	team.MemberIDs = append([]int(nil), team.MemberIDs...)
+	slices.Sort(team.MemberIDs)
+	return team
+}
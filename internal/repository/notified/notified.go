@@ -0,0 +1,87 @@
+// Package notified decorates a repository of a single instance with publishing of changes to the event broker.
+// PostgreSQL notifies all instances of appended audit entries itself, see events.Listen.
+package notified
+
+import (
+	"context"
+
+	"github.com/Nicholas2012/time-tracker/internal/audit"
+	"github.com/Nicholas2012/time-tracker/internal/events"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+)
+
+var _ usecase.Repository = (*Repository)(nil)
+
+// Repository publishes audit entries of the changes made through the wrapped repository once they are stored,
+// other methods are passed through.
+type Repository struct {
+	usecase.Repository
+	broker *events.Broker
+}
+
+func New(repo usecase.Repository, broker *events.Broker) *Repository {
+	return &Repository{Repository: repo, broker: broker}
+}
+
+// publish publishes the recorded entries unless the change failed and was rolled back.
+func (r *Repository) publish(ctx context.Context, rec *audit.Recorder, err error) {
+	orgID, ok := tenant.OrgFrom(ctx)
+	if err != nil || !ok {
+		return
+	}
+
+	for _, entry := range rec.Entries() {
+		r.broker.Publish(orgID, entry)
+	}
+}
+
+func (r *Repository) CreateUser(ctx context.Context, user *models.User) error {
+	ctx, rec := audit.Record(ctx)
+	err := r.Repository.CreateUser(ctx, user)
+	r.publish(ctx, rec, err)
+	return err
+}
+
+func (r *Repository) UpdateUser(ctx context.Context, user *models.User) error {
+	ctx, rec := audit.Record(ctx)
+	err := r.Repository.UpdateUser(ctx, user)
+	r.publish(ctx, rec, err)
+	return err
+}
+
+func (r *Repository) DeleteUser(ctx context.Context, user *models.User) error {
+	ctx, rec := audit.Record(ctx)
+	err := r.Repository.DeleteUser(ctx, user)
+	r.publish(ctx, rec, err)
+	return err
+}
+
+func (r *Repository) CreateTask(ctx context.Context, task *models.Task) error {
+	ctx, rec := audit.Record(ctx)
+	err := r.Repository.CreateTask(ctx, task)
+	r.publish(ctx, rec, err)
+	return err
+}
+
+func (r *Repository) SwitchTask(ctx context.Context, task *models.Task) (*models.Task, error) {
+	ctx, rec := audit.Record(ctx)
+	stopped, err := r.Repository.SwitchTask(ctx, task)
+	r.publish(ctx, rec, err)
+	return stopped, err
+}
+
+func (r *Repository) UpdateTask(ctx context.Context, task *models.Task, read models.TaskRevision) error {
+	ctx, rec := audit.Record(ctx)
+	err := r.Repository.UpdateTask(ctx, task, read)
+	r.publish(ctx, rec, err)
+	return err
+}
+
+func (r *Repository) AutoStopTask(ctx context.Context, task *models.Task) error {
+	ctx, rec := audit.Record(ctx)
+	err := r.Repository.AutoStopTask(ctx, task)
+	r.publish(ctx, rec, err)
+	return err
+}
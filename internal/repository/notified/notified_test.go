@@ -0,0 +1,56 @@
+package notified
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/events"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/repository/memory"
+	"github.com/Nicholas2012/time-tracker/internal/repository/repositorytest"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) usecase.Repository {
+		repo := memory.New()
+		return New(repo, events.NewBroker(repo))
+	})
+}
+
+func TestPublish(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), models.DefaultOrgID)
+	mem := memory.New()
+	broker := events.NewBroker(mem)
+	repo := New(mem, broker)
+
+	sub, err := broker.Subscribe(ctx, 0, func(*models.Event) bool { return true })
+	require.NoError(t, err)
+	defer sub.Close()
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	running := &models.Task{UserID: user.ID, Title: "Report", Since: time.Now().Add(-time.Hour)}
+	require.NoError(t, repo.CreateTask(ctx, running))
+	_, err = repo.SwitchTask(ctx, &models.Task{UserID: user.ID, Title: "Review", Since: time.Now()})
+	require.NoError(t, err)
+
+	// failed changes publish nothing
+	require.Error(t, repo.CreateTask(ctx, &models.Task{UserID: 404, Title: "Missing", Since: time.Now()}))
+
+	var types []models.EventType
+	for range 4 {
+		e := <-sub.Events()
+		types = append(types, e.Type)
+	}
+	require.Equal(t, []models.EventType{
+		models.EventUserCreated,
+		models.EventTaskStarted,
+		models.EventTaskEnded,
+		models.EventTaskStarted,
+	}, types)
+	require.Empty(t, sub.Events())
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// Organizations themselves are not restricted by row-level security, they are managed by operators of the service.
+
+func (r *Repository) CreateOrg(ctx context.Context, org *models.Org) error {
+	if err := r.db.QueryRowContext(ctx, `INSERT INTO organizations (name) VALUES ($1) RETURNING id`, org.Name).Scan(&org.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetOrg(ctx context.Context, id int) (*models.Org, error) {
+	org := &models.Org{ID: id}
+	if err := r.db.QueryRowContext(ctx, `SELECT name FROM organizations WHERE id = $1`, id).Scan(&org.Name); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+// ListOrgs returns all organizations ordered by ID.
+func (r *Repository) ListOrgs(ctx context.Context) ([]models.Org, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name FROM organizations ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "organizations")
+		}
+	}()
+
+	var orgs []models.Org
+	for rows.Next() {
+		var org models.Org
+		if err := rows.Scan(&org.ID, &org.Name); err != nil {
+			return nil, err
+		}
+
+		orgs = append(orgs, org)
+	}
+
+	return orgs, rows.Err()
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+func (r *Repository) CreateProject(ctx context.Context, project *models.Project) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	query := `INSERT INTO projects (name, description, org_id) VALUES ($1, $2, $3) RETURNING id`
+
+	row := tx.QueryRowContext(ctx, query, project.Name, project.Description, orgID)
+	if err := row.Scan(&project.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT name, description FROM projects WHERE id = $1 AND org_id = $2`
+
+	project := &models.Project{ID: id}
+	if err := tx.QueryRowContext(ctx, query, id, orgID).Scan(&project.Name, &project.Description); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (r *Repository) ListProjects(ctx context.Context) ([]models.Project, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT id, name, description FROM projects WHERE org_id = $1 ORDER BY name`
+
+	rows, err := tx.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "projects")
+		}
+	}()
+
+	var projects []models.Project
+	for rows.Next() {
+		var project models.Project
+		if err := rows.Scan(&project.ID, &project.Name, &project.Description); err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
+}
+
+func (r *Repository) UpdateProject(ctx context.Context, project *models.Project) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	query := `UPDATE projects SET name = $1, description = $2 WHERE id = $3 AND org_id = $4`
+
+	result, err := tx.ExecContext(ctx, query, project.Name, project.Description, project.ID, orgID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) DeleteProject(ctx context.Context, id int) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// checkAffected returns sql.ErrNoRows if the statement changed nothing.
+func checkAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
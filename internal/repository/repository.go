@@ -3,125 +3,250 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log/slog"
+	"slices"
+	"strconv"
+	"time"
 
+	"github.com/Nicholas2012/time-tracker/internal/encryption"
+	"github.com/Nicholas2012/time-tracker/internal/logging"
 	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/repository/cursor"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
 	goqu "github.com/doug-martin/goqu/v9"
+	"github.com/lib/pq"
 )
 
 type Repository struct {
-	db *sql.DB
+	db   *sql.DB
+	keys *encryption.Keyring // encrypts passports
 }
 
-type UserList struct {
-	Users []models.User
-	Count int
-	Pages int
-	Page  int
+func New(db *sql.DB, keys *encryption.Keyring) *Repository {
+	return &Repository{db: db, keys: keys}
 }
 
-type ListOpts struct {
-	Page  int
-	Limit int
-	Name  string // поиск по имени
-}
+func (r *Repository) CreateUser(ctx context.Context, user *models.User) error {
+	if user.Role == "" {
+		user.Role = models.RoleEmployee
+	}
+	if user.EnrichStatus == "" {
+		user.EnrichStatus = models.EnrichDone
+	}
 
-func New(db *sql.DB) *Repository {
-	return &Repository{db: db}
-}
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
 
-func (r *Repository) CreateUser(ctx context.Context, user *models.User) error {
-	query := `INSERT INTO users (name, surname, patronymic, passport_serie, passport_number) 
-		VALUES ($1, $2, $3, $4, $5)
+	encrypted, index, err := r.keys.EncryptPassport(user.Passport)
+	if err != nil {
+		return fmt.Errorf("encrypt passport: %w", err)
+	}
+
+	query := `INSERT INTO users (name, surname, patronymic, address, passport_encrypted, passport_index, passport_key, role, manager_id, org_id, enrich_status) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id`
 
-	row := r.db.QueryRowContext(ctx, query, user.Name, user.Surname, user.Patronymic, user.PassportSerie, user.PassportNumber)
+	row := tx.QueryRowContext(ctx, query, user.Name, user.Surname, user.Patronymic, user.Address, encrypted, index, r.keys.CurrentKeyID(), user.Role, nullInt(user.ManagerID), orgID, user.EnrichStatus)
 	if err := row.Scan(&user.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
 		return err
 	}
 
-	return nil
+	if err := auditUser(ctx, tx, models.AuditCreate, nil, user); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *Repository) GetUser(ctx context.Context, id int) (*models.User, error) {
-	query := `SELECT name, surname, patronymic, passport_serie, passport_number FROM users WHERE id = $1`
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND org_id = $2`
+
+	return r.scanUser(tx.QueryRowContext(ctx, query, id, orgID))
+}
 
-	row := r.db.QueryRowContext(ctx, query, id)
-	user := &models.User{ID: id}
-	if err := row.Scan(&user.Name, &user.Surname, &user.Patronymic, &user.PassportSerie, &user.PassportNumber); err != nil {
+// GetUserByPassport returns the user with the passport.
+func (r *Repository) GetUserByPassport(ctx context.Context, passport models.Passport) (*models.User, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
 		return nil, err
 	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE org_id = $1 AND passport_index = $2`
 
-	return user, nil
+	return r.scanUser(tx.QueryRowContext(ctx, query, orgID, r.keys.PassportIndex(passport)))
 }
 
 func (r *Repository) DeleteUser(ctx context.Context, user *models.User) error {
-	query := `DELETE FROM users WHERE id = $1`
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
 
-	result, err := r.db.ExecContext(ctx, query, user.ID)
+	before, err := r.lockUser(ctx, tx, orgID, user.ID)
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	// tasks are deleted with the user, so their deletion is recorded too
+	tasks, err := lockUserTasks(ctx, tx, orgID, user.ID)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1 AND org_id = $2`, user.ID, orgID); err != nil {
+		return err
 	}
 
-	return nil
+	for _, task := range tasks {
+		if err := auditTask(ctx, tx, models.AuditDelete, task, nil); err != nil {
+			return err
+		}
+	}
+	if err := auditUser(ctx, tx, models.AuditDelete, before, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockUserTasks returns tasks of the user ordered by ID locking them until the end of the transaction.
+func lockUserTasks(ctx context.Context, tx *sql.Tx, orgID, userID int) ([]*models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.user_id = $1 AND t.org_id = $2 ORDER BY t.id FOR UPDATE OF t`
+
+	rows, err := tx.QueryContext(ctx, query, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "users")
+		}
+	}()
+
+	var tasks []*models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
 }
 
 func (r *Repository) UpdateUser(ctx context.Context, user *models.User) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	before, err := r.lockUser(ctx, tx, orgID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	encrypted, index, err := r.keys.EncryptPassport(user.Passport)
+	if err != nil {
+		return fmt.Errorf("encrypt passport: %w", err)
+	}
 
 	query := `UPDATE users
 		SET name = $1,
 		surname = $2,
 		patronymic = $3,
-		passport_serie = $4,
-		passport_number = $5
-		WHERE id = $6`
-
-	result, err := r.db.ExecContext(ctx, query, user.Name, user.Surname, user.Patronymic, user.PassportSerie, user.PassportNumber, user.ID)
+		address = $4,
+		passport_serie = '',
+		passport_number = '',
+		passport_encrypted = $5,
+		passport_index = $6,
+		passport_key = $7,
+		role = $8,
+		manager_id = $9,
+		enrich_status = COALESCE(NULLIF($10, ''), enrich_status)
+		WHERE id = $11 AND org_id = $12`
+
+	_, err = tx.ExecContext(ctx, query, user.Name, user.Surname, user.Patronymic, user.Address, encrypted, index, r.keys.CurrentKeyID(), user.Role, nullInt(user.ManagerID), user.EnrichStatus, user.ID, orgID)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+	if err := auditUser(ctx, tx, models.AuditUpdate, before, user); err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
+	return tx.Commit()
+}
 
-	return nil
+// lockUser returns the user locking it until the end of the transaction.
+func (r *Repository) lockUser(ctx context.Context, tx *sql.Tx, orgID, id int) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND org_id = $2 FOR UPDATE`
+
+	return r.scanUser(tx.QueryRowContext(ctx, query, id, orgID))
 }
 
-func (r *Repository) ListUsers(opts ListOpts) (*UserList, error) {
-	qb := goqu.From("users")
+func (r *Repository) ListUsers(ctx context.Context, opts models.UserListOpts) (*models.UserList, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	qb := goqu.From("users").Where(goqu.C("org_id").Eq(orgID))
 
 	if opts.Name != "" {
 		qb = qb.Where(goqu.L("name || ' ' || surname || ' ' || patronymic ILIKE ?", fmt.Sprint("%", opts.Name, "%")))
 	}
+	if opts.FirstName != "" {
+		qb = qb.Where(goqu.C("name").ILike(fmt.Sprint("%", opts.FirstName, "%")))
+	}
+	if opts.Surname != "" {
+		qb = qb.Where(goqu.C("surname").ILike(fmt.Sprint("%", opts.Surname, "%")))
+	}
+	if opts.Patronymic != "" {
+		qb = qb.Where(goqu.C("patronymic").ILike(fmt.Sprint("%", opts.Patronymic, "%")))
+	}
+	if opts.Address != "" {
+		qb = qb.Where(goqu.C("address").ILike(fmt.Sprint("%", opts.Address, "%")))
+	}
+	if !opts.Passport.IsZero() {
+		// queries are interpolated, bytes are passed as hex to survive it
+		qb = qb.Where(goqu.L("passport_index = decode(?, 'hex')", hex.EncodeToString(r.keys.PassportIndex(opts.Passport))))
+	}
 
 	// get total count
 	countQuery, countArgs, err := qb.Select(goqu.L("COUNT(*)")).ToSQL()
 	if err != nil {
 		return nil, fmt.Errorf("build count query: %w", err)
 	}
-	slog.Debug("list count query", "query", countQuery, "repository", "users")
+	logging.FromContext(ctx).Debug("list count query", "query", countQuery, "repository", "users")
 	var count int
-	if err := r.db.QueryRow(countQuery, countArgs...).Scan(&count); err != nil {
+	if err := tx.QueryRowContext(ctx, countQuery, countArgs...).Scan(&count); err != nil {
 		return nil, fmt.Errorf("count: %w", err)
 	}
 
-	userList := UserList{
+	userList := models.UserList{
 		Users: make([]models.User, 0, opts.Limit),
 		Count: count,
 		Pages: count / opts.Limit,
@@ -132,7 +257,7 @@ func (r *Repository) ListUsers(opts ListOpts) (*UserList, error) {
 	offset := (opts.Page - 1) * opts.Limit
 	if offset < 0 {
 		offset = 0
-		opts.Page = 1
+		userList.Page = 1
 	}
 	if count%opts.Limit > 0 {
 		userList.Pages++
@@ -144,106 +269,674 @@ func (r *Repository) ListUsers(opts ListOpts) (*UserList, error) {
 
 	// get data
 	selectQuery, selectArgs, err := qb.
-		Select("id", "name", "surname", "patronymic", "passport_serie", "passport_number").
+		Select(goqu.L(userColumns)).
+		Order(goqu.C("id").Asc()).
 		Offset(uint(offset)).
 		Limit(uint(opts.Limit)).ToSQL()
 	if err != nil {
 		return nil, fmt.Errorf("build select query: %w", err)
 	}
 
-	slog.Debug("list query", "query", countQuery, "args", countArgs, "repository", "users")
+	logging.FromContext(ctx).Debug("list query", "query", selectQuery, "args", selectArgs, "repository", "users")
 
-	rows, err := r.db.Query(selectQuery, selectArgs...)
+	rows, err := tx.QueryContext(ctx, selectQuery, selectArgs...)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
-			slog.Debug("db rows close", "err", err, "repository", "users")
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "users")
 		}
 	}()
 
 	for rows.Next() {
-		var user models.User
-
-		err := rows.Scan(
-			&user.ID,
-			&user.Name,
-			&user.Surname,
-			&user.Patronymic,
-			&user.PassportSerie,
-			&user.PassportNumber,
-		)
+		user, err := r.scanUser(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		userList.Users = append(userList.Users, user)
+		userList.Users = append(userList.Users, *user)
 	}
 
-	return &userList, nil
+	return &userList, rows.Err()
 }
 
+// ListPendingUsers returns users waiting for data from the name service, the ones tried less often first.
+func (r *Repository) ListPendingUsers(ctx context.Context, limit int) ([]models.User, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT ` + userColumns + ` FROM users 
+		WHERE org_id = $1 AND enrich_status = 'pending'
+		ORDER BY enrich_attempts, id
+		LIMIT $2`
+
+	rows, err := tx.QueryContext(ctx, query, orgID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "users")
+		}
+	}()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := r.scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
+}
+
+// FailEnrichment counts a failed attempt to fill the user from the name service and sets the status,
+// pending users are retried. sql.ErrNoRows is returned if there is no such user.
+func (r *Repository) FailEnrichment(ctx context.Context, id int, status models.EnrichStatus) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	query := `UPDATE users SET enrich_status = $1, enrich_attempts = enrich_attempts + 1 WHERE id = $2 AND org_id = $3`
+
+	result, err := tx.ExecContext(ctx, query, status, id, orgID)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// userColumns are selected by scanUser.
+const userColumns = `id, name, surname, patronymic, address, ` + passportColumns + `, role, manager_id, enrich_status, enrich_attempts`
+
+func (r *Repository) scanUser(s scanner) (*models.User, error) {
+	var user models.User
+	var passport storedPassport
+	var managerID sql.NullInt64
+
+	err := s.Scan(&user.ID, &user.Name, &user.Surname, &user.Patronymic, &user.Address,
+		&passport.series, &passport.number, &passport.encrypted, &passport.index, &user.Role, &managerID,
+		&user.EnrichStatus, &user.EnrichAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if user.Passport, err = r.decryptPassport(passport); err != nil {
+		return nil, err
+	}
+	user.ManagerID = int(managerID.Int64)
+
+	return &user, nil
+}
+
+// taskColumns are selected by scanTask, tasks table must be aliased as t.
+const taskColumns = `t.id, t.user_id, t.title, t.description, t.project_id, t.start_time, t.end_time, t.minutes, t.auto_stopped,
+	COALESCE((SELECT json_agg(json_build_object('id', tg.id, 'name', tg.name) ORDER BY tg.name)
+		FROM task_tags tt JOIN tags tg ON tg.id = tt.tag_id WHERE tt.task_id = t.id), '[]'),
+	COALESCE((SELECT json_agg(json_build_object('since', ts.start_time, 'until', ts.end_time) ORDER BY ts.start_time)
+		FROM task_segments ts WHERE ts.task_id = t.id), '[]')`
+
 func (r *Repository) CreateTask(ctx context.Context, task *models.Task) error {
-	query := `INSERT INTO tasks (user_id, start_time, end_time, minutes) 
-		VALUES ($1, $2, $3, $4)
-		RETURNING id`
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
 
-	row := r.db.QueryRowContext(ctx, query, task.UserID, task.Since, task.Until, task.Minutes)
-	if err := row.Scan(&task.ID); err != nil {
+	if err := insertTask(ctx, tx, orgID, task); err != nil {
 		return err
 	}
 
-	return nil
+	if err := auditTask(ctx, tx, models.AuditCreate, nil, task); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *Repository) GetTask(ctx context.Context, userID, taskID int) (*models.Task, error) {
-	query := `SELECT id, user_id, start_time, end_time, minutes FROM tasks WHERE user_id = $1 AND id = $2`
+// SwitchTask atomically ends the running task of the user and creates the new one.
+// The running task is ended at the start time of the new task and returned, nil if there was none.
+func (r *Repository) SwitchTask(ctx context.Context, task *models.Task) (*models.Task, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
 
-	row := r.db.QueryRowContext(ctx, query, userID, taskID)
-	task := &models.Task{ID: taskID}
-	if err := row.Scan(&task.UserID, &task.Since, &task.Until, &task.Minutes); err != nil {
+	// lock the user so concurrent switches of the same user are serialized
+	var userID int
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = $1 AND org_id = $2 FOR UPDATE`, task.UserID, orgID).Scan(&userID); err != nil {
 		return nil, err
 	}
 
-	return task, nil
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.user_id = $1 AND t.org_id = $2 AND t.end_time IS NULL`
+
+	running, err := scanTask(tx.QueryRowContext(ctx, query, task.UserID, orgID))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		running = nil
+	case err != nil:
+		return nil, err
+	default:
+		before := *running
+		before.Segments = slices.Clone(running.Segments)
+		running.End(task.Since)
+
+		query := `UPDATE tasks SET end_time = $1, minutes = $2 WHERE id = $3 AND org_id = $4 AND end_time IS NULL`
+		if _, err := tx.ExecContext(ctx, query, running.Until, running.Minutes, running.ID, orgID); err != nil {
+			return nil, err
+		}
+
+		query = `UPDATE task_segments SET end_time = $1 WHERE task_id = $2 AND end_time IS NULL`
+		if _, err := tx.ExecContext(ctx, query, running.Until, running.ID); err != nil {
+			return nil, err
+		}
+
+		if err := auditTask(ctx, tx, models.AuditUpdate, &before, running); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := insertTask(ctx, tx, orgID, task); err != nil {
+		return nil, err
+	}
+
+	if err := auditTask(ctx, tx, models.AuditCreate, nil, task); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return running, nil
+}
+
+func (r *Repository) GetTask(ctx context.Context, userID, taskID int) (*models.Task, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.user_id = $1 AND t.id = $2 AND t.org_id = $3`
+
+	return scanTask(tx.QueryRowContext(ctx, query, userID, taskID, orgID))
+}
+
+// OverlappingTask returns ID of a task of the user other than excludeID overlapping [since, until).
+// Running tasks last up to now. sql.ErrNoRows is returned if there is no such task.
+func (r *Repository) OverlappingTask(ctx context.Context, userID, excludeID int, since, until, now time.Time) (int, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT id FROM tasks
+		WHERE org_id = $6 AND user_id = $1 AND id <> $2 AND start_time < $4 AND COALESCE(end_time, $5) > $3
+		ORDER BY start_time
+		LIMIT 1`
+
+	var id int
+	if err := tx.QueryRowContext(ctx, query, userID, excludeID, since, until, now, orgID).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
 }
 
-func (r *Repository) UpdateTask(ctx context.Context, task *models.Task) error {
-	query := `UPDATE tasks SET start_time = $1, end_time = $2, minutes = $3 WHERE id = $4`
+// UpdateTask stores the task if it's still at the revision read, models.ErrStale is returned otherwise.
+// The task is locked meanwhile, so concurrent changes of it are applied one by one.
+func (r *Repository) UpdateTask(ctx context.Context, task *models.Task, read models.TaskRevision) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
 
-	_, err := r.db.ExecContext(ctx, query, task.Since, task.Until, task.Minutes, task.ID)
+	before, err := lockTask(ctx, tx, orgID, task.ID)
 	if err != nil {
 		return err
 	}
+	if before.Revision() != read {
+		return models.ErrStale
+	}
 
-	return nil
+	query := `UPDATE tasks 
+		SET title = $1, description = $2, project_id = $3, start_time = $4, end_time = $5, minutes = $6, auto_stopped = $7 
+		WHERE id = $8 AND org_id = $9`
+
+	_, err = tx.ExecContext(ctx, query, task.Title, task.Description, nullInt(task.ProjectID), task.Since, nullTime(task.Until), task.Minutes, task.AutoStopped, task.ID, orgID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = $1`, task.ID); err != nil {
+		return err
+	}
+
+	if err := setTaskTags(ctx, tx, task); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_segments WHERE task_id = $1`, task.ID); err != nil {
+		return err
+	}
+
+	if err := insertTaskSegments(ctx, tx, task); err != nil {
+		return err
+	}
+
+	if err := auditTask(ctx, tx, models.AuditUpdate, before, task); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *Repository) ListTasks(ctx context.Context, userID int) ([]models.Task, error) {
-	query := `SELECT id, user_id, start_time, end_time, minutes FROM tasks WHERE user_id = $1`
+// lockTask returns the task locking it until the end of the transaction.
+// Segments and tags of the task are changed by its ID after it.
+func lockTask(ctx context.Context, tx *sql.Tx, orgID, id int) (*models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.id = $1 AND t.org_id = $2 FOR UPDATE OF t`
+
+	return scanTask(tx.QueryRowContext(ctx, query, id, orgID))
+}
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+// ListTasks returns a page of tasks of the user matching the filter and the total number of such tasks.
+func (r *Repository) ListTasks(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	qb := goqu.From(goqu.T("tasks").As("t")).
+		Where(goqu.I("t.org_id").Eq(orgID), goqu.I("t.user_id").Eq(userID))
+
+	if opts.Title != "" {
+		qb = qb.Where(goqu.I("t.title").ILike(fmt.Sprint("%", opts.Title, "%")))
+	}
+	if opts.ProjectID != 0 {
+		qb = qb.Where(goqu.I("t.project_id").Eq(opts.ProjectID))
+	}
+	if opts.TagID != 0 {
+		qb = qb.Where(goqu.L("EXISTS (SELECT 1 FROM task_tags ft WHERE ft.task_id = t.id AND ft.tag_id = ?)", opts.TagID))
+	}
+	if !opts.From.IsZero() {
+		qb = qb.Where(goqu.Or(goqu.I("t.end_time").IsNull(), goqu.I("t.end_time").Gt(opts.From)))
+	}
+	if !opts.To.IsZero() {
+		qb = qb.Where(goqu.I("t.start_time").Lt(opts.To))
+	}
+
+	const openSegment = "EXISTS (SELECT 1 FROM task_segments fs WHERE fs.task_id = t.id AND fs.end_time IS NULL)"
+	switch opts.State {
+	case models.TaskFinished:
+		qb = qb.Where(goqu.I("t.end_time").IsNotNull())
+	case models.TaskRunning:
+		qb = qb.Where(goqu.I("t.end_time").IsNull(), goqu.L(openSegment))
+	case models.TaskPaused:
+		qb = qb.Where(goqu.I("t.end_time").IsNull(), goqu.L("NOT "+openSegment))
+	}
+
+	// get total count
+	countQuery, countArgs, err := qb.Select(goqu.L("COUNT(*)")).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("build count query: %w", err)
+	}
+	logging.FromContext(ctx).Debug("list count query", "query", countQuery, "repository", "tasks")
+
+	list := &models.TaskList{Limit: opts.Limit}
+	if err := tx.QueryRowContext(ctx, countQuery, countArgs...).Scan(&list.Count); err != nil {
+		return nil, fmt.Errorf("count: %w", err)
+	}
+
+	sort := opts.Sort
+	if sort == "" {
+		sort = models.TaskSortID
+	}
+	key, ok := taskSortKeys[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort %q", sort)
+	}
+
+	if opts.Cursor != "" {
+		c, err := cursor.Decode(opts.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+
+		op := ">"
+		if opts.Desc {
+			op = "<"
+		}
+		qb = qb.Where(goqu.L(fmt.Sprintf("(%s, t.id) %s (?::%s, ?)", key.expr, op, key.typ), c.Value, c.ID))
+	}
+
+	if opts.Desc {
+		qb = qb.Order(goqu.L(key.expr).Desc(), goqu.I("t.id").Desc())
+	} else {
+		qb = qb.Order(goqu.L(key.expr).Asc(), goqu.I("t.id").Asc())
+	}
+	if opts.Limit > 0 {
+		// one more task tells whether there is a next page
+		qb = qb.Limit(uint(opts.Limit + 1))
+	}
+
+	query, args, err := qb.Select(goqu.L(taskColumns)).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("build select query: %w", err)
+	}
+
+	logging.FromContext(ctx).Debug("list query", "query", query, "args", args, "repository", "tasks")
+
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
-			slog.Debug("db rows close", "err", err, "repository", "tasks")
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "tasks")
 		}
 	}()
 
-	var tasks []models.Task
 	for rows.Next() {
-		var task models.Task
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		list.Tasks = append(list.Tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if opts.Limit > 0 && len(list.Tasks) > opts.Limit {
+		list.Tasks = list.Tasks[:opts.Limit]
+		list.NextCursor = cursor.Encode(sort, &list.Tasks[opts.Limit-1])
+	}
+
+	return list, nil
+}
+
+// TaskEfforts returns time spent on each task of the user within [from, to) sorted by the time descending.
+// Only worked segments are counted, running segments are counted up to now.
+func (r *Repository) TaskEfforts(ctx context.Context, userID int, from, to, now time.Time) ([]models.TaskEffort, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT t.id, t.start_time, t.end_time, t.auto_stopped,
+			SUM(EXTRACT(EPOCH FROM LEAST(COALESCE(s.end_time, $4), $3) - GREATEST(s.start_time, $2)))::BIGINT AS seconds
+		FROM tasks t
+		JOIN task_segments s ON s.task_id = t.id
+		WHERE t.org_id = $5 AND t.user_id = $1 AND s.start_time < $3 AND COALESCE(s.end_time, $4) > $2
+		GROUP BY t.id
+		ORDER BY seconds DESC, t.id`
+
+	rows, err := tx.QueryContext(ctx, query, userID, from, to, now, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "tasks")
+		}
+	}()
+
+	var efforts []models.TaskEffort
+	for rows.Next() {
+		var effort models.TaskEffort
+		var until sql.NullTime
+		var seconds int64
+
+		if err := rows.Scan(&effort.TaskID, &effort.Since, &until, &effort.AutoStopped, &seconds); err != nil {
+			return nil, err
+		}
+		effort.Until = until.Time
+		effort.Duration = time.Duration(seconds) * time.Second
+
+		efforts = append(efforts, effort)
+	}
+
+	return efforts, rows.Err()
+}
+
+// CountRunningTasks returns the number of running tasks of all users, paused tasks are not counted.
+func (r *Repository) CountRunningTasks(ctx context.Context) (int, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT COUNT(*) FROM task_segments s JOIN tasks t ON t.id = s.task_id WHERE t.org_id = $1 AND s.end_time IS NULL`
+
+	var count int
+	if err := tx.QueryRowContext(ctx, query, orgID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ListRunningTasks returns running tasks of all users started before the time,
+// ordered by the start of their current segment.
+func (r *Repository) ListRunningTasks(ctx context.Context, startedBefore time.Time) ([]models.Task, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
 
-		err := rows.Scan(&task.ID, &task.UserID, &task.Since, &task.Until, &task.Minutes)
+	query := `SELECT ` + taskColumns + `
+		FROM tasks t
+		JOIN task_segments s ON s.task_id = t.id AND s.end_time IS NULL
+		WHERE t.org_id = $2 AND t.end_time IS NULL AND t.start_time < $1
+		ORDER BY s.start_time, t.id`
+
+	rows, err := tx.QueryContext(ctx, query, startedBefore, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "tasks")
+		}
+	}()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
+		tasks = append(tasks, *task)
+	}
 
-		tasks = append(tasks, task)
+	return tasks, rows.Err()
+}
+
+// AutoStopTask stores the end of the task made by the auto-stop policy and marks it auto-stopped.
+// sql.ErrNoRows is returned if the task is not running in the same segment anymore, e.g. the user has ended it meanwhile.
+func (r *Repository) AutoStopTask(ctx context.Context, task *models.Task) error {
+	if len(task.Segments) == 0 {
+		return sql.ErrNoRows
+	}
+	last := task.Segments[len(task.Segments)-1]
+
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	before, err := lockTask(ctx, tx, orgID, task.ID)
+	if err != nil {
+		return err
 	}
 
-	return tasks, nil
+	query := `UPDATE task_segments SET end_time = $1 WHERE task_id = $2 AND start_time = $3 AND end_time IS NULL`
+	result, err := tx.ExecContext(ctx, query, last.Until, task.ID, last.Since)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	query = `UPDATE tasks SET end_time = $1, minutes = $2, auto_stopped = true WHERE id = $3 AND org_id = $4 AND end_time IS NULL`
+	result, err = tx.ExecContext(ctx, query, task.Until, task.Minutes, task.ID, orgID)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	if err := auditTask(ctx, tx, models.AuditUpdate, before, task); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// nullTime converts zero time to NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// nullInt converts zero to NULL.
+func nullInt(i int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(i), Valid: i != 0}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(s scanner) (*models.Task, error) {
+	var task models.Task
+	var projectID sql.NullInt64
+	var until sql.NullTime
+	var tags, segments []byte
+
+	err := s.Scan(&task.ID, &task.UserID, &task.Title, &task.Description, &projectID, &task.Since, &until, &task.Minutes, &task.AutoStopped, &tags, &segments)
+	if err != nil {
+		return nil, err
+	}
+
+	task.ProjectID = int(projectID.Int64)
+	task.Until = until.Time
+	if err := json.Unmarshal(tags, &task.Tags); err != nil {
+		return nil, fmt.Errorf("unmarshal task tags: %w", err)
+	}
+	if err := json.Unmarshal(segments, &task.Segments); err != nil {
+		return nil, fmt.Errorf("unmarshal task segments: %w", err)
+	}
+
+	return &task, nil
+}
+
+// insertTask creates the task with its segments and tags, models.ErrDuplicate is returned if the user already has a running task.
+func insertTask(ctx context.Context, tx *sql.Tx, orgID int, task *models.Task) error {
+	query := `INSERT INTO tasks (user_id, title, description, project_id, start_time, end_time, minutes, auto_stopped, org_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+
+	row := tx.QueryRowContext(ctx, query, task.UserID, task.Title, task.Description, nullInt(task.ProjectID), task.Since, nullTime(task.Until), task.Minutes, task.AutoStopped, orgID)
+	if err := row.Scan(&task.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	if err := insertTaskSegments(ctx, tx, task); err != nil {
+		return err
+	}
+
+	return setTaskTags(ctx, tx, task)
+}
+
+// insertTaskSegments stores segments of the task, a task without segments is worked in a single one.
+func insertTaskSegments(ctx context.Context, tx *sql.Tx, task *models.Task) error {
+	segments := task.Segments
+	if len(segments) == 0 {
+		segments = []models.TaskSegment{{Since: task.Since, Until: task.Until}}
+	}
+
+	query := `INSERT INTO task_segments (task_id, start_time, end_time) VALUES ($1, $2, $3)`
+	for _, s := range segments {
+		if _, err := tx.ExecContext(ctx, query, task.ID, s.Since, nullTime(s.Until)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setTaskTags(ctx context.Context, tx *sql.Tx, task *models.Task) error {
+	if len(task.Tags) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(task.Tags))
+	for i, tag := range task.Tags {
+		ids[i] = int64(tag.ID)
+	}
+
+	query := `INSERT INTO task_tags (task_id, tag_id) SELECT $1, unnest($2::INT[]) ON CONFLICT DO NOTHING`
+
+	_, err := tx.ExecContext(ctx, query, task.ID, pq.Array(ids))
+	return err
+}
+
+// begin starts a transaction within the organization of the context, tenant.ErrMissing is returned if there is none.
+// Queries filter by the organization themselves, row-level security restricts the transaction to it as a backstop.
+func (r *Repository) begin(ctx context.Context) (*sql.Tx, int, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('app.org_id', $1, true)`, strconv.Itoa(orgID)); err != nil {
+		rollback(ctx, tx)
+		return nil, 0, err
+	}
+
+	return tx, orgID, nil
+}
+
+func rollback(ctx context.Context, tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		logging.FromContext(ctx).Debug("db tx rollback", "err", err)
+	}
+}
+
+// isUniqueViolation reports whether the error is caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
 }
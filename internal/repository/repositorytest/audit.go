@@ -0,0 +1,174 @@
+package repositorytest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/audit"
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func testAuditLog(t *testing.T, repo usecase.Repository) {
+	ctx := logging.WithRequestID(audit.WithActor(background(), 7, models.RoleAdmin), "req-1")
+	start := time.Now().Add(-time.Second)
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}, Name: "Иван"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	user.Name = "Пётр"
+	require.NoError(t, repo.UpdateUser(ctx, user))
+
+	first := &models.Task{UserID: user.ID, Title: "Report", Since: time.Now().Add(-time.Hour)}
+	require.NoError(t, repo.CreateTask(ctx, first))
+
+	// changes made by background workers have no actor
+	second := &models.Task{UserID: user.ID, Title: "Review", Since: time.Now()}
+	_, err := repo.SwitchTask(background(), second)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteUser(ctx, user))
+
+	log, err := repo.ListAuditEntries(background(), models.AuditFilter{})
+	require.NoError(t, err)
+	require.Empty(t, log.NextCursor)
+
+	type change struct {
+		action   models.AuditAction
+		entity   models.AuditEntity
+		entityID int
+	}
+	var changes []change
+	for _, e := range log.Entries {
+		changes = append(changes, change{e.Action, e.Entity, e.EntityID})
+	}
+	require.Equal(t, []change{
+		{models.AuditDelete, models.AuditUser, user.ID},
+		{models.AuditDelete, models.AuditTask, second.ID},
+		{models.AuditDelete, models.AuditTask, first.ID},
+		{models.AuditCreate, models.AuditTask, second.ID},
+		{models.AuditUpdate, models.AuditTask, first.ID},
+		{models.AuditCreate, models.AuditTask, first.ID},
+		{models.AuditUpdate, models.AuditUser, user.ID},
+		{models.AuditCreate, models.AuditUser, user.ID},
+	}, changes)
+
+	t.Run("Entry", func(t *testing.T) {
+		updated := log.Entries[6]
+		require.Equal(t, 7, updated.ActorID)
+		require.Equal(t, models.RoleAdmin, updated.ActorRole)
+		require.Equal(t, "req-1", updated.RequestID)
+		require.WithinRange(t, updated.CreatedAt, start, time.Now())
+		require.Equal(t, "Иван", snapshot(t, updated.Before)["name"])
+		require.Equal(t, "Пётр", snapshot(t, updated.After)["name"])
+
+		created := log.Entries[7]
+		require.Nil(t, created.Before)
+		require.EqualValues(t, user.ID, snapshot(t, created.After)["id"])
+
+		deleted := log.Entries[0]
+		require.Equal(t, "Пётр", snapshot(t, deleted.Before)["name"])
+		require.Nil(t, deleted.After)
+
+		// tasks deleted with the user
+		deletedTask := log.Entries[2]
+		require.Equal(t, 7, deletedTask.ActorID)
+		require.Equal(t, "Report", snapshot(t, deletedTask.Before)["title"])
+		require.Nil(t, deletedTask.After)
+
+		stopped := log.Entries[4]
+		require.Zero(t, stopped.ActorID)
+		require.Empty(t, stopped.ActorRole)
+		require.Empty(t, stopped.RequestID)
+		require.Nil(t, snapshot(t, stopped.Before)["until"])
+		require.NotNil(t, snapshot(t, stopped.After)["until"])
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		for _, tc := range []struct {
+			filter models.AuditFilter
+			want   int
+		}{
+			{models.AuditFilter{Entity: models.AuditUser}, 3},
+			{models.AuditFilter{Entity: models.AuditTask, EntityID: first.ID}, 3},
+			{models.AuditFilter{ActorID: 7}, 6},
+			{models.AuditFilter{From: start, To: time.Now().Add(time.Second)}, 8},
+			{models.AuditFilter{From: time.Now().Add(time.Second)}, 0},
+			{models.AuditFilter{To: start}, 0},
+		} {
+			log, err := repo.ListAuditEntries(background(), tc.filter)
+			require.NoError(t, err)
+			require.Len(t, log.Entries, tc.want, "%+v", tc.filter)
+		}
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		var ids []int
+		filter := models.AuditFilter{Limit: 4}
+		for {
+			page, err := repo.ListAuditEntries(background(), filter)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(page.Entries), filter.Limit)
+
+			for _, e := range page.Entries {
+				ids = append(ids, e.ID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			filter.Cursor = page.NextCursor
+		}
+
+		var want []int
+		for _, e := range log.Entries {
+			want = append(want, e.ID)
+		}
+		require.Equal(t, want, ids)
+
+		_, err := repo.ListAuditEntries(background(), models.AuditFilter{Cursor: "garbage"})
+		require.ErrorIs(t, err, models.ErrInvalidCursor)
+	})
+
+	t.Run("After", func(t *testing.T) {
+		// oldest entries first
+		entries, err := repo.ListAuditEntriesAfter(background(), log.Entries[7].ID, 3)
+		require.NoError(t, err)
+		require.Equal(t, []models.AuditEntry{log.Entries[6], log.Entries[5], log.Entries[4]}, entries)
+
+		entries, err = repo.ListAuditEntriesAfter(background(), log.Entries[1].ID, 10)
+		require.NoError(t, err)
+		require.Equal(t, []models.AuditEntry{log.Entries[0]}, entries)
+
+		entries, err = repo.ListAuditEntriesAfter(background(), log.Entries[0].ID, 10)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("Recorded", func(t *testing.T) {
+		// entries built by the change are recorded with the IDs they are appended with
+		rctx, rec := audit.Record(ctx)
+		other := &models.User{Passport: models.Passport{Series: "1234", Number: "000001"}}
+		require.NoError(t, repo.CreateUser(rctx, other))
+		require.NoError(t, repo.CreateTask(rctx, &models.Task{UserID: other.ID, Title: "Recorded", Since: time.Now()}))
+
+		recorded := rec.Entries()
+		require.Len(t, recorded, 2)
+
+		entries, err := repo.ListAuditEntriesAfter(background(), log.Entries[0].ID, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		for i, e := range entries {
+			require.Equal(t, e.ID, recorded[i].ID)
+			require.Equal(t, e.EntityID, recorded[i].EntityID)
+		}
+	})
+}
+
+func snapshot(t *testing.T, raw json.RawMessage) map[string]any {
+	var s map[string]any
+	require.NoError(t, json.Unmarshal(raw, &s))
+	return s
+}
@@ -0,0 +1,150 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func testAutoStopTask(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+	now := time.Now().Truncate(time.Second)
+
+	newUser := func(number string) int {
+		user := &models.User{Passport: models.Passport{Series: "1234", Number: number}}
+		require.NoError(t, repo.CreateUser(ctx, user))
+		return user.ID
+	}
+
+	// resumed in the morning and forgotten
+	forgotten := &models.Task{UserID: newUser("000001"), Since: now.Add(-30 * time.Hour), Segments: []models.TaskSegment{
+		{Since: now.Add(-30 * time.Hour), Until: now.Add(-29 * time.Hour)},
+		{Since: now.Add(-10 * time.Hour)},
+	}}
+	recent := &models.Task{UserID: newUser("000002"), Since: now.Add(-time.Hour)}
+	paused := &models.Task{UserID: newUser("000003"), Since: now.Add(-20 * time.Hour), Segments: []models.TaskSegment{
+		{Since: now.Add(-20 * time.Hour), Until: now.Add(-19 * time.Hour)},
+	}}
+	for _, task := range []*models.Task{recent, forgotten, paused} {
+		require.NoError(t, repo.CreateTask(ctx, task))
+	}
+
+	tasks, err := repo.ListRunningTasks(ctx, now.Add(-5*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, forgotten.ID, tasks[0].ID)
+	require.Len(t, tasks[0].Segments, 2)
+
+	// the task is started long ago even though it's resumed recently
+	tasks, err = repo.ListRunningTasks(ctx, now.Add(-20*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, forgotten.ID, tasks[0].ID)
+
+	tasks, err = repo.ListRunningTasks(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2, "paused tasks aren't running")
+	require.Equal(t, forgotten.ID, tasks[0].ID, "ordered by the start of the running segment")
+	require.Equal(t, recent.ID, tasks[1].ID)
+
+	stop := tasks[0]
+	stop.End(now.Add(-2 * time.Hour))
+	stop.AutoStopped = true
+	require.NoError(t, repo.AutoStopTask(ctx, &stop))
+
+	got, err := repo.GetTask(ctx, forgotten.UserID, forgotten.ID)
+	require.NoError(t, err)
+	require.True(t, got.AutoStopped)
+	require.Equal(t, models.TaskFinished, got.State())
+	require.True(t, got.Until.Equal(now.Add(-2*time.Hour)))
+	require.True(t, got.Segments[1].Until.Equal(now.Add(-2*time.Hour)))
+	require.Equal(t, 9*60, got.Minutes)
+
+	err = repo.AutoStopTask(ctx, &stop)
+	require.True(t, errors.Is(err, sql.ErrNoRows), "stopped task can't be stopped again: %v", err)
+
+	// the user pauses the task before the stale copy is stopped
+	stale := tasks[1]
+	stale.End(now)
+	stale.AutoStopped = true
+
+	read := recent.Revision()
+	recent.Pause(now.Add(-time.Minute))
+	require.NoError(t, repo.UpdateTask(ctx, recent, read))
+
+	err = repo.AutoStopTask(ctx, &stale)
+	require.True(t, errors.Is(err, sql.ErrNoRows), "changed task must not be stopped: %v", err)
+
+	got, err = repo.GetTask(ctx, recent.UserID, recent.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.TaskPaused, got.State())
+	require.False(t, got.AutoStopped)
+
+	// the flag is kept in reports until the task is changed
+	efforts, err := repo.TaskEfforts(ctx, forgotten.UserID, now.Add(-48*time.Hour), now, now)
+	require.NoError(t, err)
+	require.Len(t, efforts, 1)
+	require.True(t, efforts[0].AutoStopped)
+
+	var rows []models.TimesheetRow
+	require.NoError(t, repo.Timesheet(ctx, forgotten.UserID, now.Add(-48*time.Hour), now, now, func(row *models.TimesheetRow) error {
+		rows = append(rows, *row)
+		return nil
+	}))
+	require.Len(t, rows, 1)
+	require.True(t, rows[0].AutoStopped)
+
+	got, err = repo.GetTask(ctx, forgotten.UserID, forgotten.ID)
+	require.NoError(t, err)
+	got.AutoStopped = false
+	require.NoError(t, repo.UpdateTask(ctx, got, got.Revision()))
+
+	got, err = repo.GetTask(ctx, forgotten.UserID, forgotten.ID)
+	require.NoError(t, err)
+	require.False(t, got.AutoStopped)
+}
+
+func testWithLock(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	var ran []string
+	acquired, err := repo.WithLock(ctx, "outer", func(ctx context.Context) error {
+		ran = append(ran, "outer")
+
+		acquired, err := repo.WithLock(ctx, "outer", func(ctx context.Context) error {
+			ran = append(ran, "nested")
+			return nil
+		})
+		require.NoError(t, err)
+		require.False(t, acquired, "held lock can't be taken")
+
+		acquired, err = repo.WithLock(ctx, "other", func(ctx context.Context) error {
+			ran = append(ran, "other")
+			return nil
+		})
+		require.NoError(t, err)
+		require.True(t, acquired, "locks of other names are independent")
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.Equal(t, []string{"outer", "other"}, ran)
+
+	failure := errors.New("job failed")
+	acquired, err = repo.WithLock(ctx, "outer", func(ctx context.Context) error {
+		return failure
+	})
+	require.True(t, acquired, "lock is released after the run")
+	require.ErrorIs(t, err, failure)
+
+	acquired, err = repo.WithLock(ctx, "outer", func(ctx context.Context) error { return nil })
+	require.NoError(t, err)
+	require.True(t, acquired, "lock is released after a failed run")
+}
@@ -0,0 +1,68 @@
+package repositorytest
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func testAPIKeys(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	manager := &models.User{Passport: models.Passport{Series: "1111", Number: "111111"}, Role: models.RoleManager}
+	require.NoError(t, repo.CreateUser(ctx, manager))
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}, ManagerID: manager.ID}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	got, err := repo.GetUser(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.RoleEmployee, got.Role)
+	require.Equal(t, manager.ID, got.ManagerID)
+
+	key := &models.APIKey{UserID: user.ID, Name: "laptop", Prefix: "tt_abcd", Hash: []byte("hash")}
+	require.NoError(t, repo.CreateAPIKey(ctx, key))
+	require.NotZero(t, key.ID)
+	require.False(t, key.CreatedAt.IsZero())
+	require.ErrorIs(t, repo.CreateAPIKey(ctx, &models.APIKey{UserID: user.ID, Prefix: "tt_abcd", Hash: []byte("hash")}), models.ErrDuplicate)
+
+	found, err := repo.GetAPIKeyByHash(ctx, []byte("hash"))
+	require.NoError(t, err)
+	require.Equal(t, key.ID, found.ID)
+	require.Equal(t, user.ID, found.UserID)
+
+	require.NoError(t, repo.RevokeAPIKey(ctx, user.ID, key.ID))
+	require.ErrorIs(t, repo.RevokeAPIKey(ctx, user.ID, key.ID), sql.ErrNoRows)
+
+	_, err = repo.GetAPIKeyByHash(ctx, []byte("hash"))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	keys, err := repo.ListAPIKeys(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.False(t, keys[0].RevokedAt.IsZero())
+}
+
+func testFeedToken(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	_, err := repo.GetFeedToken(ctx, user.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, repo.SetFeedToken(ctx, user.ID, []byte("hash")))
+	hash, err := repo.GetFeedToken(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hash"), hash)
+
+	require.NoError(t, repo.SetFeedToken(ctx, user.ID, nil))
+	_, err = repo.GetFeedToken(ctx, user.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.ErrorIs(t, repo.SetFeedToken(ctx, -1, []byte("other")), sql.ErrNoRows)
+}
@@ -0,0 +1,110 @@
+package repositorytest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func testProjectsAndTags(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	project := &models.Project{Name: "Time tracker", Description: "Internal"}
+	require.NoError(t, repo.CreateProject(ctx, project))
+	require.NotZero(t, project.ID)
+	require.ErrorIs(t, repo.CreateProject(ctx, &models.Project{Name: "Time tracker"}), models.ErrDuplicate)
+
+	bug := &models.Tag{Name: "bug"}
+	require.NoError(t, repo.CreateTag(ctx, bug))
+	urgent := &models.Tag{Name: "urgent"}
+	require.NoError(t, repo.CreateTag(ctx, urgent))
+	require.ErrorIs(t, repo.CreateTag(ctx, &models.Tag{Name: "bug"}), models.ErrDuplicate)
+
+	t.Run("Projects", func(t *testing.T) {
+		project.Description = "Public"
+		require.NoError(t, repo.UpdateProject(ctx, project))
+
+		p, err := repo.GetProject(ctx, project.ID)
+		require.NoError(t, err)
+		require.Equal(t, project, p)
+
+		projects, err := repo.ListProjects(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []models.Project{*project}, projects)
+
+		require.ErrorIs(t, repo.UpdateProject(ctx, &models.Project{ID: -1, Name: "none"}), sql.ErrNoRows)
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		tags, err := repo.ListTags(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []models.Tag{*bug, *urgent}, tags)
+
+		require.ErrorIs(t, repo.UpdateTag(ctx, &models.Tag{ID: urgent.ID, Name: "bug"}), models.ErrDuplicate)
+	})
+
+	t.Run("Tasks", func(t *testing.T) {
+		user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+		require.NoError(t, repo.CreateUser(ctx, user))
+
+		described := &models.Task{
+			UserID:      user.ID,
+			Title:       "Fix report bug",
+			Description: "Reports are empty",
+			ProjectID:   project.ID,
+			Tags:        []models.Tag{*urgent, *bug},
+			Since:       time.Now().Add(-time.Hour),
+			Until:       time.Now(),
+		}
+		require.NoError(t, repo.CreateTask(ctx, described))
+		plain := &models.Task{UserID: user.ID, Since: time.Now()}
+		require.NoError(t, repo.CreateTask(ctx, plain))
+
+		task, err := repo.GetTask(ctx, user.ID, described.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Fix report bug", task.Title)
+		require.Equal(t, "Reports are empty", task.Description)
+		require.Equal(t, project.ID, task.ProjectID)
+		require.Equal(t, []models.Tag{*bug, *urgent}, task.Tags)
+
+		for _, tc := range []struct {
+			filter models.TaskFilter
+			ids    []int
+		}{
+			{models.TaskFilter{}, []int{described.ID, plain.ID}},
+			{models.TaskFilter{Title: "REPORT"}, []int{described.ID}},
+			{models.TaskFilter{ProjectID: project.ID}, []int{described.ID}},
+			{models.TaskFilter{TagID: bug.ID}, []int{described.ID}},
+			{models.TaskFilter{TagID: bug.ID, Title: "none"}, nil},
+		} {
+			list, err := repo.ListTasks(ctx, user.ID, models.TaskListOpts{TaskFilter: tc.filter})
+			require.NoError(t, err)
+			require.Equal(t, len(tc.ids), list.Count)
+
+			var ids []int
+			for _, task := range list.Tasks {
+				ids = append(ids, task.ID)
+			}
+			require.Equal(t, tc.ids, ids, tc.filter)
+		}
+
+		task.Tags = []models.Tag{*urgent}
+		require.NoError(t, repo.UpdateTask(ctx, task, task.Revision()))
+
+		task, err = repo.GetTask(ctx, user.ID, described.ID)
+		require.NoError(t, err)
+		require.Equal(t, []models.Tag{*urgent}, task.Tags)
+
+		require.NoError(t, repo.DeleteProject(ctx, project.ID))
+		require.NoError(t, repo.DeleteTag(ctx, urgent.ID))
+
+		task, err = repo.GetTask(ctx, user.ID, described.ID)
+		require.NoError(t, err)
+		require.Zero(t, task.ProjectID)
+		require.Empty(t, task.Tags)
+	})
+}
@@ -0,0 +1,51 @@
+// Package repositorytest is a conformance test suite of usecase.Repository implementations.
+// Every storage backend runs it to behave the same way: errors, ordering, pagination and time accounting.
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+)
+
+// Factory returns a new empty repository, it is called for every test of the suite.
+type Factory func(t *testing.T) usecase.Repository
+
+var tests = []struct {
+	name string
+	fn   func(t *testing.T, repo usecase.Repository)
+}{
+	{"Repository", testRepository},
+	{"PassportUniqueness", testPassportUniqueness},
+	{"RunningTasks", testRunningTasks},
+	{"TaskSegments", testTaskSegments},
+	{"OverlappingTask", testOverlappingTask},
+	{"CountRunningTasks", testCountRunningTasks},
+	{"AutoStopTask", testAutoStopTask},
+	{"WithLock", testWithLock},
+	{"ListTasksPages", testListTasksPages},
+	{"ProjectsAndTags", testProjectsAndTags},
+	{"Teams", testTeams},
+	{"APIKeys", testAPIKeys},
+	{"FeedToken", testFeedToken},
+	{"Timesheet", testTimesheet},
+	{"AuditLog", testAuditLog},
+	{"TenantIsolation", testTenantIsolation},
+}
+
+// background returns the context calls of the suite are made with, it acts within the default organization.
+func background() context.Context {
+	return tenant.WithOrg(context.Background(), models.DefaultOrgID)
+}
+
+// Run runs the suite against repositories returned by the factory.
+func Run(t *testing.T, newRepo Factory) {
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, newRepo(t))
+		})
+	}
+}
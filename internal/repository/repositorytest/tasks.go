@@ -0,0 +1,234 @@
+package repositorytest
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func testRunningTasks(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	first := &models.Task{UserID: user.ID, Since: time.Now().Add(-time.Hour)}
+	require.NoError(t, repo.CreateTask(ctx, first))
+
+	t.Run("OneRunning", func(t *testing.T) {
+		err := repo.CreateTask(ctx, &models.Task{UserID: user.ID, Since: time.Now()})
+		require.ErrorIs(t, err, models.ErrDuplicate)
+	})
+
+	t.Run("SwitchTask", func(t *testing.T) {
+		second := &models.Task{UserID: user.ID, Title: "Review", Since: time.Now()}
+		stopped, err := repo.SwitchTask(ctx, second)
+		require.NoError(t, err)
+		require.NotZero(t, second.ID)
+		require.Equal(t, first.ID, stopped.ID)
+		require.Equal(t, 60, stopped.Minutes)
+
+		task, err := repo.GetTask(ctx, user.ID, first.ID)
+		require.NoError(t, err)
+		require.Equal(t, second.Since.Unix(), task.Until.Unix())
+
+		_, err = repo.SwitchTask(ctx, &models.Task{UserID: -1, Since: time.Now()})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("ConcurrentSwitch", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.SwitchTask(ctx, &models.Task{UserID: user.ID, Since: time.Now()})
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		list, err := repo.ListTasks(ctx, user.ID, models.TaskListOpts{})
+		require.NoError(t, err)
+		require.Len(t, list.Tasks, 12)
+
+		var running int
+		for _, task := range list.Tasks {
+			if task.IsRunning() {
+				running++
+			}
+		}
+		require.Equal(t, 1, running)
+	})
+}
+
+func testTaskSegments(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	now := time.Now().Truncate(time.Second)
+
+	task := &models.Task{UserID: user.ID, Since: now.Add(-4 * time.Hour), Segments: []models.TaskSegment{{Since: now.Add(-4 * time.Hour)}}}
+	require.NoError(t, repo.CreateTask(ctx, task))
+
+	read := task.Revision()
+	task.Pause(now.Add(-3 * time.Hour))
+	task.Resume(now.Add(-time.Hour))
+	require.NoError(t, repo.UpdateTask(ctx, task, read))
+
+	// a copy read before the pause would end the task without it
+	stale := *task
+	stale.Segments = []models.TaskSegment{{Since: task.Since}}
+	stale.End(now)
+	require.ErrorIs(t, repo.UpdateTask(ctx, &stale, read), models.ErrStale)
+
+	got, err := repo.GetTask(ctx, user.ID, task.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.TaskRunning, got.State())
+	require.Len(t, got.Segments, 2)
+	require.True(t, got.Segments[0].Until.Equal(now.Add(-3*time.Hour)))
+	require.True(t, got.Segments[1].Until.IsZero())
+
+	t.Run("TaskEfforts", func(t *testing.T) {
+		efforts, err := repo.TaskEfforts(ctx, user.ID, now.Add(-24*time.Hour), now, now)
+		require.NoError(t, err)
+		require.Len(t, efforts, 1)
+		require.Equal(t, 2*time.Hour, efforts[0].Duration)
+	})
+
+	t.Run("SwitchTask", func(t *testing.T) {
+		stopped, err := repo.SwitchTask(ctx, &models.Task{UserID: user.ID, Since: now})
+		require.NoError(t, err)
+		require.Equal(t, 120, stopped.Minutes)
+
+		got, err := repo.GetTask(ctx, user.ID, task.ID)
+		require.NoError(t, err)
+		require.Equal(t, models.TaskFinished, got.State())
+		require.True(t, got.Segments[1].Until.Equal(now))
+	})
+}
+
+func testCountRunningTasks(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	count, err := repo.CountRunningTasks(ctx)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	now := time.Now()
+	running := models.NewTask(0)
+	for i, task := range []*models.Task{
+		running,
+		{Since: now.Add(-2 * time.Hour), Until: now.Add(-time.Hour), Minutes: 60},
+		models.NewTask(0),
+	} {
+		user := &models.User{Passport: models.Passport{Series: "1234", Number: fmt.Sprintf("%06d", i)}}
+		require.NoError(t, repo.CreateUser(ctx, user))
+
+		task.UserID = user.ID
+		require.NoError(t, repo.CreateTask(ctx, task))
+	}
+
+	count, err = repo.CountRunningTasks(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	read := running.Revision()
+	running.Pause(now)
+	require.NoError(t, repo.UpdateTask(ctx, running, read))
+
+	count, err = repo.CountRunningTasks(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, count, "paused tasks aren't running")
+}
+
+func testOverlappingTask(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	now := time.Now()
+
+	done := &models.Task{UserID: user.ID, Since: now.Add(-5 * time.Hour), Until: now.Add(-4 * time.Hour), Minutes: 60}
+	require.NoError(t, repo.CreateTask(ctx, done))
+
+	running := &models.Task{UserID: user.ID, Since: now.Add(-time.Hour)}
+	require.NoError(t, repo.CreateTask(ctx, running))
+
+	id, err := repo.OverlappingTask(ctx, user.ID, 0, now.Add(-6*time.Hour), now.Add(-4*time.Hour-time.Minute), now)
+	require.NoError(t, err)
+	require.Equal(t, done.ID, id)
+
+	id, err = repo.OverlappingTask(ctx, user.ID, 0, now.Add(-30*time.Minute), now.Add(-10*time.Minute), now)
+	require.NoError(t, err)
+	require.Equal(t, running.ID, id)
+
+	// adjacent tasks don't overlap
+	_, err = repo.OverlappingTask(ctx, user.ID, 0, now.Add(-4*time.Hour), now.Add(-time.Hour), now)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = repo.OverlappingTask(ctx, user.ID, done.ID, now.Add(-5*time.Hour), now.Add(-4*time.Hour), now)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testListTasksPages(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	user := &models.User{Passport: models.Passport{Series: "3456", Number: "789012"}}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	now := time.Now().Truncate(time.Second)
+	var ids []int
+	for i, minutes := range []int{30, 10, 20, 10} {
+		since := now.Add(time.Duration(i-10) * time.Hour)
+		task := &models.Task{UserID: user.ID, Since: since, Until: since.Add(time.Duration(minutes) * time.Minute), Minutes: minutes}
+		require.NoError(t, repo.CreateTask(ctx, task))
+		ids = append(ids, task.ID)
+	}
+	running := &models.Task{UserID: user.ID, Since: now.Add(-time.Hour)}
+	require.NoError(t, repo.CreateTask(ctx, running))
+	ids = append(ids, running.ID)
+
+	pages := func(t *testing.T, opts models.TaskListOpts) []int {
+		var got []int
+		for {
+			list, err := repo.ListTasks(ctx, user.ID, opts)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(list.Tasks), opts.Limit)
+
+			for _, task := range list.Tasks {
+				got = append(got, task.ID)
+			}
+			if list.NextCursor == "" {
+				return got
+			}
+			opts.Cursor = list.NextCursor
+		}
+	}
+
+	require.Equal(t, ids, pages(t, models.TaskListOpts{Limit: 2}))
+	require.Equal(t, []int{ids[4], ids[3], ids[2], ids[1], ids[0]}, pages(t, models.TaskListOpts{Sort: models.TaskSortUntil, Desc: true, Limit: 2}))
+	require.Equal(t, []int{ids[4], ids[1], ids[3], ids[2], ids[0]}, pages(t, models.TaskListOpts{Sort: models.TaskSortMinutes, Limit: 2}))
+	require.Equal(t, []int{ids[3], ids[4]}, pages(t, models.TaskListOpts{
+		TaskFilter: models.TaskFilter{From: now.Add(-7*time.Hour + 5*time.Minute)},
+		Sort:       models.TaskSortSince,
+		Limit:      1,
+	}))
+	require.Equal(t, []int{ids[4]}, pages(t, models.TaskListOpts{TaskFilter: models.TaskFilter{State: models.TaskRunning}, Limit: 10}))
+
+	list, err := repo.ListTasks(ctx, user.ID, models.TaskListOpts{TaskFilter: models.TaskFilter{State: models.TaskFinished}, Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, 4, list.Count)
+
+	_, err = repo.ListTasks(ctx, user.ID, models.TaskListOpts{Sort: models.TaskSortSince, Cursor: list.NextCursor, Limit: 1})
+	require.ErrorIs(t, err, models.ErrInvalidCursor)
+}
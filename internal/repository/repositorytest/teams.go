@@ -0,0 +1,112 @@
+package repositorytest
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func testTeams(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	var users []*models.User
+	for i := range 4 {
+		u := &models.User{Name: fmt.Sprintf("User %d", i), Passport: models.Passport{Series: "1234", Number: fmt.Sprintf("%06d", i)}}
+		require.NoError(t, repo.CreateUser(ctx, u))
+		users = append(users, u)
+	}
+	manager, idle, busy, other := users[0], users[1], users[2], users[3]
+
+	team := &models.Team{Name: "Backend", ManagerID: manager.ID, MemberIDs: []int{idle.ID, busy.ID}}
+	require.NoError(t, repo.CreateTeam(ctx, team))
+	require.NotZero(t, team.ID)
+	require.ErrorIs(t, repo.CreateTeam(ctx, &models.Team{Name: "Backend"}), models.ErrDuplicate)
+
+	empty := &models.Team{Name: "Archive"}
+	require.NoError(t, repo.CreateTeam(ctx, empty))
+
+	t.Run("CRUD", func(t *testing.T) {
+		got, err := repo.GetTeam(ctx, team.ID)
+		require.NoError(t, err)
+		require.Equal(t, team, got)
+
+		teams, err := repo.ListTeams(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []models.Team{*empty, *team}, teams)
+
+		team.Name = "Platform"
+		team.MemberIDs = []int{busy.ID, other.ID}
+		require.NoError(t, repo.UpdateTeam(ctx, team))
+
+		got, err = repo.GetTeam(ctx, team.ID)
+		require.NoError(t, err)
+		require.Equal(t, team, got)
+
+		require.ErrorIs(t, repo.UpdateTeam(ctx, &models.Team{ID: empty.ID, Name: "Platform"}), models.ErrDuplicate)
+		require.ErrorIs(t, repo.UpdateTeam(ctx, &models.Team{ID: -1, Name: "none"}), sql.ErrNoRows)
+
+		require.NoError(t, repo.DeleteTeam(ctx, empty.ID))
+		_, err = repo.GetTeam(ctx, empty.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.ErrorIs(t, repo.DeleteTeam(ctx, empty.ID), sql.ErrNoRows)
+
+		team.MemberIDs = []int{idle.ID, busy.ID, other.ID}
+		require.NoError(t, repo.UpdateTeam(ctx, team))
+	})
+
+	t.Run("TeamEfforts", func(t *testing.T) {
+		now := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+		short := &models.Task{UserID: busy.ID, Since: now.Add(-5 * time.Hour), Until: now.Add(-4 * time.Hour), Minutes: 60}
+		long := &models.Task{UserID: busy.ID, Since: now.Add(-27 * time.Hour), Until: now.Add(-6 * time.Hour), Minutes: 1260}
+		running := &models.Task{UserID: other.ID, Since: now.Add(-90 * time.Minute)}
+		outside := &models.Task{UserID: idle.ID, Since: now.Add(-50 * time.Hour), Until: now.Add(-49 * time.Hour), Minutes: 60}
+		notMember := &models.Task{UserID: manager.ID, Since: now.Add(-2 * time.Hour), Until: now.Add(-time.Hour), Minutes: 60}
+		for _, task := range []*models.Task{short, long, running, outside, notMember} {
+			require.NoError(t, repo.CreateTask(ctx, task))
+		}
+
+		members, err := repo.TeamEfforts(ctx, team.ID, now.Add(-24*time.Hour), now.Add(time.Hour), now)
+		require.NoError(t, err)
+		require.Len(t, members, 3)
+
+		require.Equal(t, busy.ID, members[0].UserID)
+		require.Equal(t, "User 2", members[0].Name)
+		require.Equal(t, 19*time.Hour, members[0].Total)
+		require.Len(t, members[0].Tasks, 2)
+		require.Equal(t, long.ID, members[0].Tasks[0].TaskID)
+		require.Equal(t, 18*time.Hour, members[0].Tasks[0].Duration)
+		require.Equal(t, short.ID, members[0].Tasks[1].TaskID)
+		require.Equal(t, time.Hour, members[0].Tasks[1].Duration)
+		require.Equal(t, short.Until.Unix(), members[0].Tasks[1].Until.Unix())
+
+		require.Equal(t, other.ID, members[1].UserID)
+		require.Equal(t, 90*time.Minute, members[1].Total)
+		require.Len(t, members[1].Tasks, 1)
+		require.Equal(t, running.ID, members[1].Tasks[0].TaskID)
+		require.Equal(t, running.Since.Unix(), members[1].Tasks[0].Since.Unix())
+		require.True(t, members[1].Tasks[0].Until.IsZero())
+
+		require.Equal(t, idle.ID, members[2].UserID)
+		require.Zero(t, members[2].Total)
+		require.Empty(t, members[2].Tasks)
+
+		members, err = repo.TeamEfforts(ctx, -1, now.Add(-24*time.Hour), now, now)
+		require.NoError(t, err)
+		require.Empty(t, members)
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		require.NoError(t, repo.DeleteUser(ctx, manager))
+		require.NoError(t, repo.DeleteUser(ctx, busy))
+
+		got, err := repo.GetTeam(ctx, team.ID)
+		require.NoError(t, err)
+		require.Zero(t, got.ManagerID)
+		require.Equal(t, []int{idle.ID, other.ID}, got.MemberIDs)
+	})
+}
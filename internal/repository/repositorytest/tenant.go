@@ -0,0 +1,216 @@
+package repositorytest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func testTenantIsolation(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	acme := &models.Org{Name: "Acme"}
+	require.NoError(t, repo.CreateOrg(ctx, acme))
+	require.NotZero(t, acme.ID)
+	require.ErrorIs(t, repo.CreateOrg(ctx, &models.Org{Name: "Acme"}), models.ErrDuplicate)
+
+	orgs, err := repo.ListOrgs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []models.Org{{ID: models.DefaultOrgID, Name: "Default"}, *acme}, orgs)
+
+	got, err := repo.GetOrg(ctx, acme.ID)
+	require.NoError(t, err)
+	require.Equal(t, acme, got)
+	_, err = repo.GetOrg(ctx, -1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	other := tenant.WithOrg(context.Background(), acme.ID)
+	now := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+
+	// data of the default organization, which the other one tries to reach
+	passport := models.Passport{Series: "1234", Number: "567890"}
+	user := &models.User{Name: "Ivan", Passport: passport}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	pending := &models.User{Passport: models.Passport{Series: "1234", Number: "000000"}}
+	require.NoError(t, repo.CreateUser(ctx, pending))
+
+	project := &models.Project{Name: "Time tracker"}
+	require.NoError(t, repo.CreateProject(ctx, project))
+	tag := &models.Tag{Name: "bug"}
+	require.NoError(t, repo.CreateTag(ctx, tag))
+	team := &models.Team{Name: "Backend", ManagerID: pending.ID, MemberIDs: []int{user.ID}}
+	require.NoError(t, repo.CreateTeam(ctx, team))
+
+	task := &models.Task{UserID: user.ID, Title: "Secret", ProjectID: project.ID, Tags: []models.Tag{*tag}, Since: now.Add(-time.Hour)}
+	require.NoError(t, repo.CreateTask(ctx, task))
+
+	key := &models.APIKey{UserID: user.ID, Name: "laptop", Prefix: "tt_abcd", Hash: []byte("hash")}
+	require.NoError(t, repo.CreateAPIKey(ctx, key))
+	require.Equal(t, models.DefaultOrgID, key.OrgID)
+	require.NoError(t, repo.SetFeedToken(ctx, user.ID, []byte("feed")))
+
+	t.Run("Read", func(t *testing.T) {
+		_, err := repo.GetUser(other, user.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = repo.GetUserByPassport(other, passport)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		users, err := repo.ListUsers(other, models.UserListOpts{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Zero(t, users.Count)
+		require.Empty(t, users.Users)
+
+		pendingUsers, err := repo.ListPendingUsers(other, 10)
+		require.NoError(t, err)
+		require.Empty(t, pendingUsers)
+
+		_, err = repo.GetTask(other, user.ID, task.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		tasks, err := repo.ListTasks(other, user.ID, models.TaskListOpts{})
+		require.NoError(t, err)
+		require.Zero(t, tasks.Count)
+		require.Empty(t, tasks.Tasks)
+
+		_, err = repo.OverlappingTask(other, user.ID, 0, now.Add(-2*time.Hour), now, now)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		efforts, err := repo.TaskEfforts(other, user.ID, now.Add(-24*time.Hour), now, now)
+		require.NoError(t, err)
+		require.Empty(t, efforts)
+
+		count, err := repo.CountRunningTasks(other)
+		require.NoError(t, err)
+		require.Zero(t, count)
+
+		running, err := repo.ListRunningTasks(other, now)
+		require.NoError(t, err)
+		require.Empty(t, running)
+
+		err = repo.Timesheet(other, 0, now.Add(-24*time.Hour), now, now, func(row *models.TimesheetRow) error {
+			t.Errorf("row of another organization: %+v", row)
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, err = repo.GetProject(other, project.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		projects, err := repo.ListProjects(other)
+		require.NoError(t, err)
+		require.Empty(t, projects)
+
+		_, err = repo.GetTag(other, tag.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		tags, err := repo.ListTags(other)
+		require.NoError(t, err)
+		require.Empty(t, tags)
+
+		_, err = repo.GetTeam(other, team.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		teams, err := repo.ListTeams(other)
+		require.NoError(t, err)
+		require.Empty(t, teams)
+		members, err := repo.TeamEfforts(other, team.ID, now.Add(-24*time.Hour), now, now)
+		require.NoError(t, err)
+		require.Empty(t, members)
+
+		keys, err := repo.ListAPIKeys(other, user.ID)
+		require.NoError(t, err)
+		require.Empty(t, keys)
+		_, err = repo.GetFeedToken(other, user.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		log, err := repo.ListAuditEntries(other, models.AuditFilter{})
+		require.NoError(t, err)
+		require.Empty(t, log.Entries)
+		entries, err := repo.ListAuditEntriesAfter(other, 0, 10)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		require.ErrorIs(t, repo.UpdateUser(other, &models.User{ID: user.ID, Name: "Hacked", Passport: passport}), sql.ErrNoRows)
+		require.ErrorIs(t, repo.FailEnrichment(other, user.ID, models.EnrichFailed), sql.ErrNoRows)
+		require.ErrorIs(t, repo.DeleteUser(other, user), sql.ErrNoRows)
+
+		_, err := repo.SwitchTask(other, &models.Task{UserID: user.ID, Title: "Switch", Since: now})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.ErrorIs(t, repo.UpdateTask(other, &models.Task{ID: task.ID, UserID: user.ID, Title: "Hacked", Since: task.Since}, task.Revision()), sql.ErrNoRows)
+
+		stopped := *task
+		stopped.End(now)
+		require.ErrorIs(t, repo.AutoStopTask(other, &stopped), sql.ErrNoRows)
+
+		require.ErrorIs(t, repo.UpdateProject(other, &models.Project{ID: project.ID, Name: "Hacked"}), sql.ErrNoRows)
+		require.ErrorIs(t, repo.DeleteProject(other, project.ID), sql.ErrNoRows)
+		require.ErrorIs(t, repo.UpdateTag(other, &models.Tag{ID: tag.ID, Name: "hacked"}), sql.ErrNoRows)
+		require.ErrorIs(t, repo.DeleteTag(other, tag.ID), sql.ErrNoRows)
+		require.ErrorIs(t, repo.UpdateTeam(other, &models.Team{ID: team.ID, Name: "Hacked"}), sql.ErrNoRows)
+		require.ErrorIs(t, repo.DeleteTeam(other, team.ID), sql.ErrNoRows)
+
+		require.ErrorIs(t, repo.RevokeAPIKey(other, user.ID, key.ID), sql.ErrNoRows)
+		require.ErrorIs(t, repo.SetFeedToken(other, user.ID, nil), sql.ErrNoRows)
+
+		// nothing has changed in the default organization
+		u, err := repo.GetUser(ctx, user.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Ivan", u.Name)
+
+		stored, err := repo.GetTask(ctx, user.ID, task.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Secret", stored.Title)
+		require.Equal(t, models.TaskRunning, stored.State())
+		require.Equal(t, []models.Tag{*tag}, stored.Tags)
+		require.Equal(t, project.ID, stored.ProjectID)
+
+		storedTeam, err := repo.GetTeam(ctx, team.ID)
+		require.NoError(t, err)
+		require.Equal(t, team, storedTeam)
+
+		found, err := repo.GetAPIKeyByHash(ctx, []byte("hash"))
+		require.NoError(t, err)
+		require.Equal(t, key.ID, found.ID)
+
+		hash, err := repo.GetFeedToken(ctx, user.ID)
+		require.NoError(t, err)
+		require.Equal(t, []byte("feed"), hash)
+	})
+
+	t.Run("SameNames", func(t *testing.T) {
+		// names and passports are unique within an organization only
+		require.NoError(t, repo.CreateUser(other, &models.User{Passport: passport}))
+		require.NoError(t, repo.CreateProject(other, &models.Project{Name: project.Name}))
+		require.NoError(t, repo.CreateTag(other, &models.Tag{Name: tag.Name}))
+		require.NoError(t, repo.CreateTeam(other, &models.Team{Name: team.Name}))
+
+		u, err := repo.GetUserByPassport(ctx, passport)
+		require.NoError(t, err)
+		require.Equal(t, user.ID, u.ID)
+
+		projects, err := repo.ListProjects(ctx)
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+	})
+
+	t.Run("APIKey", func(t *testing.T) {
+		// keys are looked up before the organization is known and tell it
+		found, err := repo.GetAPIKeyByHash(context.Background(), []byte("hash"))
+		require.NoError(t, err)
+		require.Equal(t, models.DefaultOrgID, found.OrgID)
+		require.Equal(t, user.ID, found.UserID)
+	})
+
+	t.Run("MissingOrg", func(t *testing.T) {
+		_, err := repo.GetUser(context.Background(), user.ID)
+		require.ErrorIs(t, err, tenant.ErrMissing)
+		require.ErrorIs(t, repo.CreateUser(context.Background(), &models.User{}), tenant.ErrMissing)
+		_, err = repo.ListTasks(context.Background(), user.ID, models.TaskListOpts{})
+		require.ErrorIs(t, err, tenant.ErrMissing)
+	})
+}
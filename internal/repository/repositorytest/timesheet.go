@@ -0,0 +1,51 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func testTimesheet(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	petrov := &models.User{Passport: models.Passport{Series: "1000", Number: "000001"}, Surname: "Петров"}
+	require.NoError(t, repo.CreateUser(ctx, petrov))
+	ivanov := &models.User{Passport: models.Passport{Series: "1000", Number: "000002"}, Surname: "Иванов"}
+	require.NoError(t, repo.CreateUser(ctx, ivanov))
+
+	now := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+	for _, task := range []*models.Task{
+		{UserID: petrov.ID, Title: "Report", Since: now.Add(-3 * time.Hour), Until: now.Add(-time.Hour), Minutes: 120},
+		{UserID: ivanov.ID, Title: "Review", Since: now.Add(-5 * time.Hour), Until: now.Add(-4 * time.Hour), Minutes: 60},
+		{UserID: ivanov.ID, Title: "Old", Since: now.Add(-50 * time.Hour), Until: now.Add(-49 * time.Hour), Minutes: 60},
+		{UserID: ivanov.ID, Title: "Running", Since: now.Add(-30 * time.Minute)},
+	} {
+		require.NoError(t, repo.CreateTask(ctx, task))
+	}
+
+	collect := func(userID int) []models.TimesheetRow {
+		var rows []models.TimesheetRow
+		err := repo.Timesheet(ctx, userID, now.Add(-24*time.Hour), now.Add(-2*time.Hour), now, func(row *models.TimesheetRow) error {
+			rows = append(rows, *row)
+			return nil
+		})
+		require.NoError(t, err)
+		return rows
+	}
+
+	rows := collect(0)
+	require.Len(t, rows, 2)
+	require.Equal(t, "Иванов", rows[0].User.Surname)
+	require.Equal(t, "Review", rows[0].Title)
+	require.Equal(t, time.Hour, rows[0].Duration)
+	require.Equal(t, "Report", rows[1].Title)
+	require.Equal(t, time.Hour, rows[1].Duration) // clipped by the period
+
+	rows = collect(petrov.ID)
+	require.Len(t, rows, 1)
+	require.Equal(t, petrov.ID, rows[0].User.ID)
+}
@@ -0,0 +1,222 @@
+package repositorytest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+func testRepository(t *testing.T, repo usecase.Repository) {
+
+	user := &models.User{
+		Name:       "John",
+		Surname:    "Doe",
+		Patronymic: "Smith",
+		Address:    "Moscow",
+		Passport:   models.Passport{Series: "1234", Number: "567890"},
+	}
+
+	t.Run("CreateUser", func(t *testing.T) {
+		err := repo.CreateUser(background(), user)
+		require.NoError(t, err)
+		require.NotZero(t, user.ID)
+
+		t.Run("GetUser", func(t *testing.T) {
+			u, err := repo.GetUser(background(), user.ID)
+			require.NoError(t, err)
+			require.Equal(t, user.ID, u.ID)
+			require.Equal(t, user.Name, u.Name)
+			require.Equal(t, user.Surname, u.Surname)
+			require.Equal(t, user.Patronymic, u.Patronymic)
+			require.Equal(t, user.Address, u.Address)
+			require.Equal(t, user.Passport, u.Passport)
+		})
+	})
+
+	t.Run("ListPendingUsers", func(t *testing.T) {
+		pending := &models.User{Passport: models.Passport{Series: "0432", Number: "098765"}, EnrichStatus: models.EnrichPending}
+		require.NoError(t, repo.CreateUser(background(), pending))
+		// users created without the name service are not pending
+		unnamed := &models.User{Passport: models.Passport{Series: "0433", Number: "098765"}}
+		require.NoError(t, repo.CreateUser(background(), unnamed))
+		require.Equal(t, models.EnrichDone, unnamed.EnrichStatus)
+
+		users, err := repo.ListPendingUsers(background(), 10)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		require.Equal(t, pending.ID, users[0].ID)
+		require.Equal(t, models.EnrichPending, users[0].EnrichStatus)
+
+		t.Run("FailEnrichment", func(t *testing.T) {
+			require.NoError(t, repo.FailEnrichment(background(), pending.ID, models.EnrichPending))
+
+			u, err := repo.GetUser(background(), pending.ID)
+			require.NoError(t, err)
+			require.Equal(t, models.EnrichPending, u.EnrichStatus)
+			require.Equal(t, 1, u.EnrichAttempts)
+
+			// updates keep the attempts
+			require.NoError(t, repo.UpdateUser(background(), u))
+			u, err = repo.GetUser(background(), pending.ID)
+			require.NoError(t, err)
+			require.Equal(t, 1, u.EnrichAttempts)
+
+			require.NoError(t, repo.FailEnrichment(background(), pending.ID, models.EnrichNotFound))
+			users, err := repo.ListPendingUsers(background(), 10)
+			require.NoError(t, err)
+			require.Empty(t, users)
+
+			require.ErrorIs(t, repo.FailEnrichment(background(), 1_000_000, models.EnrichFailed), sql.ErrNoRows)
+		})
+	})
+
+	t.Run("ListUsers", func(t *testing.T) {
+		list, err := repo.ListUsers(background(), models.UserListOpts{Page: 1, Limit: 10, Name: "john doe"})
+		require.NoError(t, err)
+		require.Equal(t, 1, list.Count)
+		require.Equal(t, 1, list.Pages)
+		require.Len(t, list.Users, 1)
+		require.Equal(t, user.ID, list.Users[0].ID)
+
+		list, err = repo.ListUsers(background(), models.UserListOpts{Page: 1, Limit: 1, Passport: models.Passport{Series: "0432", Number: "098765"}})
+		require.NoError(t, err)
+		require.Equal(t, 1, list.Count)
+		require.NotEqual(t, user.ID, list.Users[0].ID)
+
+		list, err = repo.ListUsers(background(), models.UserListOpts{Page: 1, Limit: 10, Address: "nowhere"})
+		require.NoError(t, err)
+		require.Zero(t, list.Count)
+		require.Empty(t, list.Users)
+	})
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		user.Address = "Saint Petersburg"
+		require.NoError(t, repo.UpdateUser(background(), user))
+
+		u, err := repo.GetUser(background(), user.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Saint Petersburg", u.Address)
+	})
+
+	t.Run("CreateTask", func(t *testing.T) {
+		task := &models.Task{
+			UserID:  user.ID,
+			Since:   time.Now().Add(-time.Hour),
+			Until:   time.Now(),
+			Minutes: 60,
+		}
+		err := repo.CreateTask(background(), task)
+		require.NoError(t, err)
+		require.NotZero(t, task.ID)
+
+		t.Run("UpdateTask", func(t *testing.T) {
+			task.Minutes = 120
+			err := repo.UpdateTask(background(), task, task.Revision())
+			require.NoError(t, err)
+		})
+
+		t.Run("GetTask", func(t *testing.T) {
+			result, err := repo.GetTask(background(), user.ID, task.ID)
+			require.NoError(t, err)
+			require.Equal(t, task.ID, result.ID)
+			require.Equal(t, task.UserID, result.UserID)
+			require.Equal(t, task.Since.Unix(), result.Since.Unix())
+			require.Equal(t, task.Until.Unix(), result.Until.Unix())
+			require.Equal(t, 120, result.Minutes)
+		})
+
+		t.Run("ListTasks", func(t *testing.T) {
+			list, err := repo.ListTasks(background(), user.ID, models.TaskListOpts{})
+			require.NoError(t, err)
+			tasks := list.Tasks
+
+			require.Len(t, tasks, 1)
+			require.Equal(t, task.ID, tasks[0].ID)
+			require.Equal(t, task.UserID, tasks[0].UserID)
+			require.Equal(t, task.Since.Unix(), tasks[0].Since.Unix())
+			require.Equal(t, task.Until.Unix(), tasks[0].Until.Unix())
+			require.Equal(t, 120, tasks[0].Minutes)
+		})
+	})
+
+	t.Run("TaskEfforts", func(t *testing.T) {
+		worker := &models.User{Passport: models.Passport{Series: "1111", Number: "111111"}}
+		require.NoError(t, repo.CreateUser(background(), worker))
+
+		now := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+		short := &models.Task{UserID: worker.ID, Since: now.Add(-5 * time.Hour), Until: now.Add(-4 * time.Hour), Minutes: 60}
+		long := &models.Task{UserID: worker.ID, Since: now.Add(-27 * time.Hour), Until: now.Add(-6 * time.Hour), Minutes: 1260}
+		running := &models.Task{UserID: worker.ID, Since: now.Add(-90 * time.Minute)}
+		outside := &models.Task{UserID: worker.ID, Since: now.Add(-50 * time.Hour), Until: now.Add(-49 * time.Hour), Minutes: 60}
+		for _, task := range []*models.Task{short, long, running, outside} {
+			require.NoError(t, repo.CreateTask(background(), task))
+		}
+
+		efforts, err := repo.TaskEfforts(background(), worker.ID, now.Add(-24*time.Hour), now.Add(time.Hour), now)
+		require.NoError(t, err)
+		require.Len(t, efforts, 3)
+
+		require.Equal(t, long.ID, efforts[0].TaskID)
+		require.Equal(t, 18*time.Hour, efforts[0].Duration)
+		require.Equal(t, running.ID, efforts[1].TaskID)
+		require.Equal(t, 90*time.Minute, efforts[1].Duration)
+		require.True(t, efforts[1].Until.IsZero())
+		require.Equal(t, short.ID, efforts[2].TaskID)
+		require.Equal(t, time.Hour, efforts[2].Duration)
+
+		task, err := repo.GetTask(background(), worker.ID, running.ID)
+		require.NoError(t, err)
+		require.True(t, task.Until.IsZero())
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		require.NoError(t, repo.DeleteUser(background(), user))
+
+		_, err := repo.GetUser(background(), user.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		err = repo.DeleteUser(background(), user)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func testPassportUniqueness(t *testing.T, repo usecase.Repository) {
+	ctx := background()
+
+	// leading zeros are significant
+	first := &models.User{Passport: models.Passport{Series: "0012", Number: "000001"}}
+	require.NoError(t, repo.CreateUser(ctx, first))
+	second := &models.User{Passport: models.Passport{Series: "0012", Number: "000010"}}
+	require.NoError(t, repo.CreateUser(ctx, second))
+
+	u, err := repo.GetUserByPassport(ctx, first.Passport)
+	require.NoError(t, err)
+	require.Equal(t, first.ID, u.ID)
+	require.Equal(t, models.Passport{Series: "0012", Number: "000001"}, u.Passport)
+
+	_, err = repo.GetUserByPassport(ctx, models.Passport{Series: "0012", Number: "000100"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	duplicate := &models.User{Passport: first.Passport}
+	require.ErrorIs(t, repo.CreateUser(ctx, duplicate), models.ErrDuplicate)
+
+	second.Passport = first.Passport
+	require.ErrorIs(t, repo.UpdateUser(ctx, second), models.ErrDuplicate)
+
+	u, err = repo.GetUser(ctx, second.ID)
+	require.NoError(t, err)
+	require.Equal(t, "000010", u.Passport.Number)
+
+	// users keep their own passport on update
+	first.Name = "Ivan"
+	require.NoError(t, repo.UpdateUser(ctx, first))
+
+	list, err := repo.ListUsers(ctx, models.UserListOpts{Page: 1, Limit: 10, Passport: models.Passport{Series: "0012", Number: "000010"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, list.Count)
+	require.Equal(t, second.ID, list.Users[0].ID)
+}
@@ -0,0 +1,196 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/audit"
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/repository/cursor"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	goqu "github.com/doug-martin/goqu/v9"
+)
+
+// ListAuditEntries returns a page of the audit log matching the filter, the newest entries first.
+func (r *Repository) ListAuditEntries(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	qb := dialect.From("audit_log").Where(goqu.C("org_id").Eq(orgID))
+
+	if filter.Entity != "" {
+		qb = qb.Where(goqu.C("entity").Eq(filter.Entity))
+	}
+	if filter.EntityID != 0 {
+		qb = qb.Where(goqu.C("entity_id").Eq(filter.EntityID))
+	}
+	if filter.ActorID != 0 {
+		qb = qb.Where(goqu.C("actor_id").Eq(filter.ActorID))
+	}
+	if !filter.From.IsZero() {
+		qb = qb.Where(goqu.C("created_at").Gte(filter.From.UnixMicro()))
+	}
+	if !filter.To.IsZero() {
+		qb = qb.Where(goqu.C("created_at").Lt(filter.To.UnixMicro()))
+	}
+	if filter.Cursor != "" {
+		c, err := cursor.DecodeAudit(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		qb = qb.Where(goqu.C("id").Lt(c.ID))
+	}
+
+	qb = qb.Order(goqu.C("id").Desc())
+	if filter.Limit > 0 {
+		// one more entry tells whether there is a next page
+		qb = qb.Limit(uint(filter.Limit + 1))
+	}
+
+	query, args, err := qb.Select(goqu.L(auditColumns)).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("build select query: %w", err)
+	}
+
+	logging.FromContext(ctx).Debug("list query", "query", query, "args", args, "repository", "audit_log")
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "audit_log")
+		}
+	}()
+
+	log := &models.AuditLog{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Entries = append(log.Entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit > 0 && len(log.Entries) > filter.Limit {
+		log.Entries = log.Entries[:filter.Limit]
+		log.NextCursor = cursor.EncodeAudit(&log.Entries[filter.Limit-1])
+	}
+
+	return log, nil
+}
+
+// ListAuditEntriesAfter returns up to limit entries with IDs greater than afterID, the oldest first.
+func (r *Repository) ListAuditEntriesAfter(ctx context.Context, afterID, limit int) ([]models.AuditEntry, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log
+		WHERE org_id = ? AND id > ?
+		ORDER BY id
+		LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "audit_log")
+		}
+	}()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, *entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// auditColumns are selected by ListAuditEntries and ListAuditEntriesAfter.
+const auditColumns = `id, created_at, actor_id, actor_role, action, entity, entity_id, before, after, request_id`
+
+func scanAuditEntry(rows *sql.Rows) (*models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var createdAt int64
+	var actorID sql.NullInt64
+	var before, after sql.NullString
+
+	err := rows.Scan(&entry.ID, &createdAt, &actorID, &entry.ActorRole, &entry.Action, &entry.Entity, &entry.EntityID, &before, &after, &entry.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	entry.CreatedAt = time.UnixMicro(createdAt)
+	entry.ActorID = int(actorID.Int64)
+	if before.Valid {
+		entry.Before = []byte(before.String)
+	}
+	if after.Valid {
+		entry.After = []byte(after.String)
+	}
+
+	return &entry, nil
+}
+
+// auditUser appends the change of the user to the audit log in the transaction of the change.
+func auditUser(ctx context.Context, tx *sql.Tx, action models.AuditAction, before, after *models.User) error {
+	entry, err := audit.UserEntry(ctx, action, before, after)
+	if err != nil {
+		return fmt.Errorf("audit entry: %w", err)
+	}
+
+	return insertAuditEntry(ctx, tx, entry)
+}
+
+// auditTask appends the change of the task to the audit log in the transaction of the change.
+func auditTask(ctx context.Context, tx *sql.Tx, action models.AuditAction, before, after *models.Task) error {
+	entry, err := audit.TaskEntry(ctx, action, before, after)
+	if err != nil {
+		return fmt.Errorf("audit entry: %w", err)
+	}
+
+	return insertAuditEntry(ctx, tx, entry)
+}
+
+// insertAuditEntry appends the entry to the log of the organization of the context.
+func insertAuditEntry(ctx context.Context, tx *sql.Tx, entry *models.AuditEntry) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO audit_log (created_at, actor_id, actor_role, action, entity, entity_id, before, after, request_id, org_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`
+
+	row := tx.QueryRowContext(ctx, query, micro(entry.CreatedAt), nullInt(entry.ActorID), entry.ActorRole, entry.Action, entry.Entity, entry.EntityID,
+		nullJSON(entry.Before), nullJSON(entry.After), entry.RequestID, orgID)
+
+	return row.Scan(&entry.ID)
+}
+
+// nullJSON converts missing snapshots to NULL.
+func nullJSON(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	return string(b)
+}
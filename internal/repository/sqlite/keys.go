@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+// CreateAPIKey stores the key of the user in the organization of the context.
+func (r *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+	key.OrgID = orgID
+
+	query := `INSERT INTO api_keys (user_id, name, prefix, hash, created_at, org_id) VALUES (?, ?, ?, ?, ?, ?) RETURNING id`
+
+	createdAt := time.Now()
+	row := r.db.QueryRowContext(ctx, query, key.UserID, key.Name, key.Prefix, key.Hash, micro(createdAt), key.OrgID)
+	if err := row.Scan(&key.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+	key.CreatedAt = createdAt
+
+	return nil
+}
+
+// GetAPIKeyByHash returns the active key with the given hash. Keys of all organizations are searched,
+// the organization of the key is the one its requests act within.
+func (r *Repository) GetAPIKeyByHash(ctx context.Context, hash []byte) (*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE hash = ? AND revoked_at IS NULL`
+
+	return scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = ? AND org_id = ? ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "api_keys")
+		}
+	}()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes the active key of the user, sql.ErrNoRows is returned if there is no such key.
+func (r *Repository) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND user_id = ? AND org_id = ? AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, micro(time.Now()), id, userID, orgID)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(result)
+}
+
+// apiKeyColumns are selected by scanAPIKey.
+const apiKeyColumns = `id, user_id, org_id, name, prefix, hash, created_at, revoked_at`
+
+func scanAPIKey(s scanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var createdAt int64
+	var revokedAt sql.NullInt64
+
+	if err := s.Scan(&key.ID, &key.UserID, &key.OrgID, &key.Name, &key.Prefix, &key.Hash, &createdAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	key.CreatedAt = time.UnixMicro(createdAt)
+	key.RevokedAt = fromMicro(revokedAt)
+
+	return &key, nil
+}
+
+// SetFeedToken replaces the calendar feed token of the user, nil hash removes it.
+// sql.ErrNoRows is returned if there is no such user.
+func (r *Repository) SetFeedToken(ctx context.Context, userID int, hash []byte) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE users SET feed_token_hash = ? WHERE id = ? AND org_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, hash, userID, orgID)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(result)
+}
+
+// GetFeedToken returns hash of the calendar feed token of the user,
+// sql.ErrNoRows is returned if there is no such user or the user has no token.
+func (r *Repository) GetFeedToken(ctx context.Context, userID int) ([]byte, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT feed_token_hash FROM users WHERE id = ? AND org_id = ? AND feed_token_hash IS NOT NULL`
+
+	var hash []byte
+	if err := r.db.QueryRowContext(ctx, query, userID, orgID).Scan(&hash); err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+)
+
+// lockLease bounds how long a lock left by a crashed process blocks others.
+const lockLease = 10 * time.Minute
+
+// WithLock runs fn holding the lease of the name, so only one process sharing the database file runs it at a time.
+// It returns false without running fn if another process holds an unexpired lease.
+// SQLite has no advisory locks, so leases are rows of the locks table.
+func (r *Repository) WithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	now := time.Now()
+	expires := micro(now.Add(lockLease))
+
+	query := `INSERT INTO locks (name, expires_at) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET expires_at = excluded.expires_at WHERE locks.expires_at < ?`
+
+	result, err := r.db.ExecContext(ctx, query, name, expires, micro(now))
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	defer func() {
+		// the lease is only released if it's still ours
+		query := `DELETE FROM locks WHERE name = ? AND expires_at = ?`
+		if _, err := r.db.ExecContext(context.WithoutCancel(ctx), query, name, expires); err != nil {
+			logging.FromContext(ctx).Warn("Failed to release lock", "lock", name, "error", err)
+		}
+	}()
+
+	return true, fn(ctx)
+}
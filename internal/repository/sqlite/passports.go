@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// passportColumns are scanned into storedPassport.
+const passportColumns = `passport_serie, passport_number, passport_encrypted, passport_index`
+
+// passportBatch is the number of users EncryptPassports updates in a transaction.
+const passportBatch = 100
+
+// storedPassport is a passport as it is stored, plaintext is only set until the passport is encrypted.
+type storedPassport struct {
+	series, number   string
+	encrypted, index []byte
+}
+
+func (r *Repository) decryptPassport(p storedPassport) (models.Passport, error) {
+	if p.encrypted == nil {
+		return models.Passport{Series: p.series, Number: p.number}, nil
+	}
+
+	passport, err := r.keys.DecryptPassport(p.encrypted, p.index)
+	if err != nil {
+		return models.Passport{}, fmt.Errorf("decrypt passport: %w", err)
+	}
+	return passport, nil
+}
+
+// EncryptPassports encrypts passports stored in plaintext and rewraps the ones encrypted with old keys
+// with the current key. It is called on start for each organization and returns the number of updated users.
+func (r *Repository) EncryptPassports(ctx context.Context) (int, error) {
+	var updated, lastID int
+	for {
+		n, last, err := r.encryptPassports(ctx, lastID)
+		updated += n
+		if err != nil || n < passportBatch {
+			return updated, err
+		}
+		lastID = last
+	}
+}
+
+// encryptPassports updates a batch of users after the ID and returns ID of the last one.
+// Transactions are immediate, so users can't change until the batch is updated.
+func (r *Repository) encryptPassports(ctx context.Context, afterID int) (n, lastID int, err error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT id, ` + passportColumns + ` FROM users
+		WHERE org_id = ? AND id > ? AND passport_key IS NOT ?
+		ORDER BY id
+		LIMIT ?`
+
+	rows, err := tx.QueryContext(ctx, query, orgID, afterID, r.keys.CurrentKeyID(), passportBatch)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "users")
+		}
+	}()
+
+	type user struct {
+		id       int
+		passport storedPassport
+	}
+	var users []user
+	for rows.Next() {
+		var u user
+		if err := rows.Scan(&u.id, &u.passport.series, &u.passport.number, &u.passport.encrypted, &u.passport.index); err != nil {
+			return 0, 0, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	query = `UPDATE users
+		SET passport_serie = '',
+		passport_number = '',
+		passport_encrypted = ?,
+		passport_index = ?,
+		passport_key = ?
+		WHERE id = ? AND org_id = ?`
+
+	for _, u := range users {
+		encrypted, index := u.passport.encrypted, u.passport.index
+		if encrypted == nil {
+			encrypted, index, err = r.keys.EncryptPassport(models.Passport{Series: u.passport.series, Number: u.passport.number})
+		} else {
+			encrypted, err = r.keys.Rewrap(encrypted)
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("encrypt passport of user %d: %w", u.id, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, encrypted, index, r.keys.CurrentKeyID(), u.id, orgID); err != nil {
+			return 0, 0, err
+		}
+		lastID = u.id
+	}
+
+	return len(users), lastID, tx.Commit()
+}
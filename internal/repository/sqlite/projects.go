@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+func (r *Repository) CreateProject(ctx context.Context, project *models.Project) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO projects (name, description, org_id) VALUES (?, ?, ?) RETURNING id`
+
+	row := r.db.QueryRowContext(ctx, query, project.Name, project.Description, orgID)
+	if err := row.Scan(&project.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT name, description FROM projects WHERE id = ? AND org_id = ?`
+
+	project := &models.Project{ID: id}
+	if err := r.db.QueryRowContext(ctx, query, id, orgID).Scan(&project.Name, &project.Description); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (r *Repository) ListProjects(ctx context.Context) ([]models.Project, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, name, description FROM projects WHERE org_id = ? ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "projects")
+		}
+	}()
+
+	var projects []models.Project
+	for rows.Next() {
+		var project models.Project
+		if err := rows.Scan(&project.ID, &project.Name, &project.Description); err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
+}
+
+func (r *Repository) UpdateProject(ctx context.Context, project *models.Project) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE projects SET name = ?, description = ? WHERE id = ? AND org_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, project.Name, project.Description, project.ID, orgID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	return checkAffected(result)
+}
+
+func (r *Repository) DeleteProject(ctx context.Context, id int) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM projects WHERE id = ? AND org_id = ?`, id, orgID)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(result)
+}
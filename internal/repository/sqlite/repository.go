@@ -0,0 +1,991 @@
+// Package sqlite implements usecase.Repository on an embedded SQLite database.
+// Times are stored as microseconds since the Unix epoch, the precision of PostgreSQL timestamps.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/encryption"
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/repository/cursor"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	goqu "github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/sqlite3"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+func init() {
+	// LIKE and lower() of SQLite fold ASCII letters only, casefold folds any letters for case-insensitive search
+	sqlite.MustRegisterDeterministicScalarFunction("casefold", 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return args[0], nil
+		}
+		return strings.ToLower(s), nil
+	})
+}
+
+var dialect = goqu.Dialect("sqlite3")
+
+type Repository struct {
+	db   *sql.DB
+	keys *encryption.Keyring // encrypts passports
+}
+
+func New(db *sql.DB, keys *encryption.Keyring) *Repository {
+	return &Repository{db: db, keys: keys}
+}
+
+func (r *Repository) CreateUser(ctx context.Context, user *models.User) error {
+	if user.Role == "" {
+		user.Role = models.RoleEmployee
+	}
+	if user.EnrichStatus == "" {
+		user.EnrichStatus = models.EnrichDone
+	}
+
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	encrypted, index, err := r.keys.EncryptPassport(user.Passport)
+	if err != nil {
+		return fmt.Errorf("encrypt passport: %w", err)
+	}
+
+	query := `INSERT INTO users (name, surname, patronymic, address, passport_encrypted, passport_index, passport_key, role, manager_id, org_id, enrich_status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`
+
+	row := tx.QueryRowContext(ctx, query, user.Name, user.Surname, user.Patronymic, user.Address, encrypted, index, r.keys.CurrentKeyID(), user.Role, nullInt(user.ManagerID), orgID, user.EnrichStatus)
+	if err := row.Scan(&user.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	if err := auditUser(ctx, tx, models.AuditCreate, nil, user); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetUser(ctx context.Context, id int) (*models.User, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ? AND org_id = ?`
+
+	return r.scanUser(r.db.QueryRowContext(ctx, query, id, orgID))
+}
+
+// GetUserByPassport returns the user with the passport.
+func (r *Repository) GetUserByPassport(ctx context.Context, passport models.Passport) (*models.User, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE org_id = ? AND passport_index = ?`
+
+	return r.scanUser(r.db.QueryRowContext(ctx, query, orgID, r.keys.PassportIndex(passport)))
+}
+
+func (r *Repository) DeleteUser(ctx context.Context, user *models.User) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	before, err := r.getUser(ctx, tx, orgID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// tasks are deleted with the user, so their deletion is recorded too
+	tasks, err := getUserTasks(ctx, tx, orgID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = ? AND org_id = ?`, user.ID, orgID); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if err := auditTask(ctx, tx, models.AuditDelete, task, nil); err != nil {
+			return err
+		}
+	}
+	if err := auditUser(ctx, tx, models.AuditDelete, before, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// getUserTasks returns tasks of the user ordered by ID within the transaction.
+func getUserTasks(ctx context.Context, tx *sql.Tx, orgID, userID int) ([]*models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.user_id = ? AND t.org_id = ? ORDER BY t.id`
+
+	rows, err := tx.QueryContext(ctx, query, userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "users")
+		}
+	}()
+
+	var tasks []*models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (r *Repository) UpdateUser(ctx context.Context, user *models.User) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	before, err := r.getUser(ctx, tx, orgID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	encrypted, index, err := r.keys.EncryptPassport(user.Passport)
+	if err != nil {
+		return fmt.Errorf("encrypt passport: %w", err)
+	}
+
+	query := `UPDATE users
+		SET name = ?,
+		surname = ?,
+		patronymic = ?,
+		address = ?,
+		passport_serie = '',
+		passport_number = '',
+		passport_encrypted = ?,
+		passport_index = ?,
+		passport_key = ?,
+		role = ?,
+		manager_id = ?,
+		enrich_status = COALESCE(NULLIF(?, ''), enrich_status)
+		WHERE id = ? AND org_id = ?`
+
+	_, err = tx.ExecContext(ctx, query, user.Name, user.Surname, user.Patronymic, user.Address, encrypted, index, r.keys.CurrentKeyID(), user.Role, nullInt(user.ManagerID), user.EnrichStatus, user.ID, orgID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	if err := auditUser(ctx, tx, models.AuditUpdate, before, user); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// getUser returns the user within the transaction, transactions are immediate so it can't change until the end.
+func (r *Repository) getUser(ctx context.Context, tx *sql.Tx, orgID, id int) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ? AND org_id = ?`
+
+	return r.scanUser(tx.QueryRowContext(ctx, query, id, orgID))
+}
+
+func (r *Repository) ListUsers(ctx context.Context, opts models.UserListOpts) (*models.UserList, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	qb := dialect.From("users").Where(goqu.C("org_id").Eq(orgID))
+
+	if opts.Name != "" {
+		qb = qb.Where(contains("name || ' ' || surname || ' ' || patronymic", opts.Name))
+	}
+	if opts.FirstName != "" {
+		qb = qb.Where(contains("name", opts.FirstName))
+	}
+	if opts.Surname != "" {
+		qb = qb.Where(contains("surname", opts.Surname))
+	}
+	if opts.Patronymic != "" {
+		qb = qb.Where(contains("patronymic", opts.Patronymic))
+	}
+	if opts.Address != "" {
+		qb = qb.Where(contains("address", opts.Address))
+	}
+	if !opts.Passport.IsZero() {
+		// queries are interpolated, bytes are passed as hex to survive it
+		qb = qb.Where(goqu.L("passport_index = unhex(?)", hex.EncodeToString(r.keys.PassportIndex(opts.Passport))))
+	}
+
+	// get total count
+	countQuery, countArgs, err := qb.Select(goqu.L("COUNT(*)")).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("build count query: %w", err)
+	}
+	logging.FromContext(ctx).Debug("list count query", "query", countQuery, "repository", "users")
+	var count int
+	if err := r.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&count); err != nil {
+		return nil, fmt.Errorf("count: %w", err)
+	}
+
+	userList := models.UserList{
+		Users: make([]models.User, 0, opts.Limit),
+		Count: count,
+		Pages: count / opts.Limit,
+		Page:  opts.Page,
+	}
+
+	// calculate offset and fix pages if needed
+	offset := (opts.Page - 1) * opts.Limit
+	if offset < 0 {
+		offset = 0
+		userList.Page = 1
+	}
+	if count%opts.Limit > 0 {
+		userList.Pages++
+	}
+	if userList.Pages == 0 {
+		userList.Page = 0
+		return &userList, nil
+	}
+
+	// get data
+	selectQuery, selectArgs, err := qb.
+		Select(goqu.L(userColumns)).
+		Order(goqu.C("id").Asc()).
+		Offset(uint(offset)).
+		Limit(uint(opts.Limit)).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("build select query: %w", err)
+	}
+
+	logging.FromContext(ctx).Debug("list query", "query", selectQuery, "args", selectArgs, "repository", "users")
+
+	rows, err := r.db.QueryContext(ctx, selectQuery, selectArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "users")
+		}
+	}()
+
+	for rows.Next() {
+		user, err := r.scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		userList.Users = append(userList.Users, *user)
+	}
+
+	return &userList, rows.Err()
+}
+
+// ListPendingUsers returns users waiting for data from the name service, the ones tried less often first.
+func (r *Repository) ListPendingUsers(ctx context.Context, limit int) ([]models.User, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users
+		WHERE org_id = ? AND enrich_status = 'pending'
+		ORDER BY enrich_attempts, id
+		LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "users")
+		}
+	}()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := r.scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
+}
+
+// FailEnrichment counts a failed attempt to fill the user from the name service and sets the status,
+// pending users are retried. sql.ErrNoRows is returned if there is no such user.
+func (r *Repository) FailEnrichment(ctx context.Context, id int, status models.EnrichStatus) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE users SET enrich_status = ?, enrich_attempts = enrich_attempts + 1 WHERE id = ? AND org_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, status, id, orgID)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(result)
+}
+
+// contains matches rows where the SQL expression contains substr ignoring case.
+func contains(expr, substr string) goqu.Expression {
+	return goqu.L("instr(casefold("+expr+"), casefold(?)) > 0", substr)
+}
+
+// userColumns are selected by scanUser.
+const userColumns = `id, name, surname, patronymic, address, ` + passportColumns + `, role, manager_id, enrich_status, enrich_attempts`
+
+func (r *Repository) scanUser(s scanner) (*models.User, error) {
+	var user models.User
+	var passport storedPassport
+	var managerID sql.NullInt64
+
+	err := s.Scan(&user.ID, &user.Name, &user.Surname, &user.Patronymic, &user.Address,
+		&passport.series, &passport.number, &passport.encrypted, &passport.index, &user.Role, &managerID,
+		&user.EnrichStatus, &user.EnrichAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if user.Passport, err = r.decryptPassport(passport); err != nil {
+		return nil, err
+	}
+	user.ManagerID = int(managerID.Int64)
+
+	return &user, nil
+}
+
+// taskColumns are selected by scanTask, tasks table must be aliased as t.
+const taskColumns = `t.id, t.user_id, t.title, t.description, t.project_id, t.start_time, t.end_time, t.minutes, t.auto_stopped,
+	(SELECT json_group_array(json_object('id', id, 'name', name)) FROM
+		(SELECT tg.id, tg.name FROM task_tags tt JOIN tags tg ON tg.id = tt.tag_id WHERE tt.task_id = t.id ORDER BY tg.name)),
+	(SELECT json_group_array(json_object('since', start_time, 'until', end_time)) FROM
+		(SELECT ts.start_time, ts.end_time FROM task_segments ts WHERE ts.task_id = t.id ORDER BY ts.start_time))`
+
+func (r *Repository) CreateTask(ctx context.Context, task *models.Task) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	if err := insertTask(ctx, tx, orgID, task); err != nil {
+		return err
+	}
+
+	if err := auditTask(ctx, tx, models.AuditCreate, nil, task); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SwitchTask atomically ends the running task of the user and creates the new one.
+// The running task is ended at the start time of the new task and returned, nil if there was none.
+func (r *Repository) SwitchTask(ctx context.Context, task *models.Task) (*models.Task, error) {
+	// transactions are immediate, so concurrent switches are serialized by the database lock
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	var userID int
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = ? AND org_id = ?`, task.UserID, orgID).Scan(&userID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.user_id = ? AND t.org_id = ? AND t.end_time IS NULL`
+
+	running, err := scanTask(tx.QueryRowContext(ctx, query, task.UserID, orgID))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		running = nil
+	case err != nil:
+		return nil, err
+	default:
+		before := *running
+		before.Segments = slices.Clone(running.Segments)
+		running.End(task.Since)
+
+		query := `UPDATE tasks SET end_time = ?, minutes = ? WHERE id = ? AND org_id = ? AND end_time IS NULL`
+		if _, err := tx.ExecContext(ctx, query, micro(running.Until), running.Minutes, running.ID, orgID); err != nil {
+			return nil, err
+		}
+
+		query = `UPDATE task_segments SET end_time = ? WHERE task_id = ? AND end_time IS NULL`
+		if _, err := tx.ExecContext(ctx, query, micro(running.Until), running.ID); err != nil {
+			return nil, err
+		}
+
+		if err := auditTask(ctx, tx, models.AuditUpdate, &before, running); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := insertTask(ctx, tx, orgID, task); err != nil {
+		return nil, err
+	}
+
+	if err := auditTask(ctx, tx, models.AuditCreate, nil, task); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return running, nil
+}
+
+func (r *Repository) GetTask(ctx context.Context, userID, taskID int) (*models.Task, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.user_id = ? AND t.id = ? AND t.org_id = ?`
+
+	return scanTask(r.db.QueryRowContext(ctx, query, userID, taskID, orgID))
+}
+
+// OverlappingTask returns ID of a task of the user other than excludeID overlapping [since, until).
+// Running tasks last up to now. sql.ErrNoRows is returned if there is no such task.
+func (r *Repository) OverlappingTask(ctx context.Context, userID, excludeID int, since, until, now time.Time) (int, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `SELECT id FROM tasks
+		WHERE org_id = ? AND user_id = ? AND id <> ? AND start_time < ? AND COALESCE(end_time, ?) > ?
+		ORDER BY start_time
+		LIMIT 1`
+
+	var id int
+	if err := r.db.QueryRowContext(ctx, query, orgID, userID, excludeID, micro(until), micro(now), micro(since)).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateTask stores the task if it's still at the revision read, models.ErrStale is returned otherwise.
+func (r *Repository) UpdateTask(ctx context.Context, task *models.Task, read models.TaskRevision) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	before, err := getTask(ctx, tx, orgID, task.ID)
+	if err != nil {
+		return err
+	}
+	if before.Revision() != read {
+		return models.ErrStale
+	}
+
+	query := `UPDATE tasks
+		SET title = ?, description = ?, project_id = ?, start_time = ?, end_time = ?, minutes = ?, auto_stopped = ?
+		WHERE id = ?`
+
+	_, err = tx.ExecContext(ctx, query, task.Title, task.Description, nullInt(task.ProjectID), micro(task.Since), micro(task.Until), task.Minutes, task.AutoStopped, task.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = ?`, task.ID); err != nil {
+		return err
+	}
+
+	if err := setTaskTags(ctx, tx, task); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_segments WHERE task_id = ?`, task.ID); err != nil {
+		return err
+	}
+
+	if err := insertTaskSegments(ctx, tx, task); err != nil {
+		return err
+	}
+
+	if err := auditTask(ctx, tx, models.AuditUpdate, before, task); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// getTask returns the task within the transaction, transactions are immediate so it can't change until the end.
+func getTask(ctx context.Context, tx *sql.Tx, orgID, id int) (*models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.id = ? AND t.org_id = ?`
+
+	return scanTask(tx.QueryRowContext(ctx, query, id, orgID))
+}
+
+// taskSortKeys are SQL expressions tasks are sorted by, their values are cursor.Task keys.
+var taskSortKeys = map[models.TaskSort]string{
+	models.TaskSortID:      "t.id",
+	models.TaskSortSince:   "t.start_time",
+	models.TaskSortUntil:   "COALESCE(t.end_time, 9223372036854775807)",
+	models.TaskSortMinutes: "t.minutes",
+}
+
+// ListTasks returns a page of tasks of the user matching the filter and the total number of such tasks.
+func (r *Repository) ListTasks(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	qb := dialect.From(goqu.T("tasks").As("t")).
+		Where(goqu.I("t.org_id").Eq(orgID), goqu.I("t.user_id").Eq(userID))
+
+	if opts.Title != "" {
+		qb = qb.Where(contains("t.title", opts.Title))
+	}
+	if opts.ProjectID != 0 {
+		qb = qb.Where(goqu.I("t.project_id").Eq(opts.ProjectID))
+	}
+	if opts.TagID != 0 {
+		qb = qb.Where(goqu.L("EXISTS (SELECT 1 FROM task_tags ft WHERE ft.task_id = t.id AND ft.tag_id = ?)", opts.TagID))
+	}
+	if !opts.From.IsZero() {
+		qb = qb.Where(goqu.Or(goqu.I("t.end_time").IsNull(), goqu.I("t.end_time").Gt(opts.From.UnixMicro())))
+	}
+	if !opts.To.IsZero() {
+		qb = qb.Where(goqu.I("t.start_time").Lt(opts.To.UnixMicro()))
+	}
+
+	const openSegment = "EXISTS (SELECT 1 FROM task_segments fs WHERE fs.task_id = t.id AND fs.end_time IS NULL)"
+	switch opts.State {
+	case models.TaskFinished:
+		qb = qb.Where(goqu.I("t.end_time").IsNotNull())
+	case models.TaskRunning:
+		qb = qb.Where(goqu.I("t.end_time").IsNull(), goqu.L(openSegment))
+	case models.TaskPaused:
+		qb = qb.Where(goqu.I("t.end_time").IsNull(), goqu.L("NOT "+openSegment))
+	}
+
+	// get total count
+	countQuery, countArgs, err := qb.Select(goqu.L("COUNT(*)")).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("build count query: %w", err)
+	}
+	logging.FromContext(ctx).Debug("list count query", "query", countQuery, "repository", "tasks")
+
+	list := &models.TaskList{Limit: opts.Limit}
+	if err := r.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&list.Count); err != nil {
+		return nil, fmt.Errorf("count: %w", err)
+	}
+
+	sort := opts.Sort
+	if sort == "" {
+		sort = models.TaskSortID
+	}
+	key, ok := taskSortKeys[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort %q", sort)
+	}
+
+	if opts.Cursor != "" {
+		c, err := cursor.Decode(opts.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		value, err := c.Key()
+		if err != nil {
+			return nil, err
+		}
+
+		op := ">"
+		if opts.Desc {
+			op = "<"
+		}
+		qb = qb.Where(goqu.L(fmt.Sprintf("(%s, t.id) %s (?, ?)", key, op), value, c.ID))
+	}
+
+	if opts.Desc {
+		qb = qb.Order(goqu.L(key).Desc(), goqu.I("t.id").Desc())
+	} else {
+		qb = qb.Order(goqu.L(key).Asc(), goqu.I("t.id").Asc())
+	}
+	if opts.Limit > 0 {
+		// one more task tells whether there is a next page
+		qb = qb.Limit(uint(opts.Limit + 1))
+	}
+
+	query, args, err := qb.Select(goqu.L(taskColumns)).ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("build select query: %w", err)
+	}
+
+	logging.FromContext(ctx).Debug("list query", "query", query, "args", args, "repository", "tasks")
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "tasks")
+		}
+	}()
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		list.Tasks = append(list.Tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if opts.Limit > 0 && len(list.Tasks) > opts.Limit {
+		list.Tasks = list.Tasks[:opts.Limit]
+		list.NextCursor = cursor.Encode(sort, &list.Tasks[opts.Limit-1])
+	}
+
+	return list, nil
+}
+
+// workedSeconds sums time of segments s within [?1, ?2) with running segments lasting up to ?3, rounded to seconds.
+const workedSeconds = `CAST(ROUND(SUM(MIN(COALESCE(s.end_time, ?3), ?2) - MAX(s.start_time, ?1)) / 1e6) AS INTEGER)`
+
+// TaskEfforts returns time spent on each task of the user within [from, to) sorted by the time descending.
+// Only worked segments are counted, running segments are counted up to now.
+func (r *Repository) TaskEfforts(ctx context.Context, userID int, from, to, now time.Time) ([]models.TaskEffort, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT t.id, t.start_time, t.end_time, t.auto_stopped, ` + workedSeconds + ` AS seconds
+		FROM tasks t
+		JOIN task_segments s ON s.task_id = t.id
+		WHERE t.org_id = ?5 AND t.user_id = ?4 AND s.start_time < ?2 AND COALESCE(s.end_time, ?3) > ?1
+		GROUP BY t.id
+		ORDER BY seconds DESC, t.id`
+
+	rows, err := r.db.QueryContext(ctx, query, micro(from), micro(to), micro(now), userID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "tasks")
+		}
+	}()
+
+	var efforts []models.TaskEffort
+	for rows.Next() {
+		var effort models.TaskEffort
+		var since int64
+		var until sql.NullInt64
+		var seconds int64
+
+		if err := rows.Scan(&effort.TaskID, &since, &until, &effort.AutoStopped, &seconds); err != nil {
+			return nil, err
+		}
+		effort.Since = time.UnixMicro(since)
+		effort.Until = fromMicro(until)
+		effort.Duration = time.Duration(seconds) * time.Second
+
+		efforts = append(efforts, effort)
+	}
+
+	return efforts, rows.Err()
+}
+
+// CountRunningTasks returns the number of running tasks of all users, paused tasks are not counted.
+func (r *Repository) CountRunningTasks(ctx context.Context) (int, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `SELECT COUNT(*) FROM task_segments s JOIN tasks t ON t.id = s.task_id WHERE t.org_id = ? AND s.end_time IS NULL`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, orgID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ListRunningTasks returns running tasks of all users started before the time,
+// ordered by the start of their current segment.
+func (r *Repository) ListRunningTasks(ctx context.Context, startedBefore time.Time) ([]models.Task, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + taskColumns + `
+		FROM tasks t
+		JOIN task_segments s ON s.task_id = t.id AND s.end_time IS NULL
+		WHERE t.org_id = ? AND t.end_time IS NULL AND t.start_time < ?
+		ORDER BY s.start_time, t.id`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID, micro(startedBefore))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "tasks")
+		}
+	}()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// AutoStopTask stores the end of the task made by the auto-stop policy and marks it auto-stopped.
+// sql.ErrNoRows is returned if the task is not running in the same segment anymore, e.g. the user has ended it meanwhile.
+func (r *Repository) AutoStopTask(ctx context.Context, task *models.Task) error {
+	if len(task.Segments) == 0 {
+		return sql.ErrNoRows
+	}
+	last := task.Segments[len(task.Segments)-1]
+
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	before, err := getTask(ctx, tx, orgID, task.ID)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE task_segments SET end_time = ? WHERE task_id = ? AND start_time = ? AND end_time IS NULL`
+	result, err := tx.ExecContext(ctx, query, micro(last.Until), task.ID, micro(last.Since))
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	query = `UPDATE tasks SET end_time = ?, minutes = ?, auto_stopped = 1 WHERE id = ? AND org_id = ? AND end_time IS NULL`
+	result, err = tx.ExecContext(ctx, query, micro(task.Until), task.Minutes, task.ID, orgID)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	if err := auditTask(ctx, tx, models.AuditUpdate, before, task); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// micro converts the time to microseconds since the Unix epoch, zero time to NULL.
+func micro(t time.Time) sql.NullInt64 {
+	return sql.NullInt64{Int64: t.UnixMicro(), Valid: !t.IsZero()}
+}
+
+// fromMicro converts microseconds since the Unix epoch to time, NULL to zero time.
+func fromMicro(v sql.NullInt64) time.Time {
+	if !v.Valid {
+		return time.Time{}
+	}
+	return time.UnixMicro(v.Int64)
+}
+
+// nullInt converts zero to NULL.
+func nullInt(i int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(i), Valid: i != 0}
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(s scanner) (*models.Task, error) {
+	var task models.Task
+	var projectID sql.NullInt64
+	var since int64
+	var until sql.NullInt64
+	var tags, segments string
+
+	err := s.Scan(&task.ID, &task.UserID, &task.Title, &task.Description, &projectID, &since, &until, &task.Minutes, &task.AutoStopped, &tags, &segments)
+	if err != nil {
+		return nil, err
+	}
+
+	task.ProjectID = int(projectID.Int64)
+	task.Since = time.UnixMicro(since)
+	task.Until = fromMicro(until)
+	if err := json.Unmarshal([]byte(tags), &task.Tags); err != nil {
+		return nil, fmt.Errorf("unmarshal task tags: %w", err)
+	}
+
+	var rawSegments []struct {
+		Since int64
+		Until *int64
+	}
+	if err := json.Unmarshal([]byte(segments), &rawSegments); err != nil {
+		return nil, fmt.Errorf("unmarshal task segments: %w", err)
+	}
+	task.Segments = make([]models.TaskSegment, len(rawSegments))
+	for i, s := range rawSegments {
+		task.Segments[i] = models.TaskSegment{Since: time.UnixMicro(s.Since)}
+		if s.Until != nil {
+			task.Segments[i].Until = time.UnixMicro(*s.Until)
+		}
+	}
+
+	return &task, nil
+}
+
+// insertTask creates the task with its segments and tags, models.ErrDuplicate is returned if the user already has a running task.
+func insertTask(ctx context.Context, tx *sql.Tx, orgID int, task *models.Task) error {
+	query := `INSERT INTO tasks (user_id, title, description, project_id, start_time, end_time, minutes, auto_stopped, org_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`
+
+	row := tx.QueryRowContext(ctx, query, task.UserID, task.Title, task.Description, nullInt(task.ProjectID), micro(task.Since), micro(task.Until), task.Minutes, task.AutoStopped, orgID)
+	if err := row.Scan(&task.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	if err := insertTaskSegments(ctx, tx, task); err != nil {
+		return err
+	}
+
+	return setTaskTags(ctx, tx, task)
+}
+
+// insertTaskSegments stores segments of the task, a task without segments is worked in a single one.
+func insertTaskSegments(ctx context.Context, tx *sql.Tx, task *models.Task) error {
+	segments := task.Segments
+	if len(segments) == 0 {
+		segments = []models.TaskSegment{{Since: task.Since, Until: task.Until}}
+	}
+
+	query := `INSERT INTO task_segments (task_id, start_time, end_time) VALUES (?, ?, ?)`
+	for _, s := range segments {
+		if _, err := tx.ExecContext(ctx, query, task.ID, micro(s.Since), micro(s.Until)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setTaskTags(ctx context.Context, tx *sql.Tx, task *models.Task) error {
+	query := `INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING`
+	for _, tag := range task.Tags {
+		if _, err := tx.ExecContext(ctx, query, task.ID, tag.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// begin starts a transaction within the organization of the context, tenant.ErrMissing is returned if there is none.
+func (r *Repository) begin(ctx context.Context) (*sql.Tx, int, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tx, orgID, nil
+}
+
+func rollback(ctx context.Context, tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		logging.FromContext(ctx).Debug("db tx rollback", "err", err)
+	}
+}
+
+// checkAffected returns sql.ErrNoRows if the statement changed nothing.
+func checkAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// isUniqueViolation reports whether the error is caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+}
@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/encryption/encryptiontest"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/repository/repositorytest"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/Nicholas2012/time-tracker/pkg/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) usecase.Repository {
+		return setup(t)
+	})
+}
+
+func setup(t *testing.T) *Repository {
+	db, err := database.NewSQLite(filepath.Join(t.TempDir(), "time-tracker.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, database.ApplySQLiteMigrations(db))
+
+	return New(db, encryptiontest.Keyring(t))
+}
+
+func TestEncryptPassports(t *testing.T) {
+	ctx := tenant.WithOrg(context.Background(), models.DefaultOrgID)
+	db := setup(t).db
+
+	user := &models.User{Passport: models.Passport{Series: "1234", Number: "567890"}}
+	require.NoError(t, New(db, encryptiontest.Keyring(t, "2025")).CreateUser(ctx, user))
+
+	var series, key string
+	var encrypted []byte
+	require.NoError(t, db.QueryRow(`SELECT passport_serie, passport_encrypted, passport_key FROM users WHERE id = ?`, user.ID).Scan(&series, &encrypted, &key))
+	require.Empty(t, series)
+	require.NotContains(t, string(encrypted), "567890")
+	require.Equal(t, "2025", key)
+
+	// stored before passports were encrypted
+	var legacyID int
+	require.NoError(t, db.QueryRow(`INSERT INTO users (name, surname, patronymic, passport_serie, passport_number)
+		VALUES ('', '', '', '0012', '000001') RETURNING id`).Scan(&legacyID))
+
+	rotated := New(db, encryptiontest.Keyring(t, "2026", "2025"))
+	n, err := rotated.EncryptPassports(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	n, err = rotated.EncryptPassports(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// the old key is not needed any more
+	repo := New(db, encryptiontest.Keyring(t, "2026"))
+
+	u, err := repo.GetUser(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.Passport, u.Passport)
+
+	u, err = repo.GetUserByPassport(ctx, models.Passport{Series: "0012", Number: "000001"})
+	require.NoError(t, err)
+	require.Equal(t, legacyID, u.ID)
+
+	require.NoError(t, db.QueryRow(`SELECT passport_serie, passport_key FROM users WHERE id = ?`, legacyID).Scan(&series, &key))
+	require.Empty(t, series)
+	require.Equal(t, "2026", key)
+
+	duplicate := &models.User{Passport: models.Passport{Series: "0012", Number: "000001"}}
+	require.ErrorIs(t, repo.CreateUser(ctx, duplicate), models.ErrDuplicate)
+}
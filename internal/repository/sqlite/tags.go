@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+func (r *Repository) CreateTag(ctx context.Context, tag *models.Tag) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO tags (name, org_id) VALUES (?, ?) RETURNING id`
+
+	if err := r.db.QueryRowContext(ctx, query, tag.Name, orgID).Scan(&tag.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetTag(ctx context.Context, id int) (*models.Tag, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &models.Tag{ID: id}
+	if err := r.db.QueryRowContext(ctx, `SELECT name FROM tags WHERE id = ? AND org_id = ?`, id, orgID).Scan(&tag.Name); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (r *Repository) ListTags(ctx context.Context) ([]models.Tag, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name FROM tags WHERE org_id = ? ORDER BY name`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "tags")
+		}
+	}()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r *Repository) UpdateTag(ctx context.Context, tag *models.Tag) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `UPDATE tags SET name = ? WHERE id = ? AND org_id = ?`, tag.Name, tag.ID, orgID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	return checkAffected(result)
+}
+
+func (r *Repository) DeleteTag(ctx context.Context, id int) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tags WHERE id = ? AND org_id = ?`, id, orgID)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(result)
+}
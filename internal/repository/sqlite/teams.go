@@ -0,0 +1,241 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+func (r *Repository) CreateTeam(ctx context.Context, team *models.Team) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	query := `INSERT INTO teams (name, manager_id, org_id) VALUES (?, ?, ?) RETURNING id`
+
+	if err := tx.QueryRowContext(ctx, query, team.Name, nullInt(team.ManagerID), orgID).Scan(&team.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	if err := insertMembers(ctx, tx, team); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetTeam(ctx context.Context, id int) (*models.Team, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT name, manager_id FROM teams WHERE id = ? AND org_id = ?`
+
+	team := &models.Team{ID: id}
+	var managerID sql.NullInt64
+	if err := r.db.QueryRowContext(ctx, query, id, orgID).Scan(&team.Name, &managerID); err != nil {
+		return nil, err
+	}
+	team.ManagerID = int(managerID.Int64)
+
+	// the team is found within the organization, so are its members
+	rows, err := r.db.QueryContext(ctx, `SELECT user_id FROM team_members WHERE team_id = ? ORDER BY user_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "teams")
+		}
+	}()
+
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		team.MemberIDs = append(team.MemberIDs, userID)
+	}
+
+	return team, rows.Err()
+}
+
+// ListTeams returns all teams ordered by name with their members.
+func (r *Repository) ListTeams(ctx context.Context) ([]models.Team, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT t.id, t.name, t.manager_id, m.user_id
+		FROM teams t
+		LEFT JOIN team_members m ON m.team_id = t.id
+		WHERE t.org_id = ?
+		ORDER BY t.name, m.user_id`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "teams")
+		}
+	}()
+
+	var teams []models.Team
+	for rows.Next() {
+		var team models.Team
+		var managerID, memberID sql.NullInt64
+		if err := rows.Scan(&team.ID, &team.Name, &managerID, &memberID); err != nil {
+			return nil, err
+		}
+
+		// rows of a team are adjacent, one per member
+		if n := len(teams); n == 0 || teams[n-1].ID != team.ID {
+			team.ManagerID = int(managerID.Int64)
+			teams = append(teams, team)
+		}
+		if memberID.Valid {
+			last := &teams[len(teams)-1]
+			last.MemberIDs = append(last.MemberIDs, int(memberID.Int64))
+		}
+	}
+
+	return teams, rows.Err()
+}
+
+// UpdateTeam updates the team and replaces its members.
+func (r *Repository) UpdateTeam(ctx context.Context, team *models.Team) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	query := `UPDATE teams SET name = ?, manager_id = ? WHERE id = ? AND org_id = ?`
+
+	result, err := tx.ExecContext(ctx, query, team.Name, nullInt(team.ManagerID), team.ID, orgID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE team_id = ?`, team.ID); err != nil {
+		return err
+	}
+	if err := insertMembers(ctx, tx, team); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTeam deletes the team, its members are kept.
+func (r *Repository) DeleteTeam(ctx context.Context, id int) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM teams WHERE id = ? AND org_id = ?`, id, orgID)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(result)
+}
+
+func insertMembers(ctx context.Context, tx *sql.Tx, team *models.Team) error {
+	for _, userID := range team.MemberIDs {
+		query := `INSERT INTO team_members (team_id, user_id) VALUES (?, ?)`
+		if _, err := tx.ExecContext(ctx, query, team.ID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TeamEfforts returns time spent by each member of the team on each task within [from, to).
+// Members are sorted by their total time descending, their tasks by time descending; members who
+// worked on nothing in the period are included without tasks. Running segments are counted up to now.
+func (r *Repository) TeamEfforts(ctx context.Context, teamID int, from, to, now time.Time) ([]models.MemberEffort, error) {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `WITH efforts AS (
+			SELECT t.user_id, t.id, t.start_time, t.end_time, t.auto_stopped, ` + workedSeconds + ` AS seconds
+			FROM team_members m
+			JOIN tasks t ON t.user_id = m.user_id
+			JOIN task_segments s ON s.task_id = t.id
+			WHERE m.team_id = ?4 AND t.org_id = ?5 AND s.start_time < ?2 AND COALESCE(s.end_time, ?3) > ?1
+			GROUP BY t.id
+		)
+		SELECT u.id, u.name, u.surname, u.patronymic, e.id, e.start_time, e.end_time, e.auto_stopped,
+			COALESCE(e.seconds, 0), COALESCE(SUM(e.seconds) OVER (PARTITION BY u.id), 0) AS total
+		FROM team_members m
+		JOIN teams tm ON tm.id = m.team_id
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN efforts e ON e.user_id = u.id
+		WHERE m.team_id = ?4 AND tm.org_id = ?5
+		ORDER BY total DESC, u.id, e.seconds DESC, e.id`
+
+	rows, err := r.db.QueryContext(ctx, query, micro(from), micro(to), micro(now), teamID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "teams")
+		}
+	}()
+
+	var members []models.MemberEffort
+	for rows.Next() {
+		var member models.MemberEffort
+		var taskID sql.NullInt64
+		var since, until sql.NullInt64
+		var autoStopped sql.NullBool
+		var seconds, total int64
+
+		if err := rows.Scan(&member.UserID, &member.Name, &member.Surname, &member.Patronymic,
+			&taskID, &since, &until, &autoStopped, &seconds, &total); err != nil {
+			return nil, err
+		}
+
+		// rows of a member are adjacent, one per task
+		if n := len(members); n == 0 || members[n-1].UserID != member.UserID {
+			member.Total = time.Duration(total) * time.Second
+			members = append(members, member)
+		}
+		if taskID.Valid {
+			last := &members[len(members)-1]
+			last.Tasks = append(last.Tasks, models.TaskEffort{
+				TaskID:      int(taskID.Int64),
+				Since:       fromMicro(since),
+				Until:       fromMicro(until),
+				Duration:    time.Duration(seconds) * time.Second,
+				AutoStopped: autoStopped.Bool,
+			})
+		}
+	}
+
+	return members, rows.Err()
+}
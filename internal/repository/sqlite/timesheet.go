@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+// Timesheet calls fn for each task worked on within [from, to) ordered by user full name and task start.
+// Zero userID selects tasks of all users. Rows are read from the database one by one as fn consumes them.
+func (r *Repository) Timesheet(ctx context.Context, userID int, from, to, now time.Time, fn func(*models.TimesheetRow) error) error {
+	orgID, err := tenant.Org(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `SELECT u.id, u.name, u.surname, u.patronymic, u.passport_serie, u.passport_number, u.passport_encrypted, u.passport_index,
+			t.id, t.title, t.start_time, t.end_time, t.auto_stopped, ` + workedSeconds + ` AS seconds
+		FROM tasks t
+		JOIN users u ON u.id = t.user_id
+		JOIN task_segments s ON s.task_id = t.id
+		WHERE t.org_id = ?5 AND (?4 = 0 OR t.user_id = ?4) AND s.start_time < ?2 AND COALESCE(s.end_time, ?3) > ?1
+		GROUP BY u.id, t.id
+		ORDER BY u.surname, u.name, u.patronymic, u.id, t.start_time, t.id`
+
+	rows, err := r.db.QueryContext(ctx, query, micro(from), micro(to), micro(now), userID, orgID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "tasks")
+		}
+	}()
+
+	var row models.TimesheetRow
+	for rows.Next() {
+		var passport storedPassport
+		var since int64
+		var until sql.NullInt64
+		var seconds int64
+
+		err := rows.Scan(&row.User.ID, &row.User.Name, &row.User.Surname, &row.User.Patronymic,
+			&passport.series, &passport.number, &passport.encrypted, &passport.index,
+			&row.TaskID, &row.Title, &since, &until, &row.AutoStopped, &seconds)
+		if err != nil {
+			return err
+		}
+		if row.User.Passport, err = r.decryptPassport(passport); err != nil {
+			return err
+		}
+		row.Since = time.UnixMicro(since)
+		row.Until = fromMicro(until)
+		row.Duration = time.Duration(seconds) * time.Second
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+func (r *Repository) CreateTag(ctx context.Context, tag *models.Tag) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	query := `INSERT INTO tags (name, org_id) VALUES ($1, $2) RETURNING id`
+
+	if err := tx.QueryRowContext(ctx, query, tag.Name, orgID).Scan(&tag.ID); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetTag(ctx context.Context, id int) (*models.Tag, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	tag := &models.Tag{ID: id}
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM tags WHERE id = $1 AND org_id = $2`, id, orgID).Scan(&tag.Name); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (r *Repository) ListTags(ctx context.Context) ([]models.Tag, error) {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, name FROM tags WHERE org_id = $1 ORDER BY name`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "tags")
+		}
+	}()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r *Repository) UpdateTag(ctx context.Context, tag *models.Tag) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	result, err := tx.ExecContext(ctx, `UPDATE tags SET name = $1 WHERE id = $2 AND org_id = $3`, tag.Name, tag.ID, orgID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicate
+		}
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) DeleteTag(ctx context.Context, id int) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
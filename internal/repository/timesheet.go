@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// Timesheet calls fn for each task worked on within [from, to) ordered by user full name and task start.
+// Zero userID selects tasks of all users. Rows are read from the database one by one as fn consumes them.
+func (r *Repository) Timesheet(ctx context.Context, userID int, from, to, now time.Time, fn func(*models.TimesheetRow) error) error {
+	tx, orgID, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	query := `SELECT u.id, u.name, u.surname, u.patronymic, u.passport_serie, u.passport_number, u.passport_encrypted, u.passport_index,
+			t.id, t.title, t.start_time, t.end_time, t.auto_stopped,
+			SUM(EXTRACT(EPOCH FROM LEAST(COALESCE(s.end_time, $4), $3) - GREATEST(s.start_time, $2)))::BIGINT AS seconds
+		FROM tasks t
+		JOIN users u ON u.id = t.user_id
+		JOIN task_segments s ON s.task_id = t.id
+		WHERE t.org_id = $5 AND ($1 = 0 OR t.user_id = $1) AND s.start_time < $3 AND COALESCE(s.end_time, $4) > $2
+		GROUP BY u.id, t.id
+		ORDER BY u.surname, u.name, u.patronymic, u.id, t.start_time, t.id`
+
+	rows, err := tx.QueryContext(ctx, query, userID, from, to, now, orgID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logging.FromContext(ctx).Debug("db rows close", "err", err, "repository", "tasks")
+		}
+	}()
+
+	var row models.TimesheetRow
+	for rows.Next() {
+		var passport storedPassport
+		var until sql.NullTime
+		var seconds int64
+
+		err := rows.Scan(&row.User.ID, &row.User.Name, &row.User.Surname, &row.User.Patronymic,
+			&passport.series, &passport.number, &passport.encrypted, &passport.index,
+			&row.TaskID, &row.Title, &row.Since, &until, &row.AutoStopped, &seconds)
+		if err != nil {
+			return err
+		}
+		if row.User.Passport, err = r.decryptPassport(passport); err != nil {
+			return err
+		}
+		row.Until = until.Time
+		row.Duration = time.Duration(seconds) * time.Second
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,381 @@
+// Package traced decorates a repository with a span per query.
+package traced
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/Nicholas2012/time-tracker/internal/repository")
+
+var _ usecase.Repository = (*Repository)(nil)
+
+// Repository starts a child span for each query made through the wrapped repository.
+// Spans are named after the repository method, which is also the db.operation.name attribute.
+type Repository struct {
+	repo   usecase.Repository
+	system attribute.KeyValue
+}
+
+// New wraps repo, system is the db.system attribute of spans, e.g. semconv.DBSystemPostgreSQL.
+func New(repo usecase.Repository, system attribute.KeyValue) *Repository {
+	return &Repository{repo: repo, system: system}
+}
+
+func (r *Repository) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "repository."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(r.system, semconv.DBOperationName(operation)),
+	)
+}
+
+// end records the error of the query and ends the span.
+// sql.ErrNoRows is an expected result rather than a failure, so it's not recorded.
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (r *Repository) CreateOrg(ctx context.Context, org *models.Org) error {
+	ctx, span := r.start(ctx, "CreateOrg")
+	err := r.repo.CreateOrg(ctx, org)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) GetOrg(ctx context.Context, id int) (*models.Org, error) {
+	ctx, span := r.start(ctx, "GetOrg")
+	res, err := r.repo.GetOrg(ctx, id)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) ListOrgs(ctx context.Context) ([]models.Org, error) {
+	ctx, span := r.start(ctx, "ListOrgs")
+	res, err := r.repo.ListOrgs(ctx)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) CreateUser(ctx context.Context, user *models.User) error {
+	ctx, span := r.start(ctx, "CreateUser")
+	err := r.repo.CreateUser(ctx, user)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) GetUser(ctx context.Context, id int) (*models.User, error) {
+	ctx, span := r.start(ctx, "GetUser")
+	res, err := r.repo.GetUser(ctx, id)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) GetUserByPassport(ctx context.Context, passport models.Passport) (*models.User, error) {
+	ctx, span := r.start(ctx, "GetUserByPassport")
+	res, err := r.repo.GetUserByPassport(ctx, passport)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) UpdateUser(ctx context.Context, user *models.User) error {
+	ctx, span := r.start(ctx, "UpdateUser")
+	err := r.repo.UpdateUser(ctx, user)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) DeleteUser(ctx context.Context, user *models.User) error {
+	ctx, span := r.start(ctx, "DeleteUser")
+	err := r.repo.DeleteUser(ctx, user)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) ListUsers(ctx context.Context, opts models.UserListOpts) (*models.UserList, error) {
+	ctx, span := r.start(ctx, "ListUsers")
+	res, err := r.repo.ListUsers(ctx, opts)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) ListPendingUsers(ctx context.Context, limit int) ([]models.User, error) {
+	ctx, span := r.start(ctx, "ListPendingUsers")
+	res, err := r.repo.ListPendingUsers(ctx, limit)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) FailEnrichment(ctx context.Context, id int, status models.EnrichStatus) error {
+	ctx, span := r.start(ctx, "FailEnrichment")
+	err := r.repo.FailEnrichment(ctx, id, status)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) CreateTask(ctx context.Context, task *models.Task) error {
+	ctx, span := r.start(ctx, "CreateTask")
+	err := r.repo.CreateTask(ctx, task)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) SwitchTask(ctx context.Context, task *models.Task) (*models.Task, error) {
+	ctx, span := r.start(ctx, "SwitchTask")
+	res, err := r.repo.SwitchTask(ctx, task)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) UpdateTask(ctx context.Context, task *models.Task, read models.TaskRevision) error {
+	ctx, span := r.start(ctx, "UpdateTask")
+	err := r.repo.UpdateTask(ctx, task, read)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) GetTask(ctx context.Context, userID, id int) (*models.Task, error) {
+	ctx, span := r.start(ctx, "GetTask")
+	res, err := r.repo.GetTask(ctx, userID, id)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) ListTasks(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+	ctx, span := r.start(ctx, "ListTasks")
+	res, err := r.repo.ListTasks(ctx, userID, opts)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) OverlappingTask(ctx context.Context, userID, excludeID int, since, until, now time.Time) (int, error) {
+	ctx, span := r.start(ctx, "OverlappingTask")
+	res, err := r.repo.OverlappingTask(ctx, userID, excludeID, since, until, now)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) TaskEfforts(ctx context.Context, userID int, from, to, now time.Time) ([]models.TaskEffort, error) {
+	ctx, span := r.start(ctx, "TaskEfforts")
+	res, err := r.repo.TaskEfforts(ctx, userID, from, to, now)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) Timesheet(ctx context.Context, userID int, from, to, now time.Time, fn func(*models.TimesheetRow) error) error {
+	ctx, span := r.start(ctx, "Timesheet")
+	err := r.repo.Timesheet(ctx, userID, from, to, now, fn)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) CountRunningTasks(ctx context.Context) (int, error) {
+	ctx, span := r.start(ctx, "CountRunningTasks")
+	res, err := r.repo.CountRunningTasks(ctx)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) CreateProject(ctx context.Context, project *models.Project) error {
+	ctx, span := r.start(ctx, "CreateProject")
+	err := r.repo.CreateProject(ctx, project)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	ctx, span := r.start(ctx, "GetProject")
+	res, err := r.repo.GetProject(ctx, id)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) ListProjects(ctx context.Context) ([]models.Project, error) {
+	ctx, span := r.start(ctx, "ListProjects")
+	res, err := r.repo.ListProjects(ctx)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) UpdateProject(ctx context.Context, project *models.Project) error {
+	ctx, span := r.start(ctx, "UpdateProject")
+	err := r.repo.UpdateProject(ctx, project)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) DeleteProject(ctx context.Context, id int) error {
+	ctx, span := r.start(ctx, "DeleteProject")
+	err := r.repo.DeleteProject(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) CreateTag(ctx context.Context, tag *models.Tag) error {
+	ctx, span := r.start(ctx, "CreateTag")
+	err := r.repo.CreateTag(ctx, tag)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) GetTag(ctx context.Context, id int) (*models.Tag, error) {
+	ctx, span := r.start(ctx, "GetTag")
+	res, err := r.repo.GetTag(ctx, id)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) ListTags(ctx context.Context) ([]models.Tag, error) {
+	ctx, span := r.start(ctx, "ListTags")
+	res, err := r.repo.ListTags(ctx)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) UpdateTag(ctx context.Context, tag *models.Tag) error {
+	ctx, span := r.start(ctx, "UpdateTag")
+	err := r.repo.UpdateTag(ctx, tag)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) DeleteTag(ctx context.Context, id int) error {
+	ctx, span := r.start(ctx, "DeleteTag")
+	err := r.repo.DeleteTag(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) CreateTeam(ctx context.Context, team *models.Team) error {
+	ctx, span := r.start(ctx, "CreateTeam")
+	err := r.repo.CreateTeam(ctx, team)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) GetTeam(ctx context.Context, id int) (*models.Team, error) {
+	ctx, span := r.start(ctx, "GetTeam")
+	res, err := r.repo.GetTeam(ctx, id)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) ListTeams(ctx context.Context) ([]models.Team, error) {
+	ctx, span := r.start(ctx, "ListTeams")
+	res, err := r.repo.ListTeams(ctx)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) UpdateTeam(ctx context.Context, team *models.Team) error {
+	ctx, span := r.start(ctx, "UpdateTeam")
+	err := r.repo.UpdateTeam(ctx, team)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) DeleteTeam(ctx context.Context, id int) error {
+	ctx, span := r.start(ctx, "DeleteTeam")
+	err := r.repo.DeleteTeam(ctx, id)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) TeamEfforts(ctx context.Context, teamID int, from, to, now time.Time) ([]models.MemberEffort, error) {
+	ctx, span := r.start(ctx, "TeamEfforts")
+	res, err := r.repo.TeamEfforts(ctx, teamID, from, to, now)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	ctx, span := r.start(ctx, "CreateAPIKey")
+	err := r.repo.CreateAPIKey(ctx, key)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) GetAPIKeyByHash(ctx context.Context, hash []byte) (*models.APIKey, error) {
+	ctx, span := r.start(ctx, "GetAPIKeyByHash")
+	res, err := r.repo.GetAPIKeyByHash(ctx, hash)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+	ctx, span := r.start(ctx, "ListAPIKeys")
+	res, err := r.repo.ListAPIKeys(ctx, userID)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	ctx, span := r.start(ctx, "RevokeAPIKey")
+	err := r.repo.RevokeAPIKey(ctx, userID, id)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) SetFeedToken(ctx context.Context, userID int, hash []byte) error {
+	ctx, span := r.start(ctx, "SetFeedToken")
+	err := r.repo.SetFeedToken(ctx, userID, hash)
+	end(span, err)
+	return err
+}
+
+func (r *Repository) GetFeedToken(ctx context.Context, userID int) ([]byte, error) {
+	ctx, span := r.start(ctx, "GetFeedToken")
+	res, err := r.repo.GetFeedToken(ctx, userID)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) ListAuditEntries(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error) {
+	ctx, span := r.start(ctx, "ListAuditEntries")
+	res, err := r.repo.ListAuditEntries(ctx, filter)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) ListAuditEntriesAfter(ctx context.Context, afterID, limit int) ([]models.AuditEntry, error) {
+	ctx, span := r.start(ctx, "ListAuditEntriesAfter")
+	res, err := r.repo.ListAuditEntriesAfter(ctx, afterID, limit)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) ListRunningTasks(ctx context.Context, startedBefore time.Time) ([]models.Task, error) {
+	ctx, span := r.start(ctx, "ListRunningTasks")
+	res, err := r.repo.ListRunningTasks(ctx, startedBefore)
+	end(span, err)
+	return res, err
+}
+
+func (r *Repository) AutoStopTask(ctx context.Context, task *models.Task) error {
+	ctx, span := r.start(ctx, "AutoStopTask")
+	err := r.repo.AutoStopTask(ctx, task)
+	end(span, err)
+	return err
+}
+
+// WithLock spans the whole run of fn, so queries made by fn are its children.
+func (r *Repository) WithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	ctx, span := r.start(ctx, "WithLock")
+	span.SetAttributes(attribute.String("lock.name", name))
+	res, err := r.repo.WithLock(ctx, name, fn)
+	end(span, err)
+	return res, err
+}
@@ -0,0 +1,59 @@
+package traced
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/repository/memory"
+	"github.com/Nicholas2012/time-tracker/internal/repository/repositorytest"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/Nicholas2012/time-tracker/internal/usecase"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) usecase.Repository {
+		return New(memory.New(), semconv.DBSystemKey.String("memory"))
+	})
+}
+
+func TestSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	repo := New(memory.New(), semconv.DBSystemPostgreSQL)
+
+	ctx, parent := provider.Tracer("test").Start(tenant.WithOrg(context.Background(), models.DefaultOrgID), "request")
+
+	_, err := repo.GetUser(ctx, 404)
+	require.Error(t, err)
+
+	err = repo.CreateTask(ctx, &models.Task{UserID: 404})
+	require.Error(t, err)
+
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 3)
+
+	get, create := spans[0], spans[1]
+
+	require.Equal(t, "repository.GetUser", get.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), get.Parent().SpanID())
+	require.Contains(t, get.Attributes(), semconv.DBSystemPostgreSQL)
+	require.Contains(t, get.Attributes(), semconv.DBOperationName("GetUser"))
+	require.Equal(t, codes.Unset, get.Status().Code, "not found is not a failure")
+
+	require.Equal(t, "repository.CreateTask", create.Name())
+	require.Equal(t, codes.Error, create.Status().Code)
+}
@@ -0,0 +1,33 @@
+// Package tenant carries the organization a call acts within. Storage backends scope every query by it,
+// so data of one organization is never read or changed on behalf of another one.
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrMissing is returned by storage when a query is made with a context without an organization.
+var ErrMissing = errors.New("organization is not set")
+
+type orgKey struct{}
+
+// WithOrg returns a copy of ctx acting within the organization.
+func WithOrg(ctx context.Context, orgID int) context.Context {
+	return context.WithValue(ctx, orgKey{}, orgID)
+}
+
+// OrgFrom returns the organization of the context, ok is false if there is none.
+func OrgFrom(ctx context.Context) (orgID int, ok bool) {
+	orgID, ok = ctx.Value(orgKey{}).(int)
+	return orgID, ok && orgID != 0
+}
+
+// Org returns the organization of the context, ErrMissing is returned if there is none.
+func Org(ctx context.Context) (int, error) {
+	orgID, ok := OrgFrom(ctx)
+	if !ok {
+		return 0, ErrMissing
+	}
+	return orgID, nil
+}
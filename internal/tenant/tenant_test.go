@@ -0,0 +1,25 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrg(t *testing.T) {
+	_, err := Org(context.Background())
+	require.ErrorIs(t, err, ErrMissing)
+
+	_, err = Org(WithOrg(context.Background(), 0))
+	require.ErrorIs(t, err, ErrMissing)
+
+	ctx := WithOrg(context.Background(), 2)
+	orgID, err := Org(WithOrg(ctx, 3))
+	require.NoError(t, err)
+	require.Equal(t, 3, orgID)
+
+	orgID, ok := OrgFrom(ctx)
+	require.True(t, ok)
+	require.Equal(t, 2, orgID)
+}
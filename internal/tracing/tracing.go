@@ -0,0 +1,69 @@
+// Package tracing configures OpenTelemetry tracing of the service.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const serviceName = "time-tracker"
+
+// Exporters of spans.
+const (
+	ExporterOTLP   = "otlp"   // OTLP over HTTP, configured by standard OTEL_EXPORTER_OTLP_* variables
+	ExporterStdout = "stdout" // spans are written to stdout as JSON
+	ExporterNone   = "none"   // spans are not recorded
+)
+
+// Setup installs the global tracer provider exporting spans with the exporter
+// and the W3C trace context propagator. The returned shutdown flushes pending spans.
+func Setup(ctx context.Context, exporter string) (shutdown func(context.Context) error, err error) {
+	return setup(ctx, exporter, os.Stdout)
+}
+
+func setup(ctx context.Context, exporter string, stdout io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp exporter: %w", err)
+		}
+		spanExporter = exp
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(stdout))
+		if err != nil {
+			return nil, fmt.Errorf("create stdout exporter: %w", err)
+		}
+		spanExporter = exp
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, must be otlp, stdout or none", exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("create resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
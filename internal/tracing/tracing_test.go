@@ -0,0 +1,28 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup_Stdout(t *testing.T) {
+	var out bytes.Buffer
+	shutdown, err := setup(context.Background(), ExporterStdout, &out)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+
+	require.NoError(t, shutdown(context.Background()))
+	require.Contains(t, out.String(), `"Name":"test-span"`)
+	require.Contains(t, out.String(), serviceName)
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), "jaeger")
+	require.EqualError(t, err, `unknown tracing exporter "jaeger", must be otlp, stdout or none`)
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// ListAuditEntries returns a page of the audit log, the newest changes first.
+// The next page is requested with the cursor of the previous one. Only admins may read the log.
+func (s *Service) ListAuditEntries(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListAuditEntries")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if filter.Entity != "" && !filter.Entity.IsValid() {
+		return nil, invalid("invalid_entity", "entity", fmt.Sprintf("unknown entity %q", filter.Entity))
+	}
+	if filter.EntityID != 0 && filter.Entity == "" {
+		return nil, invalid("invalid_entity", "entity", "entity is required with entity ID")
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, invalid("invalid_period", "to", "to must be after from")
+	}
+
+	if filter.Limit < 1 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+
+	log, err := s.repo.ListAuditEntries(ctx, filter)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			return nil, invalid("invalid_cursor", "cursor", "cursor is malformed")
+		}
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+
+	return log, nil
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAuditEntries(t *testing.T) {
+	s, repo := setup(t)
+
+	var got models.AuditFilter
+	repo.ListAuditEntriesFn = func(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error) {
+		got = filter
+		if filter.Cursor == "garbage" {
+			return nil, models.ErrInvalidCursor
+		}
+		return &models.AuditLog{Entries: []models.AuditEntry{{ID: 1}}}, nil
+	}
+
+	admin := WithActor(context.TODO(), Actor{Role: models.RoleAdmin})
+	log, err := s.ListAuditEntries(admin, models.AuditFilter{Entity: models.AuditTask, EntityID: 5})
+	require.NoError(t, err)
+	require.Len(t, log.Entries, 1)
+	require.Equal(t, models.AuditFilter{Entity: models.AuditTask, EntityID: 5, Limit: defaultAuditLimit}, got)
+
+	_, err = s.ListAuditEntries(admin, models.AuditFilter{Limit: 10000})
+	require.NoError(t, err)
+	require.Equal(t, maxAuditLimit, got.Limit)
+
+	manager := WithActor(context.TODO(), Actor{UserID: 3, Role: models.RoleManager})
+	_, err = s.ListAuditEntries(manager, models.AuditFilter{})
+	require.ErrorIs(t, err, ErrForbidden)
+
+	now := time.Now()
+	for _, filter := range []models.AuditFilter{
+		{Entity: "project"},
+		{EntityID: 5},
+		{From: now, To: now.Add(-time.Hour)},
+		{Cursor: "garbage"},
+	} {
+		_, err := s.ListAuditEntries(admin, filter)
+		require.ErrorIs(t, err, ErrValidation, "%+v", filter)
+	}
+}
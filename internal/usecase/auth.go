@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/audit"
+	"github.com/Nicholas2012/time-tracker/internal/auth"
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+// Actor is an authenticated caller of the service.
+type Actor struct {
+	UserID int // zero for the admin key from the configuration
+	Role   models.Role
+	OrgID  int // organization of the user, zero for the admin key
+	KeyID  int // API key the actor authenticated with, zero for the admin key
+
+	system bool
+}
+
+// System is the actor of internal calls, such as the ones of background workers, which are not restricted.
+// Their changes are audited as internal.
+var System = Actor{system: true}
+
+// isOperator reports whether the actor holds the admin key, which acts within any organization.
+func (a Actor) isOperator() bool {
+	return !a.system && a.UserID == 0 && a.Role == models.RoleAdmin
+}
+
+type actorKey struct{}
+
+// WithActor returns a context of requests made by the actor, changes made with it are audited as the actor's.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	ctx = audit.WithActor(ctx, actor.UserID, actor.Role)
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor of the context, ok is false if there is none. Calls without an actor are denied.
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// WithTokens enables bearer tokens issued for API keys.
+func WithTokens(tokens *auth.Tokens) Option {
+	return func(s *Service) {
+		s.tokens = tokens
+	}
+}
+
+// WithAdminKey sets a key which authenticates as admin, it is used to issue the first keys.
+func WithAdminKey(key string) Option {
+	return func(s *Service) {
+		s.adminKey = auth.HashKey(key)
+	}
+}
+
+var (
+	errUnauthenticated = &Error{Kind: KindUnauthorized, Code: "unauthenticated", Message: "invalid credentials"}
+	errAccessDenied    = forbidden("access_denied", "access denied")
+	errKeyNotFound     = notFound("api_key_not_found", "api key not found")
+)
+
+// Authenticate returns the actor the API key belongs to, the actor acts within the organization of the key.
+func (s *Service) Authenticate(ctx context.Context, key string) (Actor, error) {
+	ctx, span := tracer.Start(ctx, "Service.Authenticate")
+	defer span.End()
+
+	hash := auth.HashKey(key)
+	if s.adminKey != nil && subtle.ConstantTimeCompare(hash, s.adminKey) == 1 {
+		return Actor{Role: models.RoleAdmin}, nil
+	}
+
+	apiKey, err := s.repo.GetAPIKeyByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Actor{}, errUnauthenticated
+		}
+		return Actor{}, fmt.Errorf("get api key: %w", err)
+	}
+
+	user, err := s.getUser(tenant.WithOrg(ctx, apiKey.OrgID), apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Actor{}, errUnauthenticated
+		}
+		return Actor{}, err
+	}
+
+	return Actor{UserID: user.ID, Role: user.Role, OrgID: apiKey.OrgID, KeyID: apiKey.ID}, nil
+}
+
+// AuthenticateToken returns the actor the bearer token is issued for. Tokens are valid while the key
+// they are issued with is, and the actor has the current role of the user rather than the one of the token.
+func (s *Service) AuthenticateToken(ctx context.Context, token string) (Actor, error) {
+	ctx, span := tracer.Start(ctx, "Service.AuthenticateToken")
+	defer span.End()
+
+	if s.tokens == nil {
+		return Actor{}, errUnauthenticated
+	}
+
+	claims, err := s.tokens.Parse(token)
+	if err != nil {
+		return Actor{}, errUnauthenticated
+	}
+
+	if claims.UserID == 0 {
+		if s.adminKey == nil || subtle.ConstantTimeCompare([]byte(claims.AdminKey), []byte(s.tokens.Fingerprint(s.adminKey))) != 1 {
+			return Actor{}, errUnauthenticated
+		}
+		return Actor{Role: models.RoleAdmin}, nil
+	}
+
+	ctx = tenant.WithOrg(ctx, claims.OrgID)
+	keys, err := s.repo.ListAPIKeys(ctx, claims.UserID)
+	if err != nil {
+		return Actor{}, fmt.Errorf("list api keys: %w", err)
+	}
+	active := slices.ContainsFunc(keys, func(key models.APIKey) bool {
+		return key.ID == claims.KeyID && key.RevokedAt.IsZero()
+	})
+	if !active {
+		return Actor{}, errUnauthenticated
+	}
+
+	user, err := s.getUser(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Actor{}, errUnauthenticated
+		}
+		return Actor{}, err
+	}
+
+	return Actor{UserID: user.ID, Role: user.Role, OrgID: claims.OrgID, KeyID: claims.KeyID}, nil
+}
+
+// IssueToken returns a bearer token for the actor of the context and its expiration time.
+func (s *Service) IssueToken(ctx context.Context) (string, time.Time, error) {
+	ctx, span := tracer.Start(ctx, "Service.IssueToken")
+	defer span.End()
+
+	actor, ok := ActorFrom(ctx)
+	if !ok || actor.system || s.tokens == nil {
+		return "", time.Time{}, errUnauthenticated
+	}
+
+	claims := auth.Claims{UserID: actor.UserID, Role: actor.Role, OrgID: actor.OrgID, KeyID: actor.KeyID}
+	if actor.isOperator() {
+		if s.adminKey == nil {
+			return "", time.Time{}, errUnauthenticated
+		}
+		claims.AdminKey = s.tokens.Fingerprint(s.adminKey)
+	}
+
+	return s.tokens.Issue(claims)
+}
+
+// CreateAPIKey issues a new key for the user. The key itself is returned only here, only its hash is stored.
+func (s *Service) CreateAPIKey(ctx context.Context, userID int, name string) (*models.APIKey, string, error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateAPIKey")
+	defer span.End()
+
+	if err := s.authorizeOwner(ctx, userID); err != nil {
+		return nil, "", err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, prefix, hash, err := auth.GenerateKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey := &models.APIKey{
+		UserID: user.ID,
+		Name:   strings.TrimSpace(name),
+		Prefix: prefix,
+		Hash:   hash,
+	}
+	if err := s.repo.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, "", fmt.Errorf("create api key: %w", err)
+	}
+
+	return apiKey, key, nil
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListAPIKeys")
+	defer span.End()
+
+	if err := s.authorizeOwner(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.ListAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *Service) RevokeAPIKey(ctx context.Context, userID, keyID int) error {
+	ctx, span := tracer.Start(ctx, "Service.RevokeAPIKey")
+	defer span.End()
+
+	if err := s.authorizeOwner(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.repo.RevokeAPIKey(ctx, userID, keyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errKeyNotFound
+		}
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	return nil
+}
+
+// RevealPassports checks that the actor may see passports unmasked, every reveal is logged.
+func (s *Service) RevealPassports(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "Service.RevealPassports")
+	defer span.End()
+
+	if err := authorizePermission(ctx, models.PermissionRevealPassports); err != nil {
+		return err
+	}
+
+	actor, _ := ActorFrom(ctx)
+	logging.FromContext(ctx).Info("Passports revealed", "actor", actor.UserID)
+
+	return nil
+}
+
+// authorizePermission checks that the role of the actor is granted the permission.
+func authorizePermission(ctx context.Context, p models.Permission) error {
+	actor, ok := ActorFrom(ctx)
+	if ok && (actor.system || actor.Role.Can(p)) {
+		return nil
+	}
+
+	return errAccessDenied
+}
+
+// authorizeRole checks that the actor has one of the roles.
+func authorizeRole(ctx context.Context, roles ...models.Role) error {
+	actor, ok := ActorFrom(ctx)
+	if !ok {
+		return errAccessDenied
+	}
+	if actor.system {
+		return nil
+	}
+
+	for _, role := range roles {
+		if actor.Role == role {
+			return nil
+		}
+	}
+
+	return errAccessDenied
+}
+
+// authorizeOwner checks that the actor may change data of the user: admins may change anyone's data.
+func (s *Service) authorizeOwner(ctx context.Context, userID int) error {
+	actor, ok := ActorFrom(ctx)
+	if ok && (actor.system || actor.Role == models.RoleAdmin || actor.UserID == userID) {
+		return nil
+	}
+
+	return errAccessDenied
+}
+
+// authorizeTeamReader checks that the actor may read reports of the team: admins and the manager of the team may.
+func authorizeTeamReader(ctx context.Context, team *models.Team) error {
+	actor, ok := ActorFrom(ctx)
+	if ok && (actor.system || actor.Role == models.RoleAdmin || (team.ManagerID != 0 && actor.UserID == team.ManagerID)) {
+		return nil
+	}
+
+	return errAccessDenied
+}
+
+// authorizeTeamMember checks that the actor may see the team: admins, the manager and members of the team may.
+func authorizeTeamMember(ctx context.Context, team *models.Team) error {
+	if err := authorizeTeamReader(ctx, team); err == nil {
+		return nil
+	}
+
+	actor, ok := ActorFrom(ctx)
+	if ok && actor.UserID != 0 && slices.Contains(team.MemberIDs, actor.UserID) {
+		return nil
+	}
+
+	return errAccessDenied
+}
+
+// authorizeReader checks that the actor may read data of the user: managers may read data
+// of users they manage directly and of members of teams they manage.
+func (s *Service) authorizeReader(ctx context.Context, userID int) error {
+	actor, ok := ActorFrom(ctx)
+	if !ok {
+		return errAccessDenied
+	}
+	if actor.system || actor.Role == models.RoleAdmin || actor.UserID == userID {
+		return nil
+	}
+	if actor.Role != models.RoleManager {
+		return errAccessDenied
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.ManagerID == actor.UserID {
+		return nil
+	}
+
+	teams, err := s.repo.ListTeams(ctx)
+	if err != nil {
+		return fmt.Errorf("list teams: %w", err)
+	}
+	for _, team := range teams {
+		if team.ManagerID == actor.UserID && slices.Contains(team.MemberIDs, userID) {
+			return nil
+		}
+	}
+
+	return errAccessDenied
+}
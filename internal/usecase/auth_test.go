@@ -0,0 +1,276 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/auth"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticate(t *testing.T) {
+	repo := &repositoryMock{}
+	s := New(repo, WithAdminKey("root"), WithTokens(auth.NewTokens([]byte("secret"), time.Hour)))
+
+	repo.GetAPIKeyByHashFn = func(ctx context.Context, hash []byte) (*models.APIKey, error) {
+		if string(hash) == string(auth.HashKey("tt_user")) {
+			return &models.APIKey{ID: 1, UserID: 7, OrgID: 3}, nil
+		}
+		return nil, sql.ErrNoRows
+	}
+	role, deleted := models.RoleManager, false
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		// the user is looked up within the organization of the key
+		orgID, _ := tenant.OrgFrom(ctx)
+		require.Equal(t, 3, orgID)
+		if deleted {
+			return nil, sql.ErrNoRows
+		}
+		return &models.User{ID: id, Role: role}, nil
+	}
+	var revokedAt time.Time
+	repo.ListAPIKeysFn = func(ctx context.Context, userID int) ([]models.APIKey, error) {
+		orgID, _ := tenant.OrgFrom(ctx)
+		require.Equal(t, 3, orgID)
+		return []models.APIKey{{ID: 1, UserID: userID, OrgID: 3, RevokedAt: revokedAt}, {ID: 2, UserID: userID, OrgID: 3}}, nil
+	}
+
+	admin, err := s.Authenticate(context.TODO(), "root")
+	require.NoError(t, err)
+	require.Equal(t, Actor{Role: models.RoleAdmin}, admin)
+
+	actor, err := s.Authenticate(context.TODO(), "tt_user")
+	require.NoError(t, err)
+	require.Equal(t, Actor{UserID: 7, Role: models.RoleManager, OrgID: 3, KeyID: 1}, actor)
+
+	_, err = s.Authenticate(context.TODO(), "tt_unknown")
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	t.Run("Token", func(t *testing.T) {
+		_, _, err := s.IssueToken(context.TODO())
+		require.ErrorIs(t, err, ErrUnauthorized)
+		_, _, err = s.IssueToken(system())
+		require.ErrorIs(t, err, ErrUnauthorized)
+
+		token, expires, err := s.IssueToken(WithActor(context.TODO(), actor))
+		require.NoError(t, err)
+		require.True(t, expires.After(time.Now()))
+
+		got, err := s.AuthenticateToken(context.TODO(), token)
+		require.NoError(t, err)
+		require.Equal(t, actor, got)
+
+		_, err = s.AuthenticateToken(context.TODO(), token+"x")
+		require.ErrorIs(t, err, ErrUnauthorized)
+
+		// the actor has the current role of the user
+		role = models.RoleEmployee
+		got, err = s.AuthenticateToken(context.TODO(), token)
+		require.NoError(t, err)
+		require.Equal(t, models.RoleEmployee, got.Role)
+
+		revokedAt = time.Now()
+		_, err = s.AuthenticateToken(context.TODO(), token)
+		require.ErrorIs(t, err, ErrUnauthorized)
+		revokedAt = time.Time{}
+
+		deleted = true
+		_, err = s.AuthenticateToken(context.TODO(), token)
+		require.ErrorIs(t, err, ErrUnauthorized)
+		deleted = false
+
+		// issued before tokens were bound to keys
+		legacy, _, err := auth.NewTokens([]byte("secret"), time.Hour).Issue(auth.Claims{UserID: 7, Role: models.RoleAdmin, OrgID: 3})
+		require.NoError(t, err)
+		_, err = s.AuthenticateToken(context.TODO(), legacy)
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("AdminToken", func(t *testing.T) {
+		token, _, err := s.IssueToken(WithActor(context.TODO(), admin))
+		require.NoError(t, err)
+
+		got, err := s.AuthenticateToken(context.TODO(), token)
+		require.NoError(t, err)
+		require.Equal(t, admin, got)
+
+		// the admin key is changed or removed from the configuration
+		tokens := WithTokens(auth.NewTokens([]byte("secret"), time.Hour))
+		for _, s := range []*Service{New(repo, WithAdminKey("new root"), tokens), New(repo, tokens)} {
+			_, err = s.AuthenticateToken(context.TODO(), token)
+			require.ErrorIs(t, err, ErrUnauthorized)
+		}
+	})
+}
+
+// TestAuthorize_NoActor checks that calls without an actor are denied, only internal calls are not restricted.
+func TestAuthorize_NoActor(t *testing.T) {
+	s, repo := setup(t)
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+
+	_, err := s.GetUser(context.TODO(), 1)
+	require.ErrorIs(t, err, ErrForbidden)
+	_, err = s.StartTask(context.TODO(), 1, TaskInfo{})
+	require.ErrorIs(t, err, ErrForbidden)
+	require.ErrorIs(t, s.CreateUser(context.TODO(), "1234 567890"), ErrForbidden)
+	require.ErrorIs(t, s.RevealPassports(context.TODO()), ErrForbidden)
+	_, err = s.ListOrgs(context.TODO())
+	require.ErrorIs(t, err, ErrForbidden)
+	_, err = s.EnrichPendingUsers(context.TODO())
+	require.ErrorIs(t, err, ErrForbidden)
+
+	_, err = s.GetUser(system(), 1)
+	require.NoError(t, err)
+	require.NoError(t, s.RevealPassports(system()))
+}
+
+func TestAuthorize(t *testing.T) {
+	s, repo := setup(t)
+
+	users := map[int]*models.User{
+		1: {ID: 1, Role: models.RoleAdmin},
+		2: {ID: 2, Role: models.RoleManager},
+		3: {ID: 3, Role: models.RoleEmployee, ManagerID: 2},
+		4: {ID: 4, Role: models.RoleEmployee},
+		5: {ID: 5, Role: models.RoleManager},
+	}
+	repo.ListTeamsFn = func(ctx context.Context) ([]models.Team, error) {
+		return []models.Team{{ID: 1, ManagerID: 5, MemberIDs: []int{4}}}, nil
+	}
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		if u, ok := users[id]; ok {
+			copied := *u
+			return &copied, nil
+		}
+		return nil, sql.ErrNoRows
+	}
+
+	as := func(id int) context.Context {
+		return WithActor(context.TODO(), Actor{UserID: id, Role: users[id].Role})
+	}
+	from := time.Now().Add(-time.Hour)
+
+	t.Run("Employee", func(t *testing.T) {
+		_, err := s.StartTask(as(3), 3, TaskInfo{})
+		require.NoError(t, err)
+
+		_, err = s.StartTask(as(3), 4, TaskInfo{})
+		require.ErrorIs(t, err, ErrForbidden)
+
+		require.ErrorIs(t, s.EndTask(as(3), 4, 1), ErrForbidden)
+
+		_, err = s.ListTasks(as(3), 4, models.TaskListOpts{})
+		require.ErrorIs(t, err, ErrForbidden)
+
+		_, err = s.Workload(as(4), 3, from, time.Time{})
+		require.ErrorIs(t, err, ErrForbidden)
+
+		require.ErrorIs(t, s.CreateUser(as(3), "1234 567890"), ErrForbidden)
+
+		_, err = s.CreateProject(as(3), "Time tracker", "")
+		require.ErrorIs(t, err, ErrForbidden)
+
+		require.ErrorIs(t, s.RevealPassports(as(3)), ErrForbidden)
+	})
+
+	t.Run("Manager", func(t *testing.T) {
+		_, err := s.Workload(as(2), 3, from, time.Time{})
+		require.NoError(t, err)
+
+		_, err = s.ListTasks(as(2), 3, models.TaskListOpts{})
+		require.NoError(t, err)
+
+		_, err = s.Workload(as(2), 4, from, time.Time{})
+		require.ErrorIs(t, err, ErrForbidden)
+
+		// managers read reports of the team but don't track time for it
+		_, err = s.StartTask(as(2), 3, TaskInfo{})
+		require.ErrorIs(t, err, ErrForbidden)
+
+		_, err = s.UpdateUser(as(2), 3, UserUpdate{})
+		require.ErrorIs(t, err, ErrForbidden)
+
+		require.ErrorIs(t, s.RevealPassports(as(2)), ErrForbidden)
+	})
+
+	t.Run("TeamManager", func(t *testing.T) {
+		_, err := s.Workload(as(5), 4, from, time.Time{})
+		require.NoError(t, err)
+
+		_, err = s.ListTasks(as(5), 4, models.TaskListOpts{})
+		require.NoError(t, err)
+
+		_, err = s.GetUser(as(5), 4)
+		require.NoError(t, err)
+
+		_, err = s.ListTasks(as(5), 3, models.TaskListOpts{})
+		require.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		_, err := s.Workload(as(1), 4, from, time.Time{})
+		require.NoError(t, err)
+
+		role := models.RoleManager
+		manager := 2
+		user, err := s.UpdateUser(as(1), 4, UserUpdate{Role: &role, ManagerID: &manager})
+		require.NoError(t, err)
+		require.Equal(t, models.RoleManager, user.Role)
+		require.Equal(t, 2, user.ManagerID)
+
+		require.NoError(t, s.RevealPassports(as(1)))
+
+		require.NoError(t, s.DeleteUser(as(1), 4))
+	})
+}
+
+func TestUpdateUser_InvalidRole(t *testing.T) {
+	s, repo := setup(t)
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+
+	role := models.Role("root")
+	_, err := s.UpdateUser(system(), 3, UserUpdate{Role: &role})
+	require.ErrorIs(t, err, ErrValidation)
+
+	self := 3
+	_, err = s.UpdateUser(system(), 3, UserUpdate{ManagerID: &self})
+	require.ErrorIs(t, err, ErrValidation)
+}
+
+func TestAPIKeys(t *testing.T) {
+	s, repo := setup(t)
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+
+	var stored *models.APIKey
+	repo.CreateAPIKeyFn = func(ctx context.Context, key *models.APIKey) error {
+		key.ID = 1
+		stored = key
+		return nil
+	}
+
+	ctx := WithActor(context.TODO(), Actor{UserID: 3, Role: models.RoleEmployee})
+
+	key, secret, err := s.CreateAPIKey(ctx, 3, " laptop ")
+	require.NoError(t, err)
+	require.Equal(t, "laptop", key.Name)
+	require.Equal(t, auth.HashKey(secret), stored.Hash)
+	require.NotContains(t, string(stored.Hash), secret)
+
+	_, _, err = s.CreateAPIKey(ctx, 4, "")
+	require.ErrorIs(t, err, ErrForbidden)
+
+	repo.RevokeAPIKeyFn = func(ctx context.Context, userID, id int) error {
+		return sql.ErrNoRows
+	}
+	require.ErrorIs(t, s.RevokeAPIKey(ctx, 3, 2), ErrNotFound)
+}
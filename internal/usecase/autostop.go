@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// autoStopLock is taken by AutoStopTasks, so replicas of the service don't stop the same tasks.
+const autoStopLock = "auto-stop-tasks"
+
+// AutoStopPolicy limits how long tasks may run unattended. A task exceeding it is ended
+// at the earliest boundary of the policy, not at the moment it's noticed.
+type AutoStopPolicy struct {
+	MaxDuration time.Duration  // tasks worked longer than this in all segments are stopped, zero disables the limit
+	Cutoff      time.Duration  // time of day running segments are stopped at, zero disables the cutoff, 24h is midnight
+	Location    *time.Location // of the cutoff, local time if nil
+}
+
+func (p AutoStopPolicy) IsEnabled() bool {
+	return p.MaxDuration > 0 || p.Cutoff > 0
+}
+
+// boundary returns the time the running task must be stopped at, zero if the policy doesn't limit it.
+// Time worked before the current segment counts towards the maximum, so pausing the task doesn't reset it.
+func (p AutoStopPolicy) boundary(task *models.Task) time.Time {
+	since := task.Segments[len(task.Segments)-1].Since
+
+	var at time.Time
+	if p.MaxDuration > 0 {
+		at = since.Add(max(p.MaxDuration-task.Worked(), 0))
+	}
+	if p.Cutoff > 0 {
+		if c := p.cutoffAfter(since); at.IsZero() || c.Before(at) {
+			at = c
+		}
+	}
+	return at
+}
+
+// cutoffAfter returns the first cutoff after t.
+func (p AutoStopPolicy) cutoffAfter(t time.Time) time.Time {
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	h, m, sec := int(p.Cutoff/time.Hour), int(p.Cutoff%time.Hour/time.Minute), int(p.Cutoff%time.Minute/time.Second)
+	local := t.In(loc)
+
+	c := time.Date(local.Year(), local.Month(), local.Day(), h, m, sec, 0, loc)
+	if !c.After(t) {
+		c = time.Date(local.Year(), local.Month(), local.Day()+1, h, m, sec, 0, loc)
+	}
+	return c
+}
+
+// WithAutoStop enables ending tasks which run longer than the policy allows, see AutoStopTasks.
+func WithAutoStop(policy AutoStopPolicy) Option {
+	return func(s *Service) {
+		s.autoStop = policy
+	}
+}
+
+// AutoStopTasks ends running tasks exceeding the auto-stop policy at the policy boundary and marks them auto-stopped.
+// Tasks of every organization are stopped unless the context acts within one.
+// Only one instance of the service stops tasks at a time, the others skip the run. It returns the number of stopped tasks.
+func (s *Service) AutoStopTasks(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "Service.AutoStopTasks")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return 0, err
+	}
+
+	if !s.autoStop.IsEnabled() {
+		return 0, nil
+	}
+
+	var stopped int
+	_, err := s.repo.WithLock(ctx, autoStopLock, func(ctx context.Context) error {
+		now := time.Now()
+
+		// any running task may have passed the cutoff, but only the ones started
+		// before now - max may have worked longer than the maximum duration
+		startedBefore := now
+		if s.autoStop.Cutoff == 0 {
+			startedBefore = now.Add(-s.autoStop.MaxDuration)
+		}
+
+		return s.forEachOrg(ctx, func(ctx context.Context) error {
+			tasks, err := s.repo.ListRunningTasks(ctx, startedBefore)
+			if err != nil {
+				return fmt.Errorf("list running tasks: %w", err)
+			}
+
+			for _, task := range tasks {
+				if len(task.Segments) == 0 {
+					continue
+				}
+
+				at := s.autoStop.boundary(&task)
+				if at.IsZero() || at.After(now) {
+					continue
+				}
+
+				task.End(at)
+				task.AutoStopped = true
+
+				err := s.repo.AutoStopTask(ctx, &task)
+				switch {
+				case errors.Is(err, sql.ErrNoRows):
+					// the user has ended or paused the task meanwhile
+					continue
+				case err != nil:
+					return fmt.Errorf("auto-stop task %d: %w", task.ID, err)
+				}
+
+				s.metrics.TaskEnded()
+				logging.FromContext(ctx).Info("Task auto-stopped", "task", task.ID, "user", task.UserID, "at", at)
+				stopped++
+			}
+
+			return nil
+		})
+	})
+
+	return stopped, err
+}
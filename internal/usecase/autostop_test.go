@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoStopPolicy_Boundary(t *testing.T) {
+	loc := time.FixedZone("MSK", 3*60*60)
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, 7, day, hour, min, 0, 0, loc)
+	}
+
+	tests := map[string]struct {
+		policy AutoStopPolicy
+		since  time.Time
+		want   time.Time
+	}{
+		"Disabled":          {AutoStopPolicy{}, at(1, 9, 0), time.Time{}},
+		"MaxDuration":       {AutoStopPolicy{MaxDuration: 10 * time.Hour}, at(1, 9, 0), at(1, 19, 0)},
+		"Cutoff":            {AutoStopPolicy{Cutoff: 20 * time.Hour, Location: loc}, at(1, 9, 0), at(1, 20, 0)},
+		"CutoffNextDay":     {AutoStopPolicy{Cutoff: 20 * time.Hour, Location: loc}, at(1, 21, 30), at(2, 20, 0)},
+		"CutoffExactly":     {AutoStopPolicy{Cutoff: 20 * time.Hour, Location: loc}, at(1, 20, 0), at(2, 20, 0)},
+		"Midnight":          {AutoStopPolicy{Cutoff: 24 * time.Hour, Location: loc}, at(1, 9, 0), at(2, 0, 0)},
+		"MaxBeforeCutoff":   {AutoStopPolicy{MaxDuration: 8 * time.Hour, Cutoff: 20 * time.Hour, Location: loc}, at(1, 9, 0), at(1, 17, 0)},
+		"CutoffBeforeMax":   {AutoStopPolicy{MaxDuration: 8 * time.Hour, Cutoff: 20 * time.Hour, Location: loc}, at(1, 15, 0), at(1, 20, 0)},
+		"CutoffInOtherZone": {AutoStopPolicy{Cutoff: 20 * time.Hour, Location: loc}, time.Date(2024, 7, 1, 18, 0, 0, 0, time.UTC), at(2, 20, 0)},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tt.policy.boundary(&models.Task{Since: tt.since, Segments: []models.TaskSegment{{Since: tt.since}}})
+			require.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
+
+// TestAutoStopPolicy_BoundaryPaused checks that pausing a task doesn't reset its maximum duration.
+func TestAutoStopPolicy_BoundaryPaused(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, 7, day, hour, 0, 0, 0, time.UTC)
+	}
+	policy := AutoStopPolicy{MaxDuration: 8 * time.Hour}
+
+	lunch := &models.Task{Since: at(1, 9), Segments: []models.TaskSegment{
+		{Since: at(1, 9), Until: at(1, 12)},
+		{Since: at(1, 13)},
+	}}
+	require.Equal(t, at(1, 18), policy.boundary(lunch))
+
+	// paused for a minute every few hours for days
+	task := &models.Task{Since: at(1, 0)}
+	for h := 0; h < 72; h += 4 {
+		task.Segments = append(task.Segments, models.TaskSegment{Since: at(1, h), Until: at(1, h+4).Add(-time.Minute)})
+	}
+	task.Segments = append(task.Segments, models.TaskSegment{Since: at(4, 0)})
+	require.Equal(t, at(4, 0), policy.boundary(task), "the maximum is exceeded before the task is resumed")
+}
+
+func TestAutoStopTasks(t *testing.T) {
+	repo := &repositoryMock{}
+	s := New(repo, WithAutoStop(AutoStopPolicy{MaxDuration: 12 * time.Hour}))
+
+	now := time.Now()
+	since := now.Add(-60 * time.Hour).Truncate(time.Second)
+	resumed := now.Add(-13 * time.Hour).Truncate(time.Second)
+
+	repo.WithLockFn = func(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+		require.Equal(t, autoStopLock, name)
+		return true, fn(ctx)
+	}
+	repo.ListOrgsFn = func(ctx context.Context) ([]models.Org, error) {
+		return []models.Org{{ID: models.DefaultOrgID}, {ID: 2}}, nil
+	}
+
+	// tasks of each organization are listed within it
+	var orgs []int
+	repo.ListRunningTasksFn = func(ctx context.Context, startedBefore time.Time) ([]models.Task, error) {
+		require.WithinDuration(t, now.Add(-12*time.Hour), startedBefore, time.Second)
+		orgID, err := tenant.Org(ctx)
+		require.NoError(t, err)
+		orgs = append(orgs, orgID)
+		if orgID != 2 {
+			return nil, nil
+		}
+		return []models.Task{{
+			ID:     7,
+			UserID: 99,
+			Since:  since,
+			Segments: []models.TaskSegment{
+				{Since: since, Until: since.Add(time.Hour)},
+				{Since: resumed},
+			},
+		}}, nil
+	}
+
+	var stopped *models.Task
+	repo.AutoStopTaskFn = func(ctx context.Context, task *models.Task) error {
+		orgID, _ := tenant.OrgFrom(ctx)
+		require.Equal(t, 2, orgID)
+		stopped = task
+		return nil
+	}
+
+	n, err := s.AutoStopTasks(system())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []int{models.DefaultOrgID, 2}, orgs)
+
+	// the task ends at the boundary of the policy, not now, the hour worked before the pause counts
+	end := resumed.Add(11 * time.Hour)
+	require.Equal(t, end, stopped.Until)
+	require.True(t, stopped.AutoStopped)
+	require.Equal(t, models.TaskFinished, stopped.State())
+	require.Equal(t, end, stopped.Segments[1].Until)
+	require.Equal(t, 12*60, stopped.Minutes)
+}
+
+func TestAutoStopTasks_Skipped(t *testing.T) {
+	tests := map[string]func(repo *repositoryMock){
+		"Locked": func(repo *repositoryMock) {
+			repo.WithLockFn = func(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+				return false, nil
+			}
+		},
+		"NotExceeded": func(repo *repositoryMock) {
+			repo.ListRunningTasksFn = func(ctx context.Context, startedBefore time.Time) ([]models.Task, error) {
+				since := time.Now().Add(-time.Hour)
+				return []models.Task{{ID: 7, Since: since, Segments: []models.TaskSegment{{Since: since}}}}, nil
+			}
+		},
+		"ChangedMeanwhile": func(repo *repositoryMock) {
+			repo.ListRunningTasksFn = func(ctx context.Context, startedBefore time.Time) ([]models.Task, error) {
+				since := time.Now().Add(-15 * time.Hour)
+				return []models.Task{{ID: 7, Since: since, Segments: []models.TaskSegment{{Since: since}}}}, nil
+			}
+			repo.AutoStopTaskFn = func(ctx context.Context, task *models.Task) error {
+				return sql.ErrNoRows
+			}
+		},
+	}
+
+	for name, prepare := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := &repositoryMock{
+				AutoStopTaskFn: func(ctx context.Context, task *models.Task) error {
+					t.Fatal("task must not be stopped")
+					return nil
+				},
+			}
+			prepare(repo)
+
+			s := New(repo, WithAutoStop(AutoStopPolicy{MaxDuration: 12 * time.Hour, Cutoff: 24 * time.Hour}))
+
+			n, err := s.AutoStopTasks(system())
+			require.NoError(t, err)
+			require.Zero(t, n)
+		})
+	}
+}
+
+func TestAutoStopTasks_Forbidden(t *testing.T) {
+	s := New(&repositoryMock{}, WithAutoStop(AutoStopPolicy{MaxDuration: time.Hour}))
+
+	ctx := WithActor(context.TODO(), Actor{UserID: 1, Role: models.RoleEmployee})
+	_, err := s.AutoStopTasks(ctx)
+	require.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestUpdateTask_ClearsAutoStopped(t *testing.T) {
+	s, repo := setup(t)
+
+	since := time.Now().Add(-5 * time.Hour).Truncate(time.Minute)
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.GetTaskFn = func(ctx context.Context, userID, id int) (*models.Task, error) {
+		task := &models.Task{ID: id, UserID: userID, AutoStopped: true}
+		task.SetPeriod(since, since.Add(4*time.Hour))
+		return task, nil
+	}
+	repo.OverlappingTaskFn = func(ctx context.Context, userID, excludeID int, from, to, now time.Time) (int, error) {
+		return 0, sql.ErrNoRows
+	}
+
+	title := "Renamed"
+	task, err := s.UpdateTask(system(), 99, 5, TaskUpdate{Title: &title})
+	require.NoError(t, err)
+	require.True(t, task.AutoStopped, "changing the title keeps the flag")
+
+	until := since.Add(time.Hour)
+	task, err = s.UpdateTask(system(), 99, 5, TaskUpdate{Until: &until})
+	require.NoError(t, err)
+	require.False(t, task.AutoStopped, "the end set by the user is not auto-stopped")
+}
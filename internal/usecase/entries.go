@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// TaskEntry describes a task logged after the fact.
+type TaskEntry struct {
+	TaskInfo
+	Since time.Time
+	Until time.Time
+}
+
+// TaskUpdate contains task fields to change, nil fields are left as is.
+type TaskUpdate struct {
+	Title       *string
+	Description *string
+	Since       *time.Time
+	Until       *time.Time // ends the running task
+}
+
+// CreateTask logs a finished task with explicit bounds.
+func (s *Service) CreateTask(ctx context.Context, userID int, entry TaskEntry) (*models.Task, error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateTask")
+	defer span.End()
+
+	if err := s.authorizeOwner(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Until.IsZero() {
+		return nil, invalid("until_required", "until", "until is required")
+	}
+
+	task := &models.Task{UserID: user.ID}
+	if err := s.describeTask(ctx, task, entry.TaskInfo); err != nil {
+		return nil, err
+	}
+	task.SetPeriod(entry.Since, entry.Until)
+
+	if err := s.checkPeriod(ctx, task); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateTask(ctx, task); err != nil {
+		return nil, fmt.Errorf("create task: %w", err)
+	}
+
+	return task, nil
+}
+
+// UpdateTask changes the task, changed bounds are validated the same way as for new entries.
+func (s *Service) UpdateTask(ctx context.Context, userID, taskID int, upd TaskUpdate) (*models.Task, error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdateTask")
+	defer span.End()
+
+	task, err := s.getTask(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if upd.Title != nil {
+		task.Title = strings.TrimSpace(*upd.Title)
+	}
+	if upd.Description != nil {
+		task.Description = *upd.Description
+	}
+
+	read := task.Revision()
+	wasFinished := task.IsFinished()
+	if upd.Since != nil || upd.Until != nil {
+		since, until := task.Since, task.Until
+		if upd.Since != nil {
+			since = *upd.Since
+		}
+		if upd.Until != nil {
+			until = *upd.Until
+			// the user has set the end, so it's not the one chosen by the policy anymore
+			task.AutoStopped = false
+		}
+		task.SetPeriod(since, until)
+
+		if err := s.checkPeriod(ctx, task); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.updateTask(ctx, task, read); err != nil {
+		return nil, err
+	}
+	if !wasFinished && task.IsFinished() {
+		s.metrics.TaskEnded()
+	}
+
+	return task, nil
+}
+
+// checkPeriod validates bounds of the task: it must not be in the future or overlap other tasks of the user.
+func (s *Service) checkPeriod(ctx context.Context, task *models.Task) error {
+	now := time.Now()
+
+	switch {
+	case task.Since.IsZero():
+		return invalid("since_required", "since", "since is required")
+	case task.Since.After(now):
+		return invalid("future_entry", "since", "since must not be in the future")
+	case task.IsFinished() && !task.Until.After(task.Since):
+		return invalid("invalid_period", "until", "until must be after since")
+	case task.Until.After(now):
+		return invalid("future_entry", "until", "until must not be in the future")
+	}
+
+	until := task.Until
+	if until.IsZero() {
+		until = now
+	}
+
+	id, err := s.repo.OverlappingTask(ctx, task.UserID, task.ID, task.Since, until, now)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("find overlapping task: %w", err)
+	default:
+		return conflict("task_overlap", fmt.Sprintf("task overlaps task %d", id))
+	}
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTask_OK(t *testing.T) {
+	s, repo := setup(t)
+
+	since := time.Now().Add(-3 * time.Hour).Truncate(time.Minute)
+	until := since.Add(90 * time.Minute)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.OverlappingTaskFn = func(ctx context.Context, userID, excludeID int, from, to, now time.Time) (int, error) {
+		require.Equal(t, 99, userID)
+		require.Zero(t, excludeID)
+		require.Equal(t, since, from)
+		require.Equal(t, until, to)
+		return 0, sql.ErrNoRows
+	}
+	repo.CreateTaskFn = func(ctx context.Context, task *models.Task) error {
+		task.ID = 5
+		return nil
+	}
+
+	task, err := s.CreateTask(system(), 99, TaskEntry{TaskInfo: TaskInfo{Title: " Review "}, Since: since, Until: until})
+	require.NoError(t, err)
+	require.Equal(t, 5, task.ID)
+	require.Equal(t, "Review", task.Title)
+	require.Equal(t, 90, task.Minutes)
+	require.Equal(t, models.TaskFinished, task.State())
+	require.Equal(t, []models.TaskSegment{{Since: since, Until: until}}, task.Segments)
+}
+
+func TestCreateTask_Invalid(t *testing.T) {
+	now := time.Now()
+
+	tests := map[string]struct {
+		entry TaskEntry
+		code  string
+	}{
+		"NoSince":    {TaskEntry{Until: now.Add(-time.Hour)}, "since_required"},
+		"NoUntil":    {TaskEntry{Since: now.Add(-time.Hour)}, "until_required"},
+		"Reversed":   {TaskEntry{Since: now.Add(-time.Hour), Until: now.Add(-2 * time.Hour)}, "invalid_period"},
+		"Empty":      {TaskEntry{Since: now.Add(-time.Hour), Until: now.Add(-time.Hour)}, "invalid_period"},
+		"FutureEnd":  {TaskEntry{Since: now.Add(-time.Hour), Until: now.Add(time.Hour)}, "future_entry"},
+		"FutureTask": {TaskEntry{Since: now.Add(time.Hour), Until: now.Add(2 * time.Hour)}, "future_entry"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, repo := setup(t)
+			repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+				return &models.User{ID: id}, nil
+			}
+
+			_, err := s.CreateTask(system(), 99, tt.entry)
+			require.ErrorIs(t, err, ErrValidation)
+
+			var domainErr *Error
+			require.ErrorAs(t, err, &domainErr)
+			require.Equal(t, tt.code, domainErr.Code)
+		})
+	}
+}
+
+func TestCreateTask_Overlap(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.OverlappingTaskFn = func(ctx context.Context, userID, excludeID int, since, until, now time.Time) (int, error) {
+		return 7, nil
+	}
+
+	_, err := s.CreateTask(system(), 99, TaskEntry{Since: time.Now().Add(-2 * time.Hour), Until: time.Now().Add(-time.Hour)})
+	require.ErrorIs(t, err, ErrConflict)
+	require.EqualError(t, err, "task overlaps task 7")
+}
+
+func TestUpdateTask_EndRunning(t *testing.T) {
+	s, repo := setup(t)
+
+	now := time.Now()
+	task := &models.Task{
+		ID:     3,
+		UserID: 99,
+		Since:  now.Add(-10 * time.Hour),
+		Segments: []models.TaskSegment{
+			{Since: now.Add(-10 * time.Hour), Until: now.Add(-9 * time.Hour)},
+			{Since: now.Add(-8 * time.Hour)},
+		},
+	}
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.GetTaskFn = func(ctx context.Context, userID, id int) (*models.Task, error) {
+		return task, nil
+	}
+	repo.OverlappingTaskFn = func(ctx context.Context, userID, excludeID int, since, until, now time.Time) (int, error) {
+		require.Equal(t, 3, excludeID)
+		return 0, sql.ErrNoRows
+	}
+
+	var updated *models.Task
+	repo.UpdateTaskFn = func(ctx context.Context, task *models.Task, read models.TaskRevision) error {
+		updated = task
+		return nil
+	}
+
+	// the task was forgotten running
+	until := now.Add(-7 * time.Hour)
+	_, err := s.UpdateTask(system(), 99, 3, TaskUpdate{Until: &until})
+	require.NoError(t, err)
+	require.Equal(t, models.TaskFinished, updated.State())
+	require.Equal(t, 120, updated.Minutes)
+	require.Equal(t, until, updated.Segments[1].Until)
+}
+
+func TestUpdateTask_ClipSegments(t *testing.T) {
+	s, repo := setup(t)
+
+	now := time.Now()
+	task := &models.Task{
+		ID:     3,
+		UserID: 99,
+		Since:  now.Add(-10 * time.Hour),
+		Until:  now.Add(-5 * time.Hour),
+		Segments: []models.TaskSegment{
+			{Since: now.Add(-10 * time.Hour), Until: now.Add(-9 * time.Hour)},
+			{Since: now.Add(-8 * time.Hour), Until: now.Add(-5 * time.Hour)},
+		},
+	}
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.GetTaskFn = func(ctx context.Context, userID, id int) (*models.Task, error) {
+		return task, nil
+	}
+
+	since := now.Add(-8*time.Hour - 30*time.Minute)
+	title := "Retro"
+	updated, err := s.UpdateTask(system(), 99, 3, TaskUpdate{Title: &title, Since: &since})
+	require.NoError(t, err)
+	require.Equal(t, "Retro", updated.Title)
+	require.Equal(t, []models.TaskSegment{{Since: since, Until: now.Add(-5 * time.Hour)}}, updated.Segments)
+	require.Equal(t, 210, updated.Minutes)
+}
+
+func TestUpdateTask_Invalid(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.GetTaskFn = func(ctx context.Context, userID, id int) (*models.Task, error) {
+		return &models.Task{ID: 3, UserID: 99, Since: time.Now().Add(-time.Hour), Until: time.Now()}, nil
+	}
+
+	since := time.Now().Add(time.Minute)
+	_, err := s.UpdateTask(system(), 99, 3, TaskUpdate{Since: &since})
+	require.ErrorIs(t, err, ErrValidation)
+}
@@ -1,5 +1,84 @@
 package usecase
 
-import "errors"
+import "strings"
 
-var ErrNotFound = errors.New("not found")
+// Kind is a class of domain errors, API maps it to a response status.
+type Kind string
+
+const (
+	KindNotFound   Kind = "not_found"
+	KindValidation Kind = "validation"
+	KindConflict   Kind = "conflict"
+	KindForbidden  Kind = "forbidden"
+
+	KindUnauthorized Kind = "unauthorized" // caller is not authenticated
+)
+
+var (
+	ErrNotFound   = &Error{Kind: KindNotFound, Message: "not found"}
+	ErrValidation = &Error{Kind: KindValidation, Message: "validation failed"}
+	ErrConflict   = &Error{Kind: KindConflict, Message: "already exists"}
+	ErrForbidden  = &Error{Kind: KindForbidden, Message: "forbidden"}
+
+	ErrUnauthorized = &Error{Kind: KindUnauthorized, Message: "unauthorized"}
+)
+
+// Error is a domain error with a stable machine-readable code.
+// errors.Is matches it with the kind sentinel (ErrNotFound etc.) or an error with the same code.
+type Error struct {
+	Kind    Kind
+	Code    string // e.g. user_not_found, empty for kind sentinels
+	Message string
+	Fields  []FieldError // invalid input fields for validation errors
+}
+
+// FieldError describes why an input field is invalid.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 || e.Message != "" {
+		return e.Message
+	}
+
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	if t.Code == "" {
+		return t.Kind == e.Kind
+	}
+	return t.Code == e.Code
+}
+
+func notFound(code, msg string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: msg}
+}
+
+func conflict(code, msg string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: msg}
+}
+
+func forbidden(code, msg string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: msg}
+}
+
+// invalid returns a validation error of a single field.
+func invalid(code, field, msg string) *Error {
+	return &Error{
+		Kind:    KindValidation,
+		Code:    code,
+		Message: msg,
+		Fields:  []FieldError{{Field: field, Message: msg}},
+	}
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestError_Is(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", errUserNotFound)
+
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, err, errUserNotFound)
+	require.NotErrorIs(t, err, errTaskNotFound)
+	require.NotErrorIs(t, err, ErrConflict)
+	require.NotErrorIs(t, errors.New("not found"), ErrNotFound)
+}
+
+func TestCreateUser_ValidationError(t *testing.T) {
+	s, _ := setup(t)
+
+	err := s.CreateUser(system(), "1234 567890 extra")
+	require.ErrorIs(t, err, ErrValidation)
+
+	var domainErr *Error
+	require.ErrorAs(t, err, &domainErr)
+	require.Equal(t, "invalid_passport", domainErr.Code)
+	require.Equal(t, "passportNumber", domainErr.Fields[0].Field)
+}
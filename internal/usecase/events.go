@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Nicholas2012/time-tracker/internal/events"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// WithEvents enables streaming of live activity through the broker.
+func WithEvents(broker *events.Broker) Option {
+	return func(s *Service) {
+		s.events = broker
+	}
+}
+
+// SubscribeEvents subscribes to live activity of the user or the members of the team of the filter,
+// events after lastEventID are replayed unless it's zero. Admins may subscribe to all events,
+// other users get their own events unless they may read the ones of the filter.
+// Members of the team are resolved once, so changes of the team take effect when the subscriber reconnects.
+func (s *Service) SubscribeEvents(ctx context.Context, filter models.EventFilter, lastEventID int) (*events.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "Service.SubscribeEvents")
+	defer span.End()
+
+	if s.events == nil {
+		return nil, errors.New("events are not enabled")
+	}
+	if filter.UserID != 0 && filter.TeamID != 0 {
+		return nil, invalid("invalid_filter", "team", "filter by a user or a team, not both")
+	}
+	if lastEventID < 0 {
+		return nil, invalid("invalid_event_id", "Last-Event-ID", "last event ID must not be negative")
+	}
+
+	actor, ok := ActorFrom(ctx)
+	if !ok {
+		return nil, errAccessDenied
+	}
+
+	var users map[int]bool
+	switch {
+	case filter.TeamID != 0:
+		team, err := s.getTeam(ctx, filter.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorizeTeamReader(ctx, team); err != nil {
+			return nil, err
+		}
+
+		users = make(map[int]bool, len(team.MemberIDs))
+		for _, id := range team.MemberIDs {
+			users[id] = true
+		}
+	case filter.UserID != 0:
+		if _, err := s.getUser(ctx, filter.UserID); err != nil {
+			return nil, err
+		}
+		if err := s.authorizeReader(ctx, filter.UserID); err != nil {
+			return nil, err
+		}
+
+		users = map[int]bool{filter.UserID: true}
+	case !actor.system && actor.Role != models.RoleAdmin:
+		users = map[int]bool{actor.UserID: true}
+	}
+
+	sub, err := s.events.Subscribe(ctx, lastEventID, func(e *models.Event) bool {
+		return users == nil || users[e.UserID]
+	})
+	if err != nil {
+		return nil, fmt.Errorf("subscribe to events: %w", err)
+	}
+
+	return sub, nil
+}
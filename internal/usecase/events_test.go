@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/audit"
+	"github.com/Nicholas2012/time-tracker/internal/events"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+	"github.com/stretchr/testify/require"
+)
+
+// publishTasks publishes started tasks of the users.
+func publishTasks(t *testing.T, broker *events.Broker, userIDs ...int) {
+	for i, userID := range userIDs {
+		entry, err := audit.TaskEntry(system(), models.AuditCreate, nil, &models.Task{ID: i + 1, UserID: userID, Since: time.Now()})
+		require.NoError(t, err)
+		entry.ID = i + 1
+		broker.Publish(models.DefaultOrgID, entry)
+	}
+}
+
+// received returns users of the queued events.
+func received(sub *events.Subscription) []int {
+	var users []int
+	for len(sub.Events()) > 0 {
+		users = append(users, (<-sub.Events()).UserID)
+	}
+	return users
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	s, repo := setup(t)
+	broker := events.NewBroker(repo)
+	s.events = broker
+
+	repo.GetTeamFn = func(ctx context.Context, id int) (*models.Team, error) {
+		return &models.Team{ID: id, ManagerID: 1, MemberIDs: []int{2, 3}}, nil
+	}
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id, ManagerID: 1}, nil
+	}
+
+	ctx := tenant.WithOrg(system(), models.DefaultOrgID)
+	admin := WithActor(ctx, Actor{UserID: 9, Role: models.RoleAdmin})
+	manager := WithActor(ctx, Actor{UserID: 1, Role: models.RoleManager})
+	employee := WithActor(ctx, Actor{UserID: 2, Role: models.RoleEmployee})
+
+	for _, tc := range []struct {
+		name   string
+		ctx    context.Context
+		filter models.EventFilter
+		want   []int
+	}{
+		{"All", admin, models.EventFilter{}, []int{1, 2, 3, 4}},
+		{"Team", manager, models.EventFilter{TeamID: 5}, []int{2, 3}},
+		{"Report", manager, models.EventFilter{UserID: 3}, []int{3}},
+		{"Own", employee, models.EventFilter{}, []int{2}},
+		{"OwnFilter", employee, models.EventFilter{UserID: 2}, []int{2}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sub, err := s.SubscribeEvents(tc.ctx, tc.filter, 0)
+			require.NoError(t, err)
+			defer sub.Close()
+
+			publishTasks(t, broker, 1, 2, 3, 4)
+			require.Equal(t, tc.want, received(sub))
+		})
+	}
+
+	t.Run("Forbidden", func(t *testing.T) {
+		_, err := s.SubscribeEvents(employee, models.EventFilter{UserID: 3}, 0)
+		require.ErrorIs(t, err, ErrForbidden)
+
+		_, err = s.SubscribeEvents(employee, models.EventFilter{TeamID: 5}, 0)
+		require.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := s.SubscribeEvents(admin, models.EventFilter{UserID: 2, TeamID: 5}, 0)
+		require.ErrorIs(t, err, ErrValidation)
+
+		_, err = s.SubscribeEvents(admin, models.EventFilter{}, -1)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		repo.ListAuditEntriesAfterFn = func(ctx context.Context, afterID, limit int) ([]models.AuditEntry, error) {
+			require.Equal(t, 41, afterID)
+			entry, err := audit.TaskEntry(ctx, models.AuditCreate, nil, &models.Task{ID: 7, UserID: 2, Since: time.Now()})
+			require.NoError(t, err)
+			entry.ID = 42
+			return []models.AuditEntry{*entry}, nil
+		}
+
+		sub, err := s.SubscribeEvents(employee, models.EventFilter{}, 41)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		require.Len(t, sub.Replay(), 1)
+		require.Equal(t, 42, sub.Replay()[0].ID)
+	})
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/auth"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+var errInvalidFeedToken = &Error{Kind: KindUnauthorized, Code: "invalid_feed_token", Message: "invalid feed token"}
+
+// feedMonths is the period of the calendar feed, older tasks are left out as calendar clients poll the feed often
+// and the whole history of a user may be large.
+const feedMonths = 3
+
+// IssueFeedToken replaces the calendar feed token of the user with a new one. The token is returned only here,
+// only its hash is stored.
+func (s *Service) IssueFeedToken(ctx context.Context, userID int) (string, error) {
+	ctx, span := tracer.Start(ctx, "Service.IssueFeedToken")
+	defer span.End()
+
+	if err := s.authorizeOwner(ctx, userID); err != nil {
+		return "", err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	token, hash, err := auth.GenerateFeedToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.SetFeedToken(ctx, user.ID, hash); err != nil {
+		return "", fmt.Errorf("set feed token: %w", err)
+	}
+
+	return token, nil
+}
+
+// RevokeFeedToken removes the calendar feed token of the user, the feed is no longer available.
+func (s *Service) RevokeFeedToken(ctx context.Context, userID int) error {
+	ctx, span := tracer.Start(ctx, "Service.RevokeFeedToken")
+	defer span.End()
+
+	if err := s.authorizeOwner(ctx, userID); err != nil {
+		return err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SetFeedToken(ctx, user.ID, nil); err != nil {
+		return fmt.Errorf("set feed token: %w", err)
+	}
+
+	return nil
+}
+
+// TaskFeed returns tasks of the user within the last feedMonths and the running ones ordered by start
+// for the calendar feed, the feed token replaces credentials because calendar clients can't send them.
+func (s *Service) TaskFeed(ctx context.Context, userID int, token string) ([]models.Task, error) {
+	ctx, span := tracer.Start(ctx, "Service.TaskFeed")
+	defer span.End()
+
+	hash, err := s.repo.GetFeedToken(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errInvalidFeedToken
+		}
+		return nil, fmt.Errorf("get feed token: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare(auth.HashKey(token), hash) != 1 {
+		return nil, errInvalidFeedToken
+	}
+
+	list, err := s.repo.ListTasks(ctx, userID, models.TaskListOpts{
+		TaskFilter: models.TaskFilter{From: time.Now().AddDate(0, -feedMonths, 0)},
+		Sort:       models.TaskSortSince,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+
+	return list.Tasks, nil
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/auth"
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedToken(t *testing.T) {
+	s, repo := setup(t)
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+
+	hashes := map[int][]byte{}
+	repo.SetFeedTokenFn = func(ctx context.Context, userID int, hash []byte) error {
+		hashes[userID] = hash
+		return nil
+	}
+	repo.GetFeedTokenFn = func(ctx context.Context, userID int) ([]byte, error) {
+		if hashes[userID] == nil {
+			return nil, sql.ErrNoRows
+		}
+		return hashes[userID], nil
+	}
+	repo.ListTasksFn = func(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+		require.Equal(t, models.TaskSortSince, opts.Sort)
+		require.Zero(t, opts.Limit)
+		// the feed covers recent tasks only, the filter keeps running tasks whenever they started
+		require.WithinDuration(t, time.Now().AddDate(0, -feedMonths, 0), opts.From, time.Minute)
+		require.True(t, opts.To.IsZero())
+		return &models.TaskList{Tasks: []models.Task{{ID: 1, UserID: userID}}}, nil
+	}
+
+	ctx := WithActor(context.TODO(), Actor{UserID: 3, Role: models.RoleEmployee})
+
+	_, err := s.IssueFeedToken(ctx, 4)
+	require.ErrorIs(t, err, ErrForbidden)
+
+	token, err := s.IssueFeedToken(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, auth.HashKey(token), hashes[3])
+
+	tasks, err := s.TaskFeed(system(), 3, token)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	_, err = s.TaskFeed(system(), 3, token+"x")
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = s.TaskFeed(system(), 4, token)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	require.NoError(t, s.RevokeFeedToken(ctx, 3))
+	_, err = s.TaskFeed(system(), 3, token)
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
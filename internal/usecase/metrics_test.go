@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/nameservice"
+	"github.com/Nicholas2012/time-tracker/internal/nameservice/nameservicetest"
+	"github.com/stretchr/testify/require"
+)
+
+type metricsMock struct {
+	started, ended, created int
+	nameService             []string
+}
+
+func (m *metricsMock) TaskStarted() { m.started++ }
+func (m *metricsMock) TaskEnded()   { m.ended++ }
+func (m *metricsMock) UserCreated() { m.created++ }
+func (m *metricsMock) NameServiceCalled(outcome string) {
+	m.nameService = append(m.nameService, outcome)
+}
+
+func TestMetrics_Tasks(t *testing.T) {
+	repo := &repositoryMock{}
+	m := &metricsMock{}
+	s := New(repo, WithMetrics(m))
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.SwitchTaskFn = func(ctx context.Context, task *models.Task) (*models.Task, error) {
+		return &models.Task{ID: 1, Since: task.Since.Add(-time.Hour), Until: task.Since}, nil
+	}
+	repo.GetTaskFn = func(ctx context.Context, userID, id int) (*models.Task, error) {
+		return models.NewTask(userID), nil
+	}
+
+	_, _, err := s.SwitchTask(system(), 99, TaskInfo{})
+	require.NoError(t, err)
+	require.NoError(t, s.EndTask(system(), 99, 2))
+
+	require.Equal(t, 1, m.started)
+	require.Equal(t, 2, m.ended)
+}
+
+func TestMetrics_NameService(t *testing.T) {
+	ns := nameservicetest.NewServer(t)
+	ns.Add(models.Passport{Series: "1234", Number: "567890"}, nameservice.People{Name: "Ivan"})
+	ns.FailNext(2) // the first attempt and the only retry
+
+	m := &metricsMock{}
+	s := New(&repositoryMock{}, WithMetrics(m), WithNameService(newNameClient(ns), NamePolicyDefer))
+
+	require.NoError(t, s.CreateUser(system(), "1234 567890"))
+	require.NoError(t, s.CreateUser(system(), "1234 567890"))
+	require.Error(t, s.CreateUser(system(), "1234 111111"))
+
+	require.Equal(t, []string{NameServiceUnavailable, NameServiceOK, NameServiceNotFound}, m.nameService)
+	require.Equal(t, 2, m.created)
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
+)
+
+var (
+	errOrgNotFound  = notFound("org_not_found", "organization not found")
+	errOrgForbidden = forbidden("org_forbidden", "access to the organization denied")
+)
+
+// ResolveOrg returns the organization requests of the actor of the context act within, requested is the one
+// asked for by the caller or zero. Users act within the organization of their credentials only, operators
+// holding the admin key choose any and act within the default one unless they do.
+func (s *Service) ResolveOrg(ctx context.Context, requested int) (int, error) {
+	ctx, span := tracer.Start(ctx, "Service.ResolveOrg")
+	defer span.End()
+
+	actor, ok := ActorFrom(ctx)
+	if !ok {
+		return 0, errAccessDenied
+	}
+	if !actor.system && !actor.isOperator() {
+		if requested != 0 && requested != actor.OrgID {
+			return 0, errOrgForbidden
+		}
+		return actor.OrgID, nil
+	}
+
+	if requested == 0 {
+		return models.DefaultOrgID, nil
+	}
+	if _, err := s.repo.GetOrg(ctx, requested); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errOrgNotFound
+		}
+		return 0, fmt.Errorf("get organization: %w", err)
+	}
+
+	return requested, nil
+}
+
+// CreateOrg creates an organization, only operators may.
+func (s *Service) CreateOrg(ctx context.Context, name string) (*models.Org, error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateOrg")
+	defer span.End()
+
+	if err := authorizeOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	org := &models.Org{Name: strings.TrimSpace(name)}
+	if org.Name == "" {
+		return nil, invalid("name_required", "name", "organization name is required")
+	}
+
+	if err := s.repo.CreateOrg(ctx, org); err != nil {
+		if errors.Is(err, models.ErrDuplicate) {
+			return nil, conflict("org_exists", fmt.Sprintf("organization %q already exists", org.Name))
+		}
+		return nil, fmt.Errorf("create organization: %w", err)
+	}
+
+	return org, nil
+}
+
+// ListOrgs returns all organizations, only operators may list them.
+func (s *Service) ListOrgs(ctx context.Context) ([]models.Org, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListOrgs")
+	defer span.End()
+
+	if err := authorizeOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	orgs, err := s.repo.ListOrgs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list organizations: %w", err)
+	}
+
+	return orgs, nil
+}
+
+// authorizeOperator checks that the actor holds the admin key, which isn't bound to an organization.
+func authorizeOperator(ctx context.Context) error {
+	actor, ok := ActorFrom(ctx)
+	if ok && (actor.system || actor.isOperator()) {
+		return nil
+	}
+
+	return errAccessDenied
+}
+
+// forEachOrg calls fn within the organization of the context. Internal calls made outside of any,
+// as the ones of background workers, are made within each organization in turn.
+func (s *Service) forEachOrg(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := tenant.OrgFrom(ctx); ok {
+		return fn(ctx)
+	}
+
+	orgs, err := s.repo.ListOrgs(ctx)
+	if err != nil {
+		return fmt.Errorf("list organizations: %w", err)
+	}
+
+	for _, org := range orgs {
+		if err := fn(tenant.WithOrg(ctx, org.ID)); err != nil {
+			return fmt.Errorf("organization %d: %w", org.ID, err)
+		}
+	}
+
+	return nil
+}
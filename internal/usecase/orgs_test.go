@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveOrg(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetOrgFn = func(ctx context.Context, id int) (*models.Org, error) {
+		if id == 2 {
+			return &models.Org{ID: 2, Name: "Acme"}, nil
+		}
+		return nil, sql.ErrNoRows
+	}
+
+	operator := WithActor(context.TODO(), Actor{Role: models.RoleAdmin})
+	user := WithActor(context.TODO(), Actor{UserID: 7, Role: models.RoleAdmin, OrgID: 2})
+
+	tests := map[string]struct {
+		ctx       context.Context
+		requested int
+		want      int
+		err       error
+	}{
+		"OperatorDefault": {operator, 0, models.DefaultOrgID, nil},
+		"OperatorChosen":  {operator, 2, 2, nil},
+		"OperatorUnknown": {operator, 5, 0, errOrgNotFound},
+		"User":            {user, 0, 2, nil},
+		"UserOwn":         {user, 2, 2, nil},
+		"UserOther":       {user, models.DefaultOrgID, 0, errOrgForbidden},
+		"NoActor":         {context.TODO(), 0, 0, errAccessDenied},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			orgID, err := s.ResolveOrg(tt.ctx, tt.requested)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, orgID)
+		})
+	}
+}
+
+func TestCreateOrg(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.CreateOrgFn = func(ctx context.Context, org *models.Org) error {
+		if org.Name == "Acme" {
+			return models.ErrDuplicate
+		}
+		org.ID = 3
+		return nil
+	}
+
+	operator := WithActor(context.TODO(), Actor{Role: models.RoleAdmin})
+
+	org, err := s.CreateOrg(operator, " Initech ")
+	require.NoError(t, err)
+	require.Equal(t, &models.Org{ID: 3, Name: "Initech"}, org)
+
+	_, err = s.CreateOrg(operator, "Acme")
+	require.ErrorIs(t, err, &Error{Code: "org_exists"})
+
+	_, err = s.CreateOrg(operator, " ")
+	require.ErrorIs(t, err, ErrValidation)
+
+	// admins of an organization don't manage organizations
+	admin := WithActor(context.TODO(), Actor{UserID: 1, Role: models.RoleAdmin, OrgID: models.DefaultOrgID})
+	_, err = s.CreateOrg(admin, "Globex")
+	require.ErrorIs(t, err, ErrForbidden)
+	_, err = s.ListOrgs(admin)
+	require.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+var errProjectNotFound = notFound("project_not_found", "project not found")
+
+// ProjectUpdate holds fields to change in a project, nil fields are left as is.
+type ProjectUpdate struct {
+	Name        *string
+	Description *string
+}
+
+func (s *Service) CreateProject(ctx context.Context, name, description string) (*models.Project, error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateProject")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleManager, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	project := &models.Project{
+		Name:        strings.TrimSpace(name),
+		Description: description,
+	}
+	if project.Name == "" {
+		return nil, invalid("name_required", "name", "project name is required")
+	}
+
+	if err := s.repo.CreateProject(ctx, project); err != nil {
+		if errors.Is(err, models.ErrDuplicate) {
+			return nil, conflict("project_exists", fmt.Sprintf("project %q already exists", project.Name))
+		}
+		return nil, fmt.Errorf("create project: %w", err)
+	}
+
+	return project, nil
+}
+
+func (s *Service) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetProject")
+	defer span.End()
+
+	project, err := s.repo.GetProject(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errProjectNotFound
+		}
+		return nil, fmt.Errorf("get project: %w", err)
+	}
+
+	return project, nil
+}
+
+func (s *Service) ListProjects(ctx context.Context) ([]models.Project, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListProjects")
+	defer span.End()
+
+	projects, err := s.repo.ListProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+func (s *Service) UpdateProject(ctx context.Context, id int, upd ProjectUpdate) (*models.Project, error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdateProject")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleManager, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	project, err := s.GetProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if upd.Name != nil {
+		project.Name = strings.TrimSpace(*upd.Name)
+		if project.Name == "" {
+			return nil, invalid("name_required", "name", "project name is required")
+		}
+	}
+	if upd.Description != nil {
+		project.Description = *upd.Description
+	}
+
+	if err := s.repo.UpdateProject(ctx, project); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, errProjectNotFound
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, conflict("project_exists", fmt.Sprintf("project %q already exists", project.Name))
+		}
+		return nil, fmt.Errorf("update project: %w", err)
+	}
+
+	return project, nil
+}
+
+// DeleteProject deletes a project, tasks of the project are kept without a project.
+func (s *Service) DeleteProject(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteProject")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleManager, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteProject(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errProjectNotFound
+		}
+		return fmt.Errorf("delete project: %w", err)
+	}
+
+	return nil
+}
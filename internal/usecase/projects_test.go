@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProject_OK(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.CreateProjectFn = func(ctx context.Context, project *models.Project) error {
+		require.Equal(t, "Time tracker", project.Name)
+		project.ID = 3
+		return nil
+	}
+
+	project, err := s.CreateProject(system(), " Time tracker ", "Internal")
+	require.NoError(t, err)
+	require.Equal(t, &models.Project{ID: 3, Name: "Time tracker", Description: "Internal"}, project)
+}
+
+func TestCreateProject_EmptyName(t *testing.T) {
+	s, _ := setup(t)
+
+	_, err := s.CreateProject(system(), " ", "")
+	require.EqualError(t, err, "project name is required")
+}
+
+func TestCreateProject_Duplicate(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.CreateProjectFn = func(ctx context.Context, project *models.Project) error {
+		return models.ErrDuplicate
+	}
+
+	_, err := s.CreateProject(system(), "Time tracker", "")
+	require.ErrorIs(t, err, ErrConflict)
+}
+
+func TestUpdateProject_OK(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetProjectFn = func(ctx context.Context, id int) (*models.Project, error) {
+		return &models.Project{ID: id, Name: "Time tracker", Description: "Internal"}, nil
+	}
+	repo.UpdateProjectFn = func(ctx context.Context, project *models.Project) error {
+		return nil
+	}
+
+	description := "Public"
+	project, err := s.UpdateProject(system(), 3, ProjectUpdate{Description: &description})
+	require.NoError(t, err)
+	require.Equal(t, &models.Project{ID: 3, Name: "Time tracker", Description: "Public"}, project)
+}
+
+func TestDeleteProject_NotFound(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.DeleteProjectFn = func(ctx context.Context, id int) error {
+		return sql.ErrNoRows
+	}
+
+	err := s.DeleteProject(system(), 3)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCreateTag_Duplicate(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.CreateTagFn = func(ctx context.Context, tag *models.Tag) error {
+		return models.ErrDuplicate
+	}
+
+	_, err := s.CreateTag(system(), "bug")
+	require.ErrorIs(t, err, ErrConflict)
+}
+
+func TestUpdateTag_NotFound(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.UpdateTagFn = func(ctx context.Context, tag *models.Tag) error {
+		require.Equal(t, &models.Tag{ID: 4, Name: "bug"}, tag)
+		return sql.ErrNoRows
+	}
+
+	_, err := s.UpdateTag(system(), 4, "bug")
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -2,16 +2,66 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/Nicholas2012/time-tracker/internal/models"
 )
 
 type Repository interface {
+	CreateOrg(ctx context.Context, org *models.Org) error
+	GetOrg(ctx context.Context, id int) (*models.Org, error)
+	ListOrgs(ctx context.Context) ([]models.Org, error)
+
 	CreateUser(ctx context.Context, user *models.User) error
 	GetUser(ctx context.Context, id int) (*models.User, error)
+	GetUserByPassport(ctx context.Context, passport models.Passport) (*models.User, error)
+	UpdateUser(ctx context.Context, user *models.User) error
+	DeleteUser(ctx context.Context, user *models.User) error
+	ListUsers(ctx context.Context, opts models.UserListOpts) (*models.UserList, error)
+	ListPendingUsers(ctx context.Context, limit int) ([]models.User, error)
+	FailEnrichment(ctx context.Context, id int, status models.EnrichStatus) error
 
 	CreateTask(ctx context.Context, task *models.Task) error
-	UpdateTask(ctx context.Context, task *models.Task) error
+	SwitchTask(ctx context.Context, task *models.Task) (*models.Task, error)
+	UpdateTask(ctx context.Context, task *models.Task, read models.TaskRevision) error
 	GetTask(ctx context.Context, userID, id int) (*models.Task, error)
-	ListTasks(ctx context.Context, userID int) ([]models.Task, error)
+	ListTasks(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error)
+	OverlappingTask(ctx context.Context, userID, excludeID int, since, until, now time.Time) (int, error)
+	TaskEfforts(ctx context.Context, userID int, from, to, now time.Time) ([]models.TaskEffort, error)
+	Timesheet(ctx context.Context, userID int, from, to, now time.Time, fn func(*models.TimesheetRow) error) error
+	CountRunningTasks(ctx context.Context) (int, error)
+	ListRunningTasks(ctx context.Context, startedBefore time.Time) ([]models.Task, error)
+	AutoStopTask(ctx context.Context, task *models.Task) error
+
+	CreateProject(ctx context.Context, project *models.Project) error
+	GetProject(ctx context.Context, id int) (*models.Project, error)
+	ListProjects(ctx context.Context) ([]models.Project, error)
+	UpdateProject(ctx context.Context, project *models.Project) error
+	DeleteProject(ctx context.Context, id int) error
+
+	CreateTag(ctx context.Context, tag *models.Tag) error
+	GetTag(ctx context.Context, id int) (*models.Tag, error)
+	ListTags(ctx context.Context) ([]models.Tag, error)
+	UpdateTag(ctx context.Context, tag *models.Tag) error
+	DeleteTag(ctx context.Context, id int) error
+
+	CreateTeam(ctx context.Context, team *models.Team) error
+	GetTeam(ctx context.Context, id int) (*models.Team, error)
+	ListTeams(ctx context.Context) ([]models.Team, error)
+	UpdateTeam(ctx context.Context, team *models.Team) error
+	DeleteTeam(ctx context.Context, id int) error
+	TeamEfforts(ctx context.Context, teamID int, from, to, now time.Time) ([]models.MemberEffort, error)
+
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, hash []byte) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, id int) error
+	SetFeedToken(ctx context.Context, userID int, hash []byte) error
+	GetFeedToken(ctx context.Context, userID int) ([]byte, error)
+
+	ListAuditEntries(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error)
+	ListAuditEntriesAfter(ctx context.Context, afterID, limit int) ([]models.AuditEntry, error)
+
+	// WithLock runs fn if no other instance of the service holds the named lock, it returns false otherwise.
+	WithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
 }
@@ -2,17 +2,60 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/Nicholas2012/time-tracker/internal/models"
 )
 
 type repositoryMock struct {
-	CreateUserFn func(ctx context.Context, user *models.User) error
-	GetUserFn    func(ctx context.Context, id int) (*models.User, error)
-	CreateTaskFn func(ctx context.Context, task *models.Task) error
-	UpdateTaskFn func(ctx context.Context, task *models.Task) error
-	GetTaskFn    func(ctx context.Context, userID, id int) (*models.Task, error)
-	ListTasksFn  func(ctx context.Context, userID int) ([]models.Task, error)
+	CreateUserFn            func(ctx context.Context, user *models.User) error
+	GetUserFn               func(ctx context.Context, id int) (*models.User, error)
+	UpdateUserFn            func(ctx context.Context, user *models.User) error
+	DeleteUserFn            func(ctx context.Context, user *models.User) error
+	ListUsersFn             func(ctx context.Context, opts models.UserListOpts) (*models.UserList, error)
+	ListPendingUsersFn      func(ctx context.Context, limit int) ([]models.User, error)
+	FailEnrichmentFn        func(ctx context.Context, id int, status models.EnrichStatus) error
+	CreateTaskFn            func(ctx context.Context, task *models.Task) error
+	UpdateTaskFn            func(ctx context.Context, task *models.Task, read models.TaskRevision) error
+	GetTaskFn               func(ctx context.Context, userID, id int) (*models.Task, error)
+	ListTasksFn             func(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error)
+	TaskEffortsFn           func(ctx context.Context, userID int, from, to, now time.Time) ([]models.TaskEffort, error)
+	CreateProjectFn         func(ctx context.Context, project *models.Project) error
+	GetProjectFn            func(ctx context.Context, id int) (*models.Project, error)
+	ListProjectsFn          func(ctx context.Context) ([]models.Project, error)
+	UpdateProjectFn         func(ctx context.Context, project *models.Project) error
+	DeleteProjectFn         func(ctx context.Context, id int) error
+	CreateTagFn             func(ctx context.Context, tag *models.Tag) error
+	GetTagFn                func(ctx context.Context, id int) (*models.Tag, error)
+	ListTagsFn              func(ctx context.Context) ([]models.Tag, error)
+	UpdateTagFn             func(ctx context.Context, tag *models.Tag) error
+	DeleteTagFn             func(ctx context.Context, id int) error
+	SwitchTaskFn            func(ctx context.Context, task *models.Task) (*models.Task, error)
+	OverlappingTaskFn       func(ctx context.Context, userID, excludeID int, since, until, now time.Time) (int, error)
+	CreateAPIKeyFn          func(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByHashFn       func(ctx context.Context, hash []byte) (*models.APIKey, error)
+	ListAPIKeysFn           func(ctx context.Context, userID int) ([]models.APIKey, error)
+	RevokeAPIKeyFn          func(ctx context.Context, userID, id int) error
+	TimesheetFn             func(ctx context.Context, userID int, from, to, now time.Time, fn func(*models.TimesheetRow) error) error
+	SetFeedTokenFn          func(ctx context.Context, userID int, hash []byte) error
+	GetFeedTokenFn          func(ctx context.Context, userID int) ([]byte, error)
+	CountRunningTasksFn     func(ctx context.Context) (int, error)
+	ListRunningTasksFn      func(ctx context.Context, startedBefore time.Time) ([]models.Task, error)
+	AutoStopTaskFn          func(ctx context.Context, task *models.Task) error
+	WithLockFn              func(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
+	ListAuditEntriesFn      func(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error)
+	GetUserByPassportFn     func(ctx context.Context, passport models.Passport) (*models.User, error)
+	CreateTeamFn            func(ctx context.Context, team *models.Team) error
+	GetTeamFn               func(ctx context.Context, id int) (*models.Team, error)
+	ListTeamsFn             func(ctx context.Context) ([]models.Team, error)
+	UpdateTeamFn            func(ctx context.Context, team *models.Team) error
+	DeleteTeamFn            func(ctx context.Context, id int) error
+	TeamEffortsFn           func(ctx context.Context, teamID int, from, to, now time.Time) ([]models.MemberEffort, error)
+	CreateOrgFn             func(ctx context.Context, org *models.Org) error
+	GetOrgFn                func(ctx context.Context, id int) (*models.Org, error)
+	ListOrgsFn              func(ctx context.Context) ([]models.Org, error)
+	ListAuditEntriesAfterFn func(ctx context.Context, afterID, limit int) ([]models.AuditEntry, error)
 }
 
 func (r *repositoryMock) CreateUser(ctx context.Context, user *models.User) error {
@@ -29,6 +72,41 @@ func (r *repositoryMock) GetUser(ctx context.Context, id int) (*models.User, err
 	return r.GetUserFn(ctx, id)
 }
 
+func (r *repositoryMock) UpdateUser(ctx context.Context, user *models.User) error {
+	if r.UpdateUserFn == nil {
+		return nil
+	}
+	return r.UpdateUserFn(ctx, user)
+}
+
+func (r *repositoryMock) DeleteUser(ctx context.Context, user *models.User) error {
+	if r.DeleteUserFn == nil {
+		return nil
+	}
+	return r.DeleteUserFn(ctx, user)
+}
+
+func (r *repositoryMock) ListUsers(ctx context.Context, opts models.UserListOpts) (*models.UserList, error) {
+	if r.ListUsersFn == nil {
+		return nil, nil
+	}
+	return r.ListUsersFn(ctx, opts)
+}
+
+func (r *repositoryMock) ListPendingUsers(ctx context.Context, limit int) ([]models.User, error) {
+	if r.ListPendingUsersFn == nil {
+		return nil, nil
+	}
+	return r.ListPendingUsersFn(ctx, limit)
+}
+
+func (r *repositoryMock) FailEnrichment(ctx context.Context, id int, status models.EnrichStatus) error {
+	if r.FailEnrichmentFn == nil {
+		return nil
+	}
+	return r.FailEnrichmentFn(ctx, id, status)
+}
+
 func (r *repositoryMock) CreateTask(ctx context.Context, task *models.Task) error {
 	if r.CreateTaskFn == nil {
 		return nil
@@ -36,11 +114,11 @@ func (r *repositoryMock) CreateTask(ctx context.Context, task *models.Task) erro
 	return r.CreateTaskFn(ctx, task)
 }
 
-func (r *repositoryMock) UpdateTask(ctx context.Context, task *models.Task) error {
+func (r *repositoryMock) UpdateTask(ctx context.Context, task *models.Task, read models.TaskRevision) error {
 	if r.UpdateTaskFn == nil {
 		return nil
 	}
-	return r.UpdateTaskFn(ctx, task)
+	return r.UpdateTaskFn(ctx, task, read)
 }
 
 func (r *repositoryMock) GetTask(ctx context.Context, userID, id int) (*models.Task, error) {
@@ -50,9 +128,261 @@ func (r *repositoryMock) GetTask(ctx context.Context, userID, id int) (*models.T
 	return r.GetTaskFn(ctx, userID, id)
 }
 
-func (r *repositoryMock) ListTasks(ctx context.Context, userID int) ([]models.Task, error) {
+func (r *repositoryMock) ListTasks(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
 	if r.ListTasksFn == nil {
 		return nil, nil
 	}
-	return r.ListTasksFn(ctx, userID)
+	return r.ListTasksFn(ctx, userID, opts)
+}
+
+func (r *repositoryMock) TaskEfforts(ctx context.Context, userID int, from, to, now time.Time) ([]models.TaskEffort, error) {
+	if r.TaskEffortsFn == nil {
+		return nil, nil
+	}
+	return r.TaskEffortsFn(ctx, userID, from, to, now)
+}
+
+func (r *repositoryMock) CreateProject(ctx context.Context, project *models.Project) error {
+	if r.CreateProjectFn == nil {
+		return nil
+	}
+	return r.CreateProjectFn(ctx, project)
+}
+
+func (r *repositoryMock) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	if r.GetProjectFn == nil {
+		return nil, nil
+	}
+	return r.GetProjectFn(ctx, id)
+}
+
+func (r *repositoryMock) ListProjects(ctx context.Context) ([]models.Project, error) {
+	if r.ListProjectsFn == nil {
+		return nil, nil
+	}
+	return r.ListProjectsFn(ctx)
+}
+
+func (r *repositoryMock) UpdateProject(ctx context.Context, project *models.Project) error {
+	if r.UpdateProjectFn == nil {
+		return nil
+	}
+	return r.UpdateProjectFn(ctx, project)
+}
+
+func (r *repositoryMock) DeleteProject(ctx context.Context, id int) error {
+	if r.DeleteProjectFn == nil {
+		return nil
+	}
+	return r.DeleteProjectFn(ctx, id)
+}
+
+func (r *repositoryMock) CreateTag(ctx context.Context, tag *models.Tag) error {
+	if r.CreateTagFn == nil {
+		return nil
+	}
+	return r.CreateTagFn(ctx, tag)
+}
+
+func (r *repositoryMock) GetTag(ctx context.Context, id int) (*models.Tag, error) {
+	if r.GetTagFn == nil {
+		return nil, nil
+	}
+	return r.GetTagFn(ctx, id)
+}
+
+func (r *repositoryMock) ListTags(ctx context.Context) ([]models.Tag, error) {
+	if r.ListTagsFn == nil {
+		return nil, nil
+	}
+	return r.ListTagsFn(ctx)
+}
+
+func (r *repositoryMock) UpdateTag(ctx context.Context, tag *models.Tag) error {
+	if r.UpdateTagFn == nil {
+		return nil
+	}
+	return r.UpdateTagFn(ctx, tag)
+}
+
+func (r *repositoryMock) DeleteTag(ctx context.Context, id int) error {
+	if r.DeleteTagFn == nil {
+		return nil
+	}
+	return r.DeleteTagFn(ctx, id)
+}
+
+func (r *repositoryMock) SwitchTask(ctx context.Context, task *models.Task) (*models.Task, error) {
+	if r.SwitchTaskFn == nil {
+		return nil, nil
+	}
+	return r.SwitchTaskFn(ctx, task)
+}
+
+func (r *repositoryMock) OverlappingTask(ctx context.Context, userID, excludeID int, since, until, now time.Time) (int, error) {
+	if r.OverlappingTaskFn == nil {
+		return 0, sql.ErrNoRows
+	}
+	return r.OverlappingTaskFn(ctx, userID, excludeID, since, until, now)
+}
+
+func (r *repositoryMock) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	if r.CreateAPIKeyFn == nil {
+		return nil
+	}
+	return r.CreateAPIKeyFn(ctx, key)
+}
+
+func (r *repositoryMock) GetAPIKeyByHash(ctx context.Context, hash []byte) (*models.APIKey, error) {
+	if r.GetAPIKeyByHashFn == nil {
+		return nil, nil
+	}
+	return r.GetAPIKeyByHashFn(ctx, hash)
+}
+
+func (r *repositoryMock) ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+	if r.ListAPIKeysFn == nil {
+		return nil, nil
+	}
+	return r.ListAPIKeysFn(ctx, userID)
+}
+
+func (r *repositoryMock) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	if r.RevokeAPIKeyFn == nil {
+		return nil
+	}
+	return r.RevokeAPIKeyFn(ctx, userID, id)
+}
+
+func (r *repositoryMock) Timesheet(ctx context.Context, userID int, from, to, now time.Time, fn func(*models.TimesheetRow) error) error {
+	if r.TimesheetFn == nil {
+		return nil
+	}
+	return r.TimesheetFn(ctx, userID, from, to, now, fn)
+}
+
+func (r *repositoryMock) SetFeedToken(ctx context.Context, userID int, hash []byte) error {
+	if r.SetFeedTokenFn == nil {
+		return nil
+	}
+	return r.SetFeedTokenFn(ctx, userID, hash)
+}
+
+func (r *repositoryMock) GetFeedToken(ctx context.Context, userID int) ([]byte, error) {
+	if r.GetFeedTokenFn == nil {
+		return nil, nil
+	}
+	return r.GetFeedTokenFn(ctx, userID)
+}
+
+func (r *repositoryMock) CountRunningTasks(ctx context.Context) (int, error) {
+	if r.CountRunningTasksFn == nil {
+		return 0, nil
+	}
+	return r.CountRunningTasksFn(ctx)
+}
+
+func (r *repositoryMock) ListRunningTasks(ctx context.Context, startedBefore time.Time) ([]models.Task, error) {
+	if r.ListRunningTasksFn == nil {
+		return nil, nil
+	}
+	return r.ListRunningTasksFn(ctx, startedBefore)
+}
+
+func (r *repositoryMock) AutoStopTask(ctx context.Context, task *models.Task) error {
+	if r.AutoStopTaskFn == nil {
+		return nil
+	}
+	return r.AutoStopTaskFn(ctx, task)
+}
+
+func (r *repositoryMock) WithLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	if r.WithLockFn == nil {
+		return true, fn(ctx)
+	}
+	return r.WithLockFn(ctx, name, fn)
+}
+
+func (r *repositoryMock) ListAuditEntries(ctx context.Context, filter models.AuditFilter) (*models.AuditLog, error) {
+	if r.ListAuditEntriesFn == nil {
+		return nil, nil
+	}
+	return r.ListAuditEntriesFn(ctx, filter)
+}
+
+func (r *repositoryMock) GetUserByPassport(ctx context.Context, passport models.Passport) (*models.User, error) {
+	if r.GetUserByPassportFn == nil {
+		return nil, nil
+	}
+	return r.GetUserByPassportFn(ctx, passport)
+}
+
+func (r *repositoryMock) CreateTeam(ctx context.Context, team *models.Team) error {
+	if r.CreateTeamFn == nil {
+		return nil
+	}
+	return r.CreateTeamFn(ctx, team)
+}
+
+func (r *repositoryMock) GetTeam(ctx context.Context, id int) (*models.Team, error) {
+	if r.GetTeamFn == nil {
+		return nil, nil
+	}
+	return r.GetTeamFn(ctx, id)
+}
+
+func (r *repositoryMock) ListTeams(ctx context.Context) ([]models.Team, error) {
+	if r.ListTeamsFn == nil {
+		return nil, nil
+	}
+	return r.ListTeamsFn(ctx)
+}
+
+func (r *repositoryMock) UpdateTeam(ctx context.Context, team *models.Team) error {
+	if r.UpdateTeamFn == nil {
+		return nil
+	}
+	return r.UpdateTeamFn(ctx, team)
+}
+
+func (r *repositoryMock) DeleteTeam(ctx context.Context, id int) error {
+	if r.DeleteTeamFn == nil {
+		return nil
+	}
+	return r.DeleteTeamFn(ctx, id)
+}
+
+func (r *repositoryMock) TeamEfforts(ctx context.Context, teamID int, from, to, now time.Time) ([]models.MemberEffort, error) {
+	if r.TeamEffortsFn == nil {
+		return nil, nil
+	}
+	return r.TeamEffortsFn(ctx, teamID, from, to, now)
+}
+
+func (r *repositoryMock) CreateOrg(ctx context.Context, org *models.Org) error {
+	if r.CreateOrgFn == nil {
+		return nil
+	}
+	return r.CreateOrgFn(ctx, org)
+}
+
+func (r *repositoryMock) GetOrg(ctx context.Context, id int) (*models.Org, error) {
+	if r.GetOrgFn == nil {
+		return nil, nil
+	}
+	return r.GetOrgFn(ctx, id)
+}
+
+func (r *repositoryMock) ListOrgs(ctx context.Context) ([]models.Org, error) {
+	if r.ListOrgsFn == nil {
+		return nil, nil
+	}
+	return r.ListOrgsFn(ctx)
+}
+
+func (r *repositoryMock) ListAuditEntriesAfter(ctx context.Context, afterID, limit int) ([]models.AuditEntry, error) {
+	if r.ListAuditEntriesAfterFn == nil {
+		return nil, nil
+	}
+	return r.ListAuditEntriesAfterFn(ctx, afterID, limit)
 }
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+var errTagNotFound = notFound("tag_not_found", "tag not found")
+
+func (s *Service) CreateTag(ctx context.Context, name string) (*models.Tag, error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateTag")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleManager, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	tag := &models.Tag{Name: strings.TrimSpace(name)}
+	if tag.Name == "" {
+		return nil, invalid("name_required", "name", "tag name is required")
+	}
+
+	if err := s.repo.CreateTag(ctx, tag); err != nil {
+		if errors.Is(err, models.ErrDuplicate) {
+			return nil, conflict("tag_exists", fmt.Sprintf("tag %q already exists", tag.Name))
+		}
+		return nil, fmt.Errorf("create tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+func (s *Service) GetTag(ctx context.Context, id int) (*models.Tag, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetTag")
+	defer span.End()
+
+	tag, err := s.repo.GetTag(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errTagNotFound
+		}
+		return nil, fmt.Errorf("get tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+func (s *Service) ListTags(ctx context.Context) ([]models.Tag, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListTags")
+	defer span.End()
+
+	tags, err := s.repo.ListTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (s *Service) UpdateTag(ctx context.Context, id int, name string) (*models.Tag, error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdateTag")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleManager, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	tag := &models.Tag{ID: id, Name: strings.TrimSpace(name)}
+	if tag.Name == "" {
+		return nil, invalid("name_required", "name", "tag name is required")
+	}
+
+	if err := s.repo.UpdateTag(ctx, tag); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, errTagNotFound
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, conflict("tag_exists", fmt.Sprintf("tag %q already exists", tag.Name))
+		}
+		return nil, fmt.Errorf("update tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// DeleteTag deletes a tag and removes it from all tasks.
+func (s *Service) DeleteTag(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteTag")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleManager, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTag(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errTagNotFound
+		}
+		return fmt.Errorf("delete tag: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+var errTeamNotFound = notFound("team_not_found", "team not found")
+
+// TeamInfo describes a new team.
+type TeamInfo struct {
+	Name      string
+	ManagerID int // optional
+	MemberIDs []int
+}
+
+// TeamUpdate holds fields to change in a team, nil fields are left as is.
+type TeamUpdate struct {
+	Name      *string
+	ManagerID *int   // zero removes the manager
+	MemberIDs *[]int // replaces all members
+}
+
+func (s *Service) CreateTeam(ctx context.Context, info TeamInfo) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateTeam")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	team := &models.Team{
+		Name:      strings.TrimSpace(info.Name),
+		ManagerID: info.ManagerID,
+		MemberIDs: teamMembers(info.MemberIDs),
+	}
+	if team.Name == "" {
+		return nil, invalid("name_required", "name", "team name is required")
+	}
+	if err := s.checkTeam(ctx, team); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateTeam(ctx, team); err != nil {
+		if errors.Is(err, models.ErrDuplicate) {
+			return nil, conflict("team_exists", fmt.Sprintf("team %q already exists", team.Name))
+		}
+		return nil, fmt.Errorf("create team: %w", err)
+	}
+
+	return team, nil
+}
+
+// GetTeam returns the team, only admins, the manager and members of the team may see it.
+func (s *Service) GetTeam(ctx context.Context, id int) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetTeam")
+	defer span.End()
+
+	team, err := s.getTeam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeTeamMember(ctx, team); err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (s *Service) getTeam(ctx context.Context, id int) (*models.Team, error) {
+	team, err := s.repo.GetTeam(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errTeamNotFound
+		}
+		return nil, fmt.Errorf("get team: %w", err)
+	}
+
+	return team, nil
+}
+
+// ListTeams returns all teams to admins, other users get the teams they manage or are members of.
+func (s *Service) ListTeams(ctx context.Context) ([]models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListTeams")
+	defer span.End()
+
+	if _, ok := ActorFrom(ctx); !ok {
+		return nil, errAccessDenied
+	}
+
+	teams, err := s.repo.ListTeams(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list teams: %w", err)
+	}
+
+	return slices.DeleteFunc(teams, func(team models.Team) bool {
+		return authorizeTeamMember(ctx, &team) != nil
+	}), nil
+}
+
+func (s *Service) UpdateTeam(ctx context.Context, id int, upd TeamUpdate) (*models.Team, error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdateTeam")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	team, err := s.getTeam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if upd.Name != nil {
+		team.Name = strings.TrimSpace(*upd.Name)
+		if team.Name == "" {
+			return nil, invalid("name_required", "name", "team name is required")
+		}
+	}
+	if upd.ManagerID != nil {
+		team.ManagerID = *upd.ManagerID
+	}
+	if upd.MemberIDs != nil {
+		team.MemberIDs = teamMembers(*upd.MemberIDs)
+	}
+	if err := s.checkTeam(ctx, team); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTeam(ctx, team); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, errTeamNotFound
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, conflict("team_exists", fmt.Sprintf("team %q already exists", team.Name))
+		}
+		return nil, fmt.Errorf("update team: %w", err)
+	}
+
+	return team, nil
+}
+
+// DeleteTeam deletes a team, its members are kept.
+func (s *Service) DeleteTeam(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteTeam")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTeam(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errTeamNotFound
+		}
+		return fmt.Errorf("delete team: %w", err)
+	}
+
+	return nil
+}
+
+// TeamReport returns time spent by each member of the team on each task within [from, to).
+// Only admins and the manager of the team may read it.
+func (s *Service) TeamReport(ctx context.Context, teamID int, from, to time.Time) (*models.TeamReport, error) {
+	ctx, span := tracer.Start(ctx, "Service.TeamReport")
+	defer span.End()
+
+	team, err := s.getTeam(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeTeamReader(ctx, team); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if to.IsZero() {
+		to = now
+	}
+	if !from.Before(to) {
+		return nil, invalid("invalid_period", "from", "invalid period, from must be before to")
+	}
+
+	members, err := s.repo.TeamEfforts(ctx, teamID, from, to, now)
+	if err != nil {
+		return nil, fmt.Errorf("team efforts: %w", err)
+	}
+
+	report := &models.TeamReport{
+		TeamID:  teamID,
+		From:    from,
+		To:      to,
+		Members: members,
+	}
+	for _, m := range members {
+		report.Total += m.Total
+	}
+
+	return report, nil
+}
+
+// checkTeam validates that the manager and members of the team exist, the manager must be a manager or an admin.
+func (s *Service) checkTeam(ctx context.Context, team *models.Team) error {
+	if team.ManagerID != 0 {
+		manager, err := s.getUser(ctx, team.ManagerID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return invalid("invalid_manager", "managerId", fmt.Sprintf("user %d not found", team.ManagerID))
+			}
+			return err
+		}
+		if manager.Role != models.RoleManager && manager.Role != models.RoleAdmin {
+			return invalid("invalid_manager", "managerId", fmt.Sprintf("user %d is not a manager", team.ManagerID))
+		}
+	}
+
+	for _, id := range team.MemberIDs {
+		if _, err := s.getUser(ctx, id); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return invalid("invalid_member", "memberIds", fmt.Sprintf("user %d not found", id))
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// teamMembers returns sorted member IDs without duplicates, nil if there are none.
+func teamMembers(ids []int) []int {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTeam_OK(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		if id == 1 {
+			return &models.User{ID: id, Role: models.RoleManager}, nil
+		}
+		return &models.User{ID: id}, nil
+	}
+	repo.CreateTeamFn = func(ctx context.Context, team *models.Team) error {
+		team.ID = 5
+		return nil
+	}
+
+	team, err := s.CreateTeam(system(), TeamInfo{Name: " Backend ", ManagerID: 1, MemberIDs: []int{3, 2, 3}})
+	require.NoError(t, err)
+	require.Equal(t, &models.Team{ID: 5, Name: "Backend", ManagerID: 1, MemberIDs: []int{2, 3}}, team)
+}
+
+func TestCreateTeam_Invalid(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		if id == 404 {
+			return nil, sql.ErrNoRows
+		}
+		return &models.User{ID: id, Role: models.RoleEmployee}, nil
+	}
+
+	_, err := s.CreateTeam(system(), TeamInfo{Name: " "})
+	require.EqualError(t, err, "team name is required")
+
+	_, err = s.CreateTeam(system(), TeamInfo{Name: "Backend", ManagerID: 2})
+	require.EqualError(t, err, "user 2 is not a manager")
+
+	_, err = s.CreateTeam(system(), TeamInfo{Name: "Backend", MemberIDs: []int{2, 404}})
+	require.EqualError(t, err, "user 404 not found")
+	require.ErrorIs(t, err, ErrValidation)
+
+	manager := WithActor(context.TODO(), Actor{UserID: 3, Role: models.RoleManager})
+	_, err = s.CreateTeam(manager, TeamInfo{Name: "Backend"})
+	require.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestUpdateTeam_Members(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetTeamFn = func(ctx context.Context, id int) (*models.Team, error) {
+		return &models.Team{ID: id, Name: "Backend", ManagerID: 1, MemberIDs: []int{2}}, nil
+	}
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id, Role: models.RoleAdmin}, nil
+	}
+	repo.UpdateTeamFn = func(ctx context.Context, team *models.Team) error {
+		return nil
+	}
+
+	members := []int{4}
+	noManager := 0
+	team, err := s.UpdateTeam(system(), 5, TeamUpdate{ManagerID: &noManager, MemberIDs: &members})
+	require.NoError(t, err)
+	require.Equal(t, &models.Team{ID: 5, Name: "Backend", MemberIDs: []int{4}}, team)
+}
+
+func TestDeleteTeam_NotFound(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.DeleteTeamFn = func(ctx context.Context, id int) error {
+		return sql.ErrNoRows
+	}
+
+	require.ErrorIs(t, s.DeleteTeam(system(), 5), ErrNotFound)
+}
+
+func TestGetTeam_Access(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetTeamFn = func(ctx context.Context, id int) (*models.Team, error) {
+		return &models.Team{ID: id, Name: "Backend", ManagerID: 7, MemberIDs: []int{1, 2}}, nil
+	}
+
+	for _, actor := range []Actor{
+		{Role: models.RoleAdmin},
+		{UserID: 7, Role: models.RoleManager},
+		{UserID: 2, Role: models.RoleEmployee},
+	} {
+		_, err := s.GetTeam(WithActor(context.TODO(), actor), 5)
+		require.NoError(t, err, actor)
+	}
+
+	for _, ctx := range []context.Context{
+		context.TODO(),
+		WithActor(context.TODO(), Actor{UserID: 8, Role: models.RoleManager}),
+		WithActor(context.TODO(), Actor{UserID: 3, Role: models.RoleEmployee}),
+	} {
+		_, err := s.GetTeam(ctx, 5)
+		require.ErrorIs(t, err, ErrForbidden)
+	}
+}
+
+func TestListTeams_Access(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.ListTeamsFn = func(ctx context.Context) ([]models.Team, error) {
+		return []models.Team{
+			{ID: 1, Name: "Backend", ManagerID: 7, MemberIDs: []int{1, 2}},
+			{ID: 2, Name: "Frontend", ManagerID: 8, MemberIDs: []int{3}},
+		}, nil
+	}
+
+	ids := func(ctx context.Context) []int {
+		teams, err := s.ListTeams(ctx)
+		require.NoError(t, err)
+
+		var ids []int
+		for _, team := range teams {
+			ids = append(ids, team.ID)
+		}
+		return ids
+	}
+
+	require.Equal(t, []int{1, 2}, ids(WithActor(context.TODO(), Actor{Role: models.RoleAdmin})))
+	require.Equal(t, []int{2}, ids(WithActor(context.TODO(), Actor{UserID: 8, Role: models.RoleManager})))
+	require.Equal(t, []int{1}, ids(WithActor(context.TODO(), Actor{UserID: 2, Role: models.RoleEmployee})))
+	require.Empty(t, ids(WithActor(context.TODO(), Actor{UserID: 4, Role: models.RoleEmployee})))
+
+	_, err := s.ListTeams(context.TODO())
+	require.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestTeamReport(t *testing.T) {
+	s, repo := setup(t)
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.GetTeamFn = func(ctx context.Context, id int) (*models.Team, error) {
+		return &models.Team{ID: id, Name: "Backend", ManagerID: 7}, nil
+	}
+	repo.TeamEffortsFn = func(ctx context.Context, teamID int, f, tt, now time.Time) ([]models.MemberEffort, error) {
+		require.Equal(t, 5, teamID)
+		require.Equal(t, from, f)
+		require.Equal(t, to, tt)
+
+		return []models.MemberEffort{
+			{UserID: 1, Total: 3 * time.Hour},
+			{UserID: 2, Total: 30 * time.Minute},
+		}, nil
+	}
+
+	for _, actor := range []Actor{
+		{Role: models.RoleAdmin},
+		{UserID: 7, Role: models.RoleManager},
+	} {
+		report, err := s.TeamReport(WithActor(context.TODO(), actor), 5, from, to)
+		require.NoError(t, err)
+		require.Len(t, report.Members, 2)
+		require.Equal(t, 3*time.Hour+30*time.Minute, report.Total)
+	}
+
+	for _, actor := range []Actor{
+		{UserID: 8, Role: models.RoleManager},
+		{UserID: 1, Role: models.RoleEmployee},
+	} {
+		_, err := s.TeamReport(WithActor(context.TODO(), actor), 5, from, to)
+		require.ErrorIs(t, err, ErrForbidden)
+	}
+
+	_, err := s.TeamReport(system(), 5, to, from)
+	require.EqualError(t, err, "invalid period, from must be before to")
+}
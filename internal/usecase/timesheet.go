@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+)
+
+// Timesheet passes time spent by the user on each task within [from, to) to fn row by row,
+// zero userID exports tasks of all users. Running tasks are counted up to now or to, whichever comes first.
+// Errors returned before the first call of fn leave nothing exported.
+func (s *Service) Timesheet(ctx context.Context, userID int, from, to time.Time, fn func(*models.TimesheetRow) error) error {
+	ctx, span := tracer.Start(ctx, "Service.Timesheet")
+	defer span.End()
+
+	if userID == 0 {
+		if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+			return err
+		}
+	} else if err := s.authorizeReader(ctx, userID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if to.IsZero() {
+		to = now
+	}
+	if !from.Before(to) {
+		return invalid("invalid_period", "from", "invalid period, from must be before to")
+	}
+
+	if userID != 0 {
+		if _, err := s.getUser(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.Timesheet(ctx, userID, from, to, now, fn); err != nil {
+		return fmt.Errorf("timesheet: %w", err)
+	}
+
+	return nil
+}
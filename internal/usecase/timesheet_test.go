@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimesheet(t *testing.T) {
+	s, repo := setup(t)
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		if id == 3 {
+			return &models.User{ID: 3}, nil
+		}
+		return nil, sql.ErrNoRows
+	}
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	repo.TimesheetFn = func(ctx context.Context, userID int, gotFrom, gotTo, now time.Time, fn func(*models.TimesheetRow) error) error {
+		require.Equal(t, from, gotFrom)
+		require.Equal(t, to, gotTo)
+		return fn(&models.TimesheetRow{User: models.User{ID: 3}, TaskID: 1, Duration: time.Hour})
+	}
+
+	var rows []models.TimesheetRow
+	collect := func(row *models.TimesheetRow) error {
+		rows = append(rows, *row)
+		return nil
+	}
+
+	employee := WithActor(context.TODO(), Actor{UserID: 3, Role: models.RoleEmployee})
+	require.NoError(t, s.Timesheet(employee, 3, from, to, collect))
+	require.Len(t, rows, 1)
+
+	require.ErrorIs(t, s.Timesheet(employee, 0, from, to, collect), ErrForbidden)
+
+	admin := WithActor(context.TODO(), Actor{Role: models.RoleAdmin})
+	require.NoError(t, s.Timesheet(admin, 0, from, to, collect))
+	require.Len(t, rows, 2)
+
+	require.ErrorIs(t, s.Timesheet(admin, 4, from, to, collect), ErrNotFound)
+	require.ErrorIs(t, s.Timesheet(admin, 3, to, from, collect), ErrValidation)
+}
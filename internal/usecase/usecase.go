@@ -5,103 +5,746 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Nicholas2012/time-tracker/internal/auth"
+	"github.com/Nicholas2012/time-tracker/internal/events"
+	"github.com/Nicholas2012/time-tracker/internal/logging"
 	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/nameservice"
+	"go.opentelemetry.io/otel"
 )
 
+// tracer starts a child span of the request for each Service method.
+var tracer = otel.Tracer("github.com/Nicholas2012/time-tracker/internal/usecase")
+
+// NamePolicy defines what happens to a new user when the name service is unavailable.
+type NamePolicy string
+
+const (
+	NamePolicyFail  NamePolicy = "fail"  // user is not created
+	NamePolicyDefer NamePolicy = "defer" // user is created without name and enriched later
+)
+
+const (
+	defaultUsersLimit = 20
+	maxUsersLimit     = 100
+
+	defaultTasksLimit = 50
+	maxTasksLimit     = 500
+)
+
+// StartPolicy defines what happens when a user starts a task while another one is running.
+type StartPolicy string
+
+const (
+	StartPolicyReject StartPolicy = "reject" // new task is rejected with ErrConflict
+	StartPolicySwitch StartPolicy = "switch" // running task is ended and the new one is started
+)
+
+// pendingUsersBatch is the number of users enriched in one EnrichPendingUsers call.
+const pendingUsersBatch = 100
+
+// maxEnrichAttempts is the number of failed attempts after which a pending user is no longer enriched.
+const maxEnrichAttempts = 5
+
+type NameService interface {
+	Enrich(ctx context.Context, user *models.User) error
+}
+
+// Name service call outcomes reported to Metrics.
+const (
+	NameServiceOK          = "ok"
+	NameServiceNotFound    = "not_found"
+	NameServiceUnavailable = "unavailable"
+	NameServiceError       = "error"
+)
+
+// Metrics counts business events.
+type Metrics interface {
+	TaskStarted()
+	TaskEnded()
+	UserCreated()
+	NameServiceCalled(outcome string)
+}
+
+// nopMetrics discards events when metrics are not enabled.
+type nopMetrics struct{}
+
+func (nopMetrics) TaskStarted()             {}
+func (nopMetrics) TaskEnded()               {}
+func (nopMetrics) UserCreated()             {}
+func (nopMetrics) NameServiceCalled(string) {}
+
 type Service struct {
-	repo Repository
+	repo        Repository
+	metrics     Metrics
+	names       NameService
+	namePolicy  NamePolicy
+	startPolicy StartPolicy
+	autoStop    AutoStopPolicy
+	tokens      *auth.Tokens
+	adminKey    []byte         // hash of the admin key, nil if it is not set
+	events      *events.Broker // nil if live activity isn't streamed
 }
 
-func New(repo Repository) *Service {
-	return &Service{
-		repo: repo,
+type Option func(*Service)
+
+// WithNameService enables filling user name and address from the name service.
+func WithNameService(names NameService, policy NamePolicy) Option {
+	return func(s *Service) {
+		s.names = names
+		s.namePolicy = policy
 	}
 }
 
-func (s *Service) CreateUser(ctx context.Context, passportNumber string) error {
-	parts := strings.Split(passportNumber, " ")
-	if len(parts) < 2 {
-		return errors.New("invalid passport number, must have at least 2 parts")
+// WithStartPolicy sets what happens when a task is started while another one is running.
+func WithStartPolicy(policy StartPolicy) Option {
+	return func(s *Service) {
+		s.startPolicy = policy
 	}
+}
 
-	series, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return errors.New("invalid passport series, must be a number, got: " + parts[0])
+// WithMetrics enables counting of business events.
+func WithMetrics(m Metrics) Option {
+	return func(s *Service) {
+		s.metrics = m
 	}
+}
 
-	number, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return errors.New("invalid passport number, must be a number, got: " + parts[1])
+func New(repo Repository, opts ...Option) *Service {
+	s := &Service{
+		repo:        repo,
+		metrics:     nopMetrics{},
+		namePolicy:  NamePolicyFail,
+		startPolicy: StartPolicyReject,
 	}
 
-	newUser := &models.User{
-		PassportSerie:  series,
-		PassportNumber: number,
+	for _, opt := range opts {
+		opt(s)
 	}
 
-	// todo make call to name service to get user name
+	return s
+}
+
+func (s *Service) CreateUser(ctx context.Context, passportNumber string) error {
+	ctx, span := tracer.Start(ctx, "Service.CreateUser")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	passport, err := parsePassport(passportNumber)
+	if err != nil {
+		return err
+	}
+
+	newUser := &models.User{Passport: passport}
+
+	if s.names != nil {
+		if err := s.enrich(ctx, newUser); err != nil {
+			if s.namePolicy != NamePolicyDefer || !errors.Is(err, nameservice.ErrUnavailable) {
+				return fmt.Errorf("get user info: %w", err)
+			}
+			logging.FromContext(ctx).Warn("Name service is unavailable, user will be enriched later", "error", err)
+			newUser.EnrichStatus = models.EnrichPending
+		}
+	}
 
 	if err := s.repo.CreateUser(ctx, newUser); err != nil {
+		if errors.Is(err, models.ErrDuplicate) {
+			return errPassportTaken
+		}
 		return fmt.Errorf("create user: %w", err)
 	}
+	s.metrics.UserCreated()
 
 	return nil
 }
 
-func (s *Service) StartTask(ctx context.Context, userID int) (int, error) {
-	user, err := s.repo.GetUser(ctx, userID)
+// enrich fills the user from the name service counting the outcome of the call.
+func (s *Service) enrich(ctx context.Context, user *models.User) error {
+	err := s.names.Enrich(ctx, user)
+
+	switch {
+	case err == nil:
+		s.metrics.NameServiceCalled(NameServiceOK)
+	case errors.Is(err, nameservice.ErrNotFound):
+		s.metrics.NameServiceCalled(NameServiceNotFound)
+	case errors.Is(err, nameservice.ErrUnavailable):
+		s.metrics.NameServiceCalled(NameServiceUnavailable)
+	default:
+		s.metrics.NameServiceCalled(NameServiceError)
+	}
+
+	return err
+}
+
+func (s *Service) GetUser(ctx context.Context, id int) (*models.User, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetUser")
+	defer span.End()
+
+	if err := s.authorizeReader(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.getUser(ctx, id)
+}
+
+// GetUserByPassport returns the user with the passport, which may be written with or without separators.
+func (s *Service) GetUserByPassport(ctx context.Context, passport string) (*models.User, error) {
+	ctx, span := tracer.Start(ctx, "Service.GetUserByPassport")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	p, err := parsePassport(passport)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetUserByPassport(ctx, p)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return 0, ErrNotFound
+			return nil, errUserNotFound
+		}
+		return nil, fmt.Errorf("get user by passport: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *Service) getUser(ctx context.Context, id int) (*models.User, error) {
+	user, err := s.repo.GetUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errUserNotFound
+		}
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *Service) ListUsers(ctx context.Context, opts models.UserListOpts) (*models.UserList, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListUsers")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.Limit < 1 {
+		opts.Limit = defaultUsersLimit
+	}
+	if opts.Limit > maxUsersLimit {
+		opts.Limit = maxUsersLimit
+	}
+
+	users, err := s.repo.ListUsers(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+
+	return users, nil
+}
+
+// UserUpdate holds fields to change in a user, nil fields are left as is.
+type UserUpdate struct {
+	Passport   *string
+	Name       *string
+	Surname    *string
+	Patronymic *string
+	Address    *string
+	Role       *models.Role
+	ManagerID  *int // zero removes the manager
+}
+
+// UpdateUser changes fields of the user set in the update and returns the updated user.
+func (s *Service) UpdateUser(ctx context.Context, id int, upd UserUpdate) (*models.User, error) {
+	ctx, span := tracer.Start(ctx, "Service.UpdateUser")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if upd.Passport != nil {
+		user.Passport, err = parsePassport(*upd.Passport)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if upd.Name != nil {
+		user.Name = *upd.Name
+	}
+	if upd.Surname != nil {
+		user.Surname = *upd.Surname
+	}
+	if upd.Patronymic != nil {
+		user.Patronymic = *upd.Patronymic
+	}
+	if upd.Address != nil {
+		user.Address = *upd.Address
+	}
+	if upd.Role != nil {
+		if !upd.Role.IsValid() {
+			return nil, invalid("invalid_role", "role", fmt.Sprintf("unknown role %q", *upd.Role))
+		}
+		user.Role = *upd.Role
+	}
+	if upd.ManagerID != nil {
+		if err := s.checkManager(ctx, user, *upd.ManagerID); err != nil {
+			return nil, err
+		}
+		user.ManagerID = *upd.ManagerID
+	}
+
+	if err := s.repo.UpdateUser(ctx, user); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, errUserNotFound
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, errPassportTaken
+		}
+		return nil, fmt.Errorf("update user: %w", err)
+	}
+
+	return user, nil
+}
+
+// checkManager validates that the manager may be assigned to the user.
+func (s *Service) checkManager(ctx context.Context, user *models.User, managerID int) error {
+	if managerID == 0 {
+		return nil
+	}
+	if managerID == user.ID {
+		return invalid("invalid_manager", "managerId", "user can't be their own manager")
+	}
+
+	if _, err := s.getUser(ctx, managerID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return invalid("invalid_manager", "managerId", fmt.Sprintf("user %d not found", managerID))
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) DeleteUser(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "Service.DeleteUser")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteUser(ctx, &models.User{ID: id}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errUserNotFound
+		}
+		return fmt.Errorf("delete user: %w", err)
+	}
+
+	return nil
+}
+
+// EnrichPendingUsers fills users created while the name service was unavailable, users of every organization
+// unless the context acts within one. It returns the number of enriched users.
+// Users the name service doesn't know and the ones which failed too many times are not retried.
+// The batch stops while the service is unavailable, such attempts are not counted.
+func (s *Service) EnrichPendingUsers(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "Service.EnrichPendingUsers")
+	defer span.End()
+
+	if err := authorizeRole(ctx, models.RoleAdmin); err != nil {
+		return 0, err
+	}
+
+	if s.names == nil {
+		return 0, nil
+	}
+
+	var enriched int
+	err := s.forEachOrg(ctx, func(ctx context.Context) error {
+		users, err := s.repo.ListPendingUsers(ctx, pendingUsersBatch)
+		if err != nil {
+			return fmt.Errorf("list pending users: %w", err)
+		}
+
+		for _, user := range users {
+			if err := s.enrich(ctx, &user); err != nil {
+				if errors.Is(err, nameservice.ErrUnavailable) {
+					return fmt.Errorf("enrich user %d: %w", user.ID, err)
+				}
+				logging.FromContext(ctx).Warn("Failed to enrich user", "user", user.ID, "attempt", user.EnrichAttempts+1, "error", err)
+
+				status := models.EnrichPending
+				switch {
+				case errors.Is(err, nameservice.ErrNotFound):
+					status = models.EnrichNotFound
+				case user.EnrichAttempts+1 >= maxEnrichAttempts:
+					status = models.EnrichFailed
+				}
+				if err := s.repo.FailEnrichment(ctx, user.ID, status); err != nil {
+					return fmt.Errorf("fail enrichment of user %d: %w", user.ID, err)
+				}
+				continue
+			}
+
+			user.EnrichStatus = models.EnrichDone
+			if err := s.repo.UpdateUser(ctx, &user); err != nil {
+				return fmt.Errorf("update user %d: %w", user.ID, err)
+			}
+			enriched++
 		}
-		return 0, fmt.Errorf("get user: %w", err)
+
+		return nil
+	})
+
+	return enriched, err
+}
+
+var (
+	errUserNotFound  = notFound("user_not_found", "user not found")
+	errPassportTaken = conflict("passport_taken", "user with the passport already exists")
+	errTaskNotFound  = notFound("task_not_found", "task not found")
+	errTaskRunning   = conflict("task_running", "user already has a running task")
+	errTaskFinished  = conflict("task_finished", "task is already finished")
+	errTaskPaused    = conflict("task_paused", "task is already paused")
+	errTaskResumed   = conflict("task_not_paused", "task is not paused")
+	errTaskChanged   = conflict("task_changed", "task was changed meanwhile, reload it and retry")
+)
+
+// TaskInfo describes a new task.
+type TaskInfo struct {
+	Title       string
+	Description string
+	ProjectID   int // optional
+	TagIDs      []int
+}
+
+func (s *Service) StartTask(ctx context.Context, userID int, info TaskInfo) (int, error) {
+	ctx, span := tracer.Start(ctx, "Service.StartTask")
+	defer span.End()
+
+	if err := s.authorizeOwner(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if s.startPolicy == StartPolicySwitch {
+		id, _, err := s.switchTask(ctx, user.ID, info)
+		return id, err
 	}
 
 	task := models.NewTask(user.ID)
+	if err := s.describeTask(ctx, task, info); err != nil {
+		return 0, err
+	}
 
 	if err := s.repo.CreateTask(ctx, task); err != nil {
+		if errors.Is(err, models.ErrDuplicate) {
+			return 0, errTaskRunning
+		}
 		return 0, fmt.Errorf("create task: %w", err)
 	}
+	s.metrics.TaskStarted()
 
 	return task.ID, nil
 }
 
+// SwitchTask ends the running task of the user and starts a new one at the same moment.
+// It returns IDs of the new task and the ended one, which is zero if no task was running.
+func (s *Service) SwitchTask(ctx context.Context, userID int, info TaskInfo) (int, int, error) {
+	ctx, span := tracer.Start(ctx, "Service.SwitchTask")
+	defer span.End()
+
+	if err := s.authorizeOwner(ctx, userID); err != nil {
+		return 0, 0, err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return s.switchTask(ctx, user.ID, info)
+}
+
+func (s *Service) switchTask(ctx context.Context, userID int, info TaskInfo) (int, int, error) {
+	task := models.NewTask(userID)
+	if err := s.describeTask(ctx, task, info); err != nil {
+		return 0, 0, err
+	}
+
+	stopped, err := s.repo.SwitchTask(ctx, task)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, 0, errUserNotFound
+		case errors.Is(err, models.ErrDuplicate):
+			return 0, 0, errTaskRunning
+		}
+		return 0, 0, fmt.Errorf("switch task: %w", err)
+	}
+
+	s.metrics.TaskStarted()
+
+	var stoppedID int
+	if stopped != nil {
+		stoppedID = stopped.ID
+		s.metrics.TaskEnded()
+	}
+
+	return task.ID, stoppedID, nil
+}
+
+// describeTask fills task description checking that the project and tags exist.
+func (s *Service) describeTask(ctx context.Context, task *models.Task, info TaskInfo) error {
+	task.Title = strings.TrimSpace(info.Title)
+	task.Description = info.Description
+
+	if info.ProjectID != 0 {
+		project, err := s.GetProject(ctx, info.ProjectID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return invalid("unknown_project", "projectId", fmt.Sprintf("project %d not found", info.ProjectID))
+			}
+			return err
+		}
+		task.ProjectID = project.ID
+	}
+
+	task.Tags = make([]models.Tag, 0, len(info.TagIDs))
+	for _, id := range info.TagIDs {
+		tag, err := s.GetTag(ctx, id)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return invalid("unknown_tag", "tagIds", fmt.Sprintf("tag %d not found", id))
+			}
+			return err
+		}
+		task.Tags = append(task.Tags, *tag)
+	}
+
+	return nil
+}
+
 func (s *Service) EndTask(ctx context.Context, userID, taskID int) error {
-	user, err := s.repo.GetUser(ctx, userID)
+	ctx, span := tracer.Start(ctx, "Service.EndTask")
+	defer span.End()
+
+	task, err := s.getTask(ctx, userID, taskID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrNotFound
+		return err
+	}
+
+	if task.IsFinished() {
+		return errTaskFinished
+	}
+
+	read := task.Revision()
+	task.End(time.Now())
+
+	if err := s.updateTask(ctx, task, read); err != nil {
+		return err
+	}
+	s.metrics.TaskEnded()
+
+	return nil
+}
+
+// PauseTask stops counting time of the running task until it is resumed.
+func (s *Service) PauseTask(ctx context.Context, userID, taskID int) error {
+	ctx, span := tracer.Start(ctx, "Service.PauseTask")
+	defer span.End()
+
+	task, err := s.getTask(ctx, userID, taskID)
+	if err != nil {
+		return err
+	}
+
+	switch task.State() {
+	case models.TaskFinished:
+		return errTaskFinished
+	case models.TaskPaused:
+		return errTaskPaused
+	}
+
+	read := task.Revision()
+	task.Pause(time.Now())
+
+	return s.updateTask(ctx, task, read)
+}
+
+// ResumeTask continues counting time of the paused task in a new segment.
+func (s *Service) ResumeTask(ctx context.Context, userID, taskID int) error {
+	ctx, span := tracer.Start(ctx, "Service.ResumeTask")
+	defer span.End()
+
+	task, err := s.getTask(ctx, userID, taskID)
+	if err != nil {
+		return err
+	}
+
+	switch task.State() {
+	case models.TaskFinished:
+		return errTaskFinished
+	case models.TaskRunning:
+		return errTaskResumed
+	}
+
+	read := task.Revision()
+	task.Resume(time.Now())
+
+	return s.updateTask(ctx, task, read)
+}
+
+// updateTask stores the task changed since it was read at the revision, it's a conflict if
+// the task was changed by someone else meanwhile, e.g. ended by the auto-stop policy.
+func (s *Service) updateTask(ctx context.Context, task *models.Task, read models.TaskRevision) error {
+	if err := s.repo.UpdateTask(ctx, task, read); err != nil {
+		switch {
+		case errors.Is(err, models.ErrStale):
+			return errTaskChanged
+		case errors.Is(err, sql.ErrNoRows):
+			return errTaskNotFound
 		}
-		return fmt.Errorf("get user: %w", err)
+		return fmt.Errorf("update task: %w", err)
+	}
+	return nil
+}
+
+// getTask returns the task of the existing user which the actor may change.
+func (s *Service) getTask(ctx context.Context, userID, taskID int) (*models.Task, error) {
+	if err := s.authorizeOwner(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
 	task, err := s.repo.GetTask(ctx, user.ID, taskID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrNotFound
+			return nil, errTaskNotFound
 		}
-		return fmt.Errorf("get task: %w", err)
+		return nil, fmt.Errorf("get task: %w", err)
 	}
 
-	task.Until = time.Now()
-	task.Minutes = int(task.Until.Sub(task.Since).Minutes())
+	return task, nil
+}
 
-	if err := s.repo.UpdateTask(ctx, task); err != nil {
-		return fmt.Errorf("update task: %w", err)
+// ListTasks returns a page of tasks of the user, the next page is requested with the cursor of the previous one.
+func (s *Service) ListTasks(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+	ctx, span := tracer.Start(ctx, "Service.ListTasks")
+	defer span.End()
+
+	if err := s.authorizeReader(ctx, userID); err != nil {
+		return nil, err
 	}
 
-	return nil
-}
+	if opts.Sort == "" {
+		opts.Sort = models.TaskSortID
+	}
+	if !opts.Sort.IsValid() {
+		return nil, invalid("invalid_sort", "sort", fmt.Sprintf("unknown sort field %q", opts.Sort))
+	}
+
+	switch opts.State {
+	case "", models.TaskRunning, models.TaskPaused, models.TaskFinished:
+	default:
+		return nil, invalid("invalid_state", "state", fmt.Sprintf("unknown task state %q", opts.State))
+	}
+
+	if !opts.From.IsZero() && !opts.To.IsZero() && !opts.From.Before(opts.To) {
+		return nil, invalid("invalid_period", "to", "to must be after from")
+	}
+
+	if opts.Limit < 1 {
+		opts.Limit = defaultTasksLimit
+	}
+	if opts.Limit > maxTasksLimit {
+		opts.Limit = maxTasksLimit
+	}
 
-func (s *Service) ListTasks(ctx context.Context, userID int) ([]models.Task, error) {
-	tasks, err := s.repo.ListTasks(ctx, userID)
+	tasks, err := s.repo.ListTasks(ctx, userID, opts)
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			return nil, invalid("invalid_cursor", "cursor", "cursor is malformed or was issued for another sort")
+		}
 		return nil, fmt.Errorf("list tasks: %w", err)
 	}
 
 	return tasks, nil
 }
+
+// parsePassport parses the passport sent by a client.
+func parsePassport(s string) (models.Passport, error) {
+	passport, err := models.ParsePassport(s)
+	if err != nil {
+		return models.Passport{}, invalid("invalid_passport", "passportNumber", err.Error())
+	}
+
+	return passport, nil
+}
+
+// Workload returns time spent by the user on each task within [from, to).
+// Running tasks are counted up to now or to, whichever comes first. Zero to means now.
+func (s *Service) Workload(ctx context.Context, userID int, from, to time.Time) (*models.Workload, error) {
+	ctx, span := tracer.Start(ctx, "Service.Workload")
+	defer span.End()
+
+	if err := s.authorizeReader(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if to.IsZero() {
+		to = now
+	}
+	if !from.Before(to) {
+		return nil, invalid("invalid_period", "from", "invalid period, from must be before to")
+	}
+
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	efforts, err := s.repo.TaskEfforts(ctx, userID, from, to, now)
+	if err != nil {
+		return nil, fmt.Errorf("task efforts: %w", err)
+	}
+
+	workload := &models.Workload{
+		UserID: userID,
+		From:   from,
+		To:     to,
+		Tasks:  efforts,
+	}
+	for _, e := range efforts {
+		workload.Total += e.Duration
+	}
+
+	return workload, nil
+}
@@ -4,35 +4,308 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/Nicholas2012/time-tracker/internal/models"
+	"github.com/Nicholas2012/time-tracker/internal/nameservice"
+	"github.com/Nicholas2012/time-tracker/internal/nameservice/nameservicetest"
+	"github.com/Nicholas2012/time-tracker/internal/tenant"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCreateUser_OK(t *testing.T) {
 	s, _ := setup(t)
-	err := s.CreateUser(context.TODO(), "1234 567890")
+	err := s.CreateUser(system(), "1234 567890")
 	require.NoError(t, err)
 }
 
-func TestCreateUser_InvalidPassportNumber(t *testing.T) {
-	s, _ := setup(t)
-	err := s.CreateUser(context.TODO(), "1234567890")
-	require.EqualError(t, err, "invalid passport number, must have at least 2 parts")
+func TestCreateUser_PassportFormats(t *testing.T) {
+	s, repo := setup(t)
+
+	var created *models.User
+	repo.CreateUserFn = func(ctx context.Context, user *models.User) error {
+		created = user
+		return nil
+	}
+
+	for _, passport := range []string{"0012 000001", "0012000001", "0012-000001", "  00 12  000 001 ", "0012\t000001"} {
+		created = nil
+		require.NoError(t, s.CreateUser(system(), passport), passport)
+		require.Equal(t, models.Passport{Series: "0012", Number: "000001"}, created.Passport, passport)
+	}
+
+	for _, passport := range []string{"", "1234 567890 extra", "1234 5678901", "123 56789", "abc 567890", "1234 abc", "１２３４ ５６７８９０"} {
+		err := s.CreateUser(system(), passport)
+		require.ErrorIs(t, err, ErrValidation, passport)
+		require.ErrorContains(t, err, "passport must be a 4-digit series and a 6-digit number", passport)
+	}
 }
 
-func TestCreateUser_BadSeries(t *testing.T) {
-	s, _ := setup(t)
-	err := s.CreateUser(context.TODO(), "abc 567890")
-	require.EqualError(t, err, "invalid passport series, must be a number, got: abc")
+func TestCreateUser_PassportTaken(t *testing.T) {
+	s, repo := setup(t)
+	repo.CreateUserFn = func(ctx context.Context, user *models.User) error {
+		return models.ErrDuplicate
+	}
+
+	err := s.CreateUser(system(), "1234 567890")
+	require.ErrorIs(t, err, ErrConflict)
+	require.ErrorIs(t, err, errPassportTaken)
 }
 
-func TestCreateUser_BadNumber(t *testing.T) {
-	s, _ := setup(t)
-	err := s.CreateUser(context.TODO(), "1234 abc")
-	require.EqualError(t, err, "invalid passport number, must be a number, got: abc")
+func TestGetUserByPassport(t *testing.T) {
+	s, repo := setup(t)
+	repo.GetUserByPassportFn = func(ctx context.Context, passport models.Passport) (*models.User, error) {
+		if passport == (models.Passport{Series: "0012", Number: "000001"}) {
+			return &models.User{ID: 7, Passport: passport}, nil
+		}
+		return nil, sql.ErrNoRows
+	}
+
+	user, err := s.GetUserByPassport(system(), "0012-000001")
+	require.NoError(t, err)
+	require.Equal(t, 7, user.ID)
+
+	_, err = s.GetUserByPassport(system(), "1234 567890")
+	require.ErrorIs(t, err, errUserNotFound)
+
+	_, err = s.GetUserByPassport(system(), "12")
+	require.ErrorIs(t, err, ErrValidation)
+
+	employee := WithActor(context.TODO(), Actor{UserID: 7, Role: models.RoleEmployee})
+	_, err = s.GetUserByPassport(employee, "0012 000001")
+	require.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestCreateUser_NameService(t *testing.T) {
+	ns := nameservicetest.NewServer(t)
+	ns.Add(models.Passport{Series: "1234", Number: "567890"}, nameservice.People{Name: "Ivan", Surname: "Ivanov", Patronymic: "Ivanovich", Address: "Moscow"})
+
+	repo := &repositoryMock{}
+	s := New(repo, WithNameService(newNameClient(ns), NamePolicyFail))
+
+	var created *models.User
+	repo.CreateUserFn = func(ctx context.Context, user *models.User) error {
+		created = user
+		return nil
+	}
+
+	err := s.CreateUser(system(), "1234 567890")
+	require.NoError(t, err)
+	require.Equal(t, &models.User{
+		Passport:   models.Passport{Series: "1234", Number: "567890"},
+		Name:       "Ivan",
+		Surname:    "Ivanov",
+		Patronymic: "Ivanovich",
+		Address:    "Moscow",
+	}, created)
+}
+
+func TestCreateUser_NameServiceUnavailable(t *testing.T) {
+	ns := nameservicetest.NewServer(t)
+	ns.FailNext(10)
+
+	repo := &repositoryMock{}
+	s := New(repo, WithNameService(newNameClient(ns), NamePolicyFail))
+
+	repo.CreateUserFn = func(ctx context.Context, user *models.User) error {
+		t.Fatal("user must not be created")
+		return nil
+	}
+
+	err := s.CreateUser(system(), "1234 567890")
+	require.ErrorIs(t, err, nameservice.ErrUnavailable)
+}
+
+func TestCreateUser_NameServiceUnavailableDefer(t *testing.T) {
+	ns := nameservicetest.NewServer(t)
+	ns.FailNext(10)
+
+	repo := &repositoryMock{}
+	s := New(repo, WithNameService(newNameClient(ns), NamePolicyDefer))
+
+	var created *models.User
+	repo.CreateUserFn = func(ctx context.Context, user *models.User) error {
+		created = user
+		return nil
+	}
+
+	err := s.CreateUser(system(), "1234 567890")
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	require.Empty(t, created.Name)
+	require.Equal(t, models.EnrichPending, created.EnrichStatus)
+}
+
+func TestCreateUser_NameServiceNotFoundDefer(t *testing.T) {
+	ns := nameservicetest.NewServer(t)
+	s := New(&repositoryMock{}, WithNameService(newNameClient(ns), NamePolicyDefer))
+
+	err := s.CreateUser(system(), "1234 567890")
+	require.ErrorIs(t, err, nameservice.ErrNotFound)
+}
+
+func TestEnrichPendingUsers_OK(t *testing.T) {
+	ns := nameservicetest.NewServer(t)
+	ns.Add(models.Passport{Series: "1234", Number: "567890"}, nameservice.People{Name: "Ivan", Surname: "Ivanov"})
+
+	repo := &repositoryMock{}
+	s := New(repo, WithNameService(newNameClient(ns), NamePolicyDefer))
+
+	repo.ListOrgsFn = func(ctx context.Context) ([]models.Org, error) {
+		return []models.Org{{ID: models.DefaultOrgID}}, nil
+	}
+	repo.ListPendingUsersFn = func(ctx context.Context, limit int) ([]models.User, error) {
+		orgID, _ := tenant.OrgFrom(ctx)
+		require.Equal(t, models.DefaultOrgID, orgID)
+		return []models.User{
+			{ID: 1, Passport: models.Passport{Series: "1234", Number: "567890"}},
+			{ID: 2, Passport: models.Passport{Series: "1111", Number: "111111"}},
+		}, nil
+	}
+
+	var updated []models.User
+	repo.UpdateUserFn = func(ctx context.Context, user *models.User) error {
+		updated = append(updated, *user)
+		return nil
+	}
+	failed := make(map[int]models.EnrichStatus)
+	repo.FailEnrichmentFn = func(ctx context.Context, id int, status models.EnrichStatus) error {
+		failed[id] = status
+		return nil
+	}
+
+	n, err := s.EnrichPendingUsers(system())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Len(t, updated, 1)
+	require.Equal(t, 1, updated[0].ID)
+	require.Equal(t, "Ivan", updated[0].Name)
+	require.Equal(t, models.EnrichDone, updated[0].EnrichStatus)
+
+	// the name service doesn't know the person, so the user is not retried
+	require.Equal(t, map[int]models.EnrichStatus{2: models.EnrichNotFound}, failed)
+}
+
+type nameServiceFunc func(ctx context.Context, user *models.User) error
+
+func (f nameServiceFunc) Enrich(ctx context.Context, user *models.User) error {
+	return f(ctx, user)
+}
+
+func TestEnrichPendingUsers_Attempts(t *testing.T) {
+	repo := &repositoryMock{}
+	s := New(repo, WithNameService(nameServiceFunc(func(ctx context.Context, user *models.User) error {
+		return errors.New("invalid response")
+	}), NamePolicyDefer))
+
+	repo.ListPendingUsersFn = func(ctx context.Context, limit int) ([]models.User, error) {
+		return []models.User{
+			{ID: 1, EnrichStatus: models.EnrichPending},
+			{ID: 2, EnrichStatus: models.EnrichPending, EnrichAttempts: maxEnrichAttempts - 1},
+		}, nil
+	}
+	failed := make(map[int]models.EnrichStatus)
+	repo.FailEnrichmentFn = func(ctx context.Context, id int, status models.EnrichStatus) error {
+		failed[id] = status
+		return nil
+	}
+
+	n, err := s.EnrichPendingUsers(tenant.WithOrg(system(), models.DefaultOrgID))
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.Equal(t, map[int]models.EnrichStatus{1: models.EnrichPending, 2: models.EnrichFailed}, failed)
+}
+
+func TestGetUser_NotFound(t *testing.T) {
+	s, repo := setup(t)
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return nil, sql.ErrNoRows
+	}
+
+	_, err := s.GetUser(system(), 1)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestListUsers_Defaults(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.ListUsersFn = func(ctx context.Context, opts models.UserListOpts) (*models.UserList, error) {
+		require.Equal(t, 1, opts.Page)
+		require.Equal(t, defaultUsersLimit, opts.Limit)
+		require.Equal(t, "Ivan", opts.Name)
+		return &models.UserList{}, nil
+	}
+
+	_, err := s.ListUsers(system(), models.UserListOpts{Name: "Ivan"})
+	require.NoError(t, err)
+
+	repo.ListUsersFn = func(ctx context.Context, opts models.UserListOpts) (*models.UserList, error) {
+		require.Equal(t, maxUsersLimit, opts.Limit)
+		return &models.UserList{}, nil
+	}
+
+	_, err = s.ListUsers(system(), models.UserListOpts{Page: 2, Limit: 1000})
+	require.NoError(t, err)
+}
+
+func TestUpdateUser_OK(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id, Name: "Ivan", Surname: "Ivanov", Passport: models.Passport{Series: "1234", Number: "567890"}}, nil
+	}
+	repo.UpdateUserFn = func(ctx context.Context, user *models.User) error {
+		return nil
+	}
+
+	name, passport := "Petr", "4321 098765"
+	user, err := s.UpdateUser(system(), 1, UserUpdate{Name: &name, Passport: &passport})
+	require.NoError(t, err)
+	require.Equal(t, &models.User{ID: 1, Name: "Petr", Surname: "Ivanov", Passport: models.Passport{Series: "4321", Number: "098765"}}, user)
+}
+
+func TestUpdateUser_PassportTaken(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.UpdateUserFn = func(ctx context.Context, user *models.User) error {
+		return models.ErrDuplicate
+	}
+
+	passport := "4321 098765"
+	_, err := s.UpdateUser(system(), 1, UserUpdate{Passport: &passport})
+	require.ErrorIs(t, err, errPassportTaken)
+}
+
+func TestUpdateUser_BadPassport(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.UpdateUserFn = func(ctx context.Context, user *models.User) error {
+		t.Fatal("user must not be updated")
+		return nil
+	}
+
+	passport := "abc 567890"
+	_, err := s.UpdateUser(system(), 1, UserUpdate{Passport: &passport})
+	require.EqualError(t, err, "passport must be a 4-digit series and a 6-digit number")
+}
+
+func TestDeleteUser_NotFound(t *testing.T) {
+	s, repo := setup(t)
+	repo.DeleteUserFn = func(ctx context.Context, user *models.User) error {
+		require.Equal(t, 7, user.ID)
+		return sql.ErrNoRows
+	}
+
+	err := s.DeleteUser(system(), 7)
+	require.ErrorIs(t, err, ErrNotFound)
 }
 
 func TestStartTask_OK(t *testing.T) {
@@ -50,18 +323,73 @@ func TestStartTask_OK(t *testing.T) {
 		return nil
 	}
 
-	id, err := s.StartTask(context.TODO(), testUser.ID)
+	id, err := s.StartTask(system(), testUser.ID, TaskInfo{})
 	require.NoError(t, err)
 	require.NotZero(t, id)
 }
 
+func TestStartTask_Described(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.GetProjectFn = func(ctx context.Context, id int) (*models.Project, error) {
+		return &models.Project{ID: id, Name: "Time tracker"}, nil
+	}
+	repo.GetTagFn = func(ctx context.Context, id int) (*models.Tag, error) {
+		return &models.Tag{ID: id, Name: fmt.Sprint("tag", id)}, nil
+	}
+	repo.CreateTaskFn = func(ctx context.Context, task *models.Task) error {
+		require.Equal(t, "Fix bug", task.Title)
+		require.Equal(t, "Reports are empty", task.Description)
+		require.Equal(t, 3, task.ProjectID)
+		require.Equal(t, []models.Tag{{ID: 1, Name: "tag1"}, {ID: 2, Name: "tag2"}}, task.Tags)
+		task.ID = 1
+		return nil
+	}
+
+	id, err := s.StartTask(system(), 99, TaskInfo{
+		Title:       " Fix bug ",
+		Description: "Reports are empty",
+		ProjectID:   3,
+		TagIDs:      []int{1, 2},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, id)
+}
+
+func TestStartTask_UnknownTag(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.GetTagFn = func(ctx context.Context, id int) (*models.Tag, error) {
+		return nil, sql.ErrNoRows
+	}
+	repo.CreateTaskFn = func(ctx context.Context, task *models.Task) error {
+		t.Fatal("task must not be created")
+		return nil
+	}
+
+	_, err := s.StartTask(system(), 99, TaskInfo{TagIDs: []int{7}})
+	require.ErrorIs(t, err, ErrValidation)
+	require.EqualError(t, err, "tag 7 not found")
+
+	var domainErr *Error
+	require.ErrorAs(t, err, &domainErr)
+	require.Equal(t, "unknown_tag", domainErr.Code)
+	require.Equal(t, []FieldError{{Field: "tagIds", Message: "tag 7 not found"}}, domainErr.Fields)
+}
+
 func TestStartTask_UserNotFound(t *testing.T) {
 	s, repo := setup(t)
 	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
 		return nil, sql.ErrNoRows
 	}
 
-	_, err := s.StartTask(context.TODO(), 1)
+	_, err := s.StartTask(system(), 1, TaskInfo{})
 
 	require.ErrorIs(t, err, ErrNotFound)
 }
@@ -78,11 +406,97 @@ func TestStartTask_Error(t *testing.T) {
 		return errors.New("test error")
 	}
 
-	_, err := s.StartTask(context.TODO(), 1)
+	_, err := s.StartTask(system(), 1, TaskInfo{})
 
 	require.EqualError(t, err, "create task: test error")
 }
 
+func TestStartTask_AlreadyRunning(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.CreateTaskFn = func(ctx context.Context, task *models.Task) error {
+		return models.ErrDuplicate
+	}
+
+	_, err := s.StartTask(system(), 99, TaskInfo{})
+	require.ErrorIs(t, err, ErrConflict)
+}
+
+func TestStartTask_SwitchPolicy(t *testing.T) {
+	repo := &repositoryMock{}
+	s := New(repo, WithStartPolicy(StartPolicySwitch))
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.CreateTaskFn = func(ctx context.Context, task *models.Task) error {
+		t.Fatal("task must be switched")
+		return nil
+	}
+	repo.SwitchTaskFn = func(ctx context.Context, task *models.Task) (*models.Task, error) {
+		task.ID = 2
+		return &models.Task{ID: 1}, nil
+	}
+
+	id, err := s.StartTask(system(), 99, TaskInfo{})
+	require.NoError(t, err)
+	require.Equal(t, 2, id)
+}
+
+func TestSwitchTask_OK(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.SwitchTaskFn = func(ctx context.Context, task *models.Task) (*models.Task, error) {
+		require.Equal(t, 99, task.UserID)
+		require.Equal(t, "Review", task.Title)
+		require.NotZero(t, task.Since)
+		task.ID = 2
+
+		stopped := &models.Task{ID: 1, Since: task.Since.Add(-time.Hour)}
+		stopped.End(task.Since)
+		return stopped, nil
+	}
+
+	id, stoppedID, err := s.SwitchTask(system(), 99, TaskInfo{Title: "Review"})
+	require.NoError(t, err)
+	require.Equal(t, 2, id)
+	require.Equal(t, 1, stoppedID)
+}
+
+func TestSwitchTask_NothingRunning(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.SwitchTaskFn = func(ctx context.Context, task *models.Task) (*models.Task, error) {
+		task.ID = 2
+		return nil, nil
+	}
+
+	id, stoppedID, err := s.SwitchTask(system(), 99, TaskInfo{})
+	require.NoError(t, err)
+	require.Equal(t, 2, id)
+	require.Zero(t, stoppedID)
+}
+
+func TestSwitchTask_UserNotFound(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return nil, sql.ErrNoRows
+	}
+
+	_, _, err := s.SwitchTask(system(), 99, TaskInfo{})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
 func TestEndTask_OK(t *testing.T) {
 	s, repo := setup(t)
 
@@ -96,7 +510,7 @@ func TestEndTask_OK(t *testing.T) {
 	repo.GetTaskFn = func(ctx context.Context, userID, id int) (*models.Task, error) {
 		return testTask, nil
 	}
-	repo.UpdateTaskFn = func(ctx context.Context, task *models.Task) error {
+	repo.UpdateTaskFn = func(ctx context.Context, task *models.Task, read models.TaskRevision) error {
 		require.NotZero(t, task.ID)
 		require.NotEmpty(t, task.Since)
 		require.NotEmpty(t, task.Until)
@@ -106,7 +520,7 @@ func TestEndTask_OK(t *testing.T) {
 		return nil
 	}
 
-	err := s.EndTask(context.TODO(), testUser.ID, testTask.ID)
+	err := s.EndTask(system(), testUser.ID, testTask.ID)
 	require.NoError(t, err)
 }
 
@@ -116,7 +530,7 @@ func TestEndTask_UserNotFound(t *testing.T) {
 		return nil, sql.ErrNoRows
 	}
 
-	err := s.EndTask(context.TODO(), 1, 1)
+	err := s.EndTask(system(), 1, 1)
 
 	require.ErrorIs(t, err, ErrNotFound)
 }
@@ -130,29 +544,245 @@ func TestEndTask_TaskNotFound(t *testing.T) {
 		return nil, sql.ErrNoRows
 	}
 
-	err := s.EndTask(context.TODO(), 99, 1)
+	err := s.EndTask(system(), 99, 1)
 
 	require.ErrorIs(t, err, ErrNotFound)
 }
 
+func TestEndTask_Finished(t *testing.T) {
+	s, repo := setup(t)
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: 99}, nil
+	}
+	repo.GetTaskFn = func(ctx context.Context, userID, id int) (*models.Task, error) {
+		return &models.Task{ID: 1, UserID: 99, Since: time.Now().Add(-time.Hour), Until: time.Now()}, nil
+	}
+
+	err := s.EndTask(system(), 99, 1)
+
+	require.ErrorIs(t, err, ErrConflict)
+	require.ErrorIs(t, err, errTaskFinished)
+}
+
+func TestEndTask_Changed(t *testing.T) {
+	s, repo := setup(t)
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: 99}, nil
+	}
+	repo.GetTaskFn = func(ctx context.Context, userID, id int) (*models.Task, error) {
+		task := models.NewTask(99)
+		task.ID = 1
+		return task, nil
+	}
+	repo.UpdateTaskFn = func(ctx context.Context, task *models.Task, read models.TaskRevision) error {
+		require.Equal(t, models.TaskRevision{State: models.TaskRunning, Segments: 1}, read)
+		// auto-stopped meanwhile
+		return models.ErrStale
+	}
+
+	err := s.EndTask(system(), 99, 1)
+
+	require.ErrorIs(t, err, ErrConflict)
+	require.Equal(t, errTaskChanged, err)
+}
+
+func TestEndTask_CountsSegments(t *testing.T) {
+	s, repo := setup(t)
+
+	now := time.Now()
+	testTask := &models.Task{
+		ID:     1,
+		UserID: 99,
+		Since:  now.Add(-3 * time.Hour),
+		Segments: []models.TaskSegment{
+			{Since: now.Add(-3 * time.Hour), Until: now.Add(-2 * time.Hour)},
+			{Since: now.Add(-30 * time.Minute)},
+		},
+	}
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: 99}, nil
+	}
+	repo.GetTaskFn = func(ctx context.Context, userID, id int) (*models.Task, error) {
+		return testTask, nil
+	}
+
+	var updated *models.Task
+	repo.UpdateTaskFn = func(ctx context.Context, task *models.Task, read models.TaskRevision) error {
+		updated = task
+		return nil
+	}
+
+	require.NoError(t, s.EndTask(system(), 99, 1))
+	require.Equal(t, models.TaskFinished, updated.State())
+	require.Equal(t, 90, updated.Minutes)
+	require.False(t, updated.Segments[1].Until.IsZero())
+}
+
+func TestPauseResumeTask(t *testing.T) {
+	s, repo := setup(t)
+
+	testTask := models.NewTask(99)
+	testTask.ID = 1
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: 99}, nil
+	}
+	repo.GetTaskFn = func(ctx context.Context, userID, id int) (*models.Task, error) {
+		return testTask, nil
+	}
+	repo.UpdateTaskFn = func(ctx context.Context, task *models.Task, read models.TaskRevision) error {
+		testTask = task
+		return nil
+	}
+
+	require.ErrorIs(t, s.ResumeTask(system(), 99, 1), errTaskResumed)
+
+	require.NoError(t, s.PauseTask(system(), 99, 1))
+	require.Equal(t, models.TaskPaused, testTask.State())
+	require.ErrorIs(t, s.PauseTask(system(), 99, 1), errTaskPaused)
+
+	require.NoError(t, s.ResumeTask(system(), 99, 1))
+	require.Equal(t, models.TaskRunning, testTask.State())
+	require.Len(t, testTask.Segments, 2)
+
+	require.NoError(t, s.EndTask(system(), 99, 1))
+	require.ErrorIs(t, s.PauseTask(system(), 99, 1), errTaskFinished)
+	require.ErrorIs(t, s.ResumeTask(system(), 99, 1), ErrConflict)
+}
+
 func TestListTasks_OK(t *testing.T) {
 	s, repo := setup(t)
 
 	testUser := &models.User{ID: 99}
-	testTasks := []models.Task{
-		{ID: 1, UserID: testUser.ID, Since: time.Now(), Until: time.Now().Add(time.Hour), Minutes: 60},
+	testTasks := &models.TaskList{
+		Tasks: []models.Task{
+			{ID: 1, UserID: testUser.ID, Since: time.Now(), Until: time.Now().Add(time.Hour), Minutes: 60},
+		},
+		Count: 1,
+		Limit: defaultTasksLimit,
 	}
 
-	repo.ListTasksFn = func(ctx context.Context, userID int) ([]models.Task, error) {
+	repo.ListTasksFn = func(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+		require.Equal(t, models.TaskListOpts{
+			TaskFilter: models.TaskFilter{ProjectID: 5},
+			Sort:       models.TaskSortID,
+			Limit:      defaultTasksLimit,
+		}, opts)
 		return testTasks, nil
 	}
 
-	tasks, err := s.ListTasks(context.TODO(), testUser.ID)
+	tasks, err := s.ListTasks(system(), testUser.ID, models.TaskListOpts{TaskFilter: models.TaskFilter{ProjectID: 5}})
 	require.NoError(t, err)
 	require.Equal(t, testTasks, tasks)
+
+	repo.ListTasksFn = func(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+		require.Equal(t, maxTasksLimit, opts.Limit)
+		return testTasks, nil
+	}
+
+	_, err = s.ListTasks(system(), testUser.ID, models.TaskListOpts{Limit: 10000})
+	require.NoError(t, err)
+}
+
+func TestListTasks_Invalid(t *testing.T) {
+	s, repo := setup(t)
+	repo.ListTasksFn = func(ctx context.Context, userID int, opts models.TaskListOpts) (*models.TaskList, error) {
+		return nil, models.ErrInvalidCursor
+	}
+
+	now := time.Now()
+	cases := map[string]models.TaskListOpts{
+		"sort":   {Sort: "title"},
+		"state":  {TaskFilter: models.TaskFilter{State: "stopped"}},
+		"period": {TaskFilter: models.TaskFilter{From: now, To: now.Add(-time.Hour)}},
+		"cursor": {Cursor: "garbage"},
+	}
+
+	for name, opts := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.ListTasks(system(), 99, opts)
+
+			var e *Error
+			require.ErrorAs(t, err, &e)
+			require.Equal(t, KindValidation, e.Kind)
+			require.Equal(t, "invalid_"+name, e.Code)
+		})
+	}
+}
+
+// system returns a context of internal calls, they are not restricted.
+func system() context.Context {
+	return WithActor(context.TODO(), System)
+}
+
+func newNameClient(ns *nameservicetest.Server) *nameservice.Client {
+	return nameservice.New(ns.URL, nameservice.Options{Timeout: time.Second, Retries: 1, Backoff: time.Millisecond})
 }
 
 func setup(_ *testing.T) (*Service, *repositoryMock) {
 	repo := &repositoryMock{}
 	return New(repo), repo
 }
+
+func TestWorkload_OK(t *testing.T) {
+	s, repo := setup(t)
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.TaskEffortsFn = func(ctx context.Context, userID int, f, tt, now time.Time) ([]models.TaskEffort, error) {
+		require.Equal(t, 99, userID)
+		require.Equal(t, from, f)
+		require.Equal(t, to, tt)
+		require.False(t, now.IsZero())
+
+		return []models.TaskEffort{
+			{TaskID: 1, Duration: 3 * time.Hour},
+			{TaskID: 2, Duration: 30 * time.Minute},
+		}, nil
+	}
+
+	w, err := s.Workload(system(), 99, from, to)
+	require.NoError(t, err)
+	require.Equal(t, 99, w.UserID)
+	require.Len(t, w.Tasks, 2)
+	require.Equal(t, 3*time.Hour+30*time.Minute, w.Total)
+}
+
+func TestWorkload_DefaultTo(t *testing.T) {
+	s, repo := setup(t)
+
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}
+	repo.TaskEffortsFn = func(ctx context.Context, userID int, from, to, now time.Time) ([]models.TaskEffort, error) {
+		require.Equal(t, now, to)
+		return nil, nil
+	}
+
+	w, err := s.Workload(system(), 99, time.Now().Add(-time.Hour), time.Time{})
+	require.NoError(t, err)
+	require.Zero(t, w.Total)
+}
+
+func TestWorkload_BadPeriod(t *testing.T) {
+	s, _ := setup(t)
+
+	now := time.Now()
+	_, err := s.Workload(system(), 99, now, now.Add(-time.Hour))
+	require.EqualError(t, err, "invalid period, from must be before to")
+}
+
+func TestWorkload_UserNotFound(t *testing.T) {
+	s, repo := setup(t)
+	repo.GetUserFn = func(ctx context.Context, id int) (*models.User, error) {
+		return nil, sql.ErrNoRows
+	}
+
+	_, err := s.Workload(system(), 99, time.Now().Add(-time.Hour), time.Time{})
+	require.ErrorIs(t, err, ErrNotFound)
+}
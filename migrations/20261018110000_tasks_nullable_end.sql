@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ALTER COLUMN end_time DROP NOT NULL;
+UPDATE tasks SET end_time = NULL WHERE end_time <= '0001-01-01 00:00:00+00';
+CREATE INDEX tasks_user_start ON tasks (user_id, start_time);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX tasks_user_start;
+UPDATE tasks SET end_time = '0001-01-01 00:00:00+00' WHERE end_time IS NULL;
+ALTER TABLE tasks ALTER COLUMN end_time SET NOT NULL;
+-- +goose StatementEnd
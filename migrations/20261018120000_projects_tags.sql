@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE projects (
+                    id SERIAL PRIMARY KEY,
+                    name VARCHAR NOT NULL UNIQUE,
+                    description VARCHAR NOT NULL DEFAULT ''
+);
+CREATE TABLE tags (
+                    id SERIAL PRIMARY KEY,
+                    name VARCHAR NOT NULL UNIQUE
+);
+ALTER TABLE tasks
+    ADD COLUMN title VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN description VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN project_id INT REFERENCES projects(id) ON DELETE SET NULL;
+CREATE INDEX tasks_project ON tasks (project_id);
+CREATE TABLE task_tags (
+                    task_id INT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+                    tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+                    PRIMARY KEY (task_id, tag_id)
+);
+CREATE INDEX task_tags_tag ON task_tags (tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE task_tags;
+ALTER TABLE tasks
+    DROP COLUMN title,
+    DROP COLUMN description,
+    DROP COLUMN project_id;
+DROP TABLE tags;
+DROP TABLE projects;
+-- +goose StatementEnd
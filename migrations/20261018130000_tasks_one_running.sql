@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- only the latest running task of a user is left running, the others end when the next task of the user starts
+-- as if the user had switched to it
+UPDATE tasks t
+SET end_time = n.next_start,
+    minutes = (EXTRACT(EPOCH FROM n.next_start - t.start_time) / 60)::INT
+FROM (SELECT id, LEAD(start_time) OVER (PARTITION BY user_id ORDER BY start_time, id) AS next_start FROM tasks) n
+WHERE n.id = t.id
+  AND t.end_time IS NULL
+  AND EXISTS (SELECT 1 FROM tasks o
+              WHERE o.user_id = t.user_id AND o.end_time IS NULL AND (o.start_time, o.id) > (t.start_time, t.id));
+CREATE UNIQUE INDEX tasks_one_running ON tasks (user_id) WHERE end_time IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX tasks_one_running;
+-- +goose StatementEnd
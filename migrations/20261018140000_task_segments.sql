@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE task_segments (
+                    id SERIAL PRIMARY KEY,
+                    task_id INT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+                    start_time timestamptz NOT NULL,
+                    end_time timestamptz
+);
+CREATE INDEX task_segments_task ON task_segments (task_id, start_time);
+CREATE UNIQUE INDEX task_segments_one_open ON task_segments (task_id) WHERE end_time IS NULL;
+-- existing tasks are worked in a single segment
+INSERT INTO task_segments (task_id, start_time, end_time)
+SELECT id, start_time, end_time FROM tasks;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE task_segments;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN role VARCHAR NOT NULL DEFAULT 'employee' CHECK (role IN ('employee', 'manager', 'admin')),
+    ADD COLUMN manager_id INT REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX users_manager ON users (manager_id);
+CREATE TABLE api_keys (
+                    id SERIAL PRIMARY KEY,
+                    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                    name VARCHAR NOT NULL DEFAULT '',
+                    prefix VARCHAR NOT NULL,
+                    hash BYTEA NOT NULL UNIQUE,
+                    created_at timestamptz NOT NULL DEFAULT now(),
+                    revoked_at timestamptz
+);
+CREATE INDEX api_keys_user ON api_keys (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_keys;
+ALTER TABLE users DROP COLUMN manager_id, DROP COLUMN role;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN auto_stopped BOOLEAN NOT NULL DEFAULT false;
+-- the auto-stop worker looks for segments open for too long
+CREATE INDEX task_segments_open_start ON task_segments (start_time) WHERE end_time IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX task_segments_open_start;
+ALTER TABLE tasks DROP COLUMN auto_stopped;
+-- +goose StatementEnd
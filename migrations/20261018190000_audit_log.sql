@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- actors and entities are not referenced, entries outlive deleted users and tasks
+CREATE TABLE audit_log (
+                    id BIGSERIAL PRIMARY KEY,
+                    created_at TIMESTAMPTZ NOT NULL,
+                    actor_id INT,
+                    actor_role TEXT NOT NULL DEFAULT '',
+                    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+                    entity TEXT NOT NULL CHECK (entity IN ('user', 'task')),
+                    entity_id INT NOT NULL,
+                    before JSONB,
+                    after JSONB,
+                    request_id TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX audit_log_entity ON audit_log (entity, entity_id, id);
+CREATE INDEX audit_log_actor ON audit_log (actor_id, id);
+CREATE INDEX audit_log_created_at ON audit_log (created_at);
+
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_log;
+DROP FUNCTION audit_log_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,66 @@
+-- +goose Up
+-- +goose StatementBegin
+-- passports are stored as entered, leading zeros of the series and number are significant
+ALTER TABLE users
+    ALTER COLUMN passport_serie TYPE TEXT
+        USING CASE WHEN passport_serie BETWEEN 0 AND 9999 THEN lpad(passport_serie::TEXT, 4, '0') ELSE passport_serie::TEXT END,
+    ALTER COLUMN passport_number TYPE TEXT
+        USING CASE WHEN passport_number BETWEEN 0 AND 999999 THEN lpad(passport_number::TEXT, 6, '0') ELSE passport_number::TEXT END;
+
+-- users registered with the same passport are merged into the one registered first
+CREATE TEMPORARY TABLE passport_duplicates ON COMMIT DROP AS
+SELECT id, keep_id
+FROM (SELECT id, min(id) OVER (PARTITION BY passport_serie, passport_number) AS keep_id FROM users) u
+WHERE id <> keep_id;
+
+-- only the latest running task of merged users is left running
+CREATE TEMPORARY TABLE passport_stopped_tasks ON COMMIT DROP AS
+SELECT t.id
+FROM tasks t
+LEFT JOIN passport_duplicates d ON d.id = t.user_id
+WHERE t.end_time IS NULL
+  AND EXISTS (SELECT 1
+              FROM tasks o
+              LEFT JOIN passport_duplicates od ON od.id = o.user_id
+              WHERE o.end_time IS NULL
+                AND o.id > t.id
+                AND COALESCE(od.keep_id, o.user_id) = COALESCE(d.keep_id, t.user_id));
+UPDATE task_segments
+SET end_time = now()
+WHERE end_time IS NULL
+  AND task_id IN (SELECT id FROM passport_stopped_tasks);
+UPDATE tasks t
+SET end_time = now(),
+    minutes = (SELECT COALESCE(SUM(EXTRACT(EPOCH FROM s.end_time - s.start_time)), 0) / 60
+               FROM task_segments s
+               WHERE s.task_id = t.id)::INT
+WHERE t.id IN (SELECT id FROM passport_stopped_tasks);
+
+UPDATE tasks t SET user_id = d.keep_id FROM passport_duplicates d WHERE t.user_id = d.id;
+UPDATE api_keys k SET user_id = d.keep_id FROM passport_duplicates d WHERE k.user_id = d.id;
+UPDATE users u SET manager_id = NULLIF(d.keep_id, u.id) FROM passport_duplicates d WHERE u.manager_id = d.id;
+
+INSERT INTO audit_log (created_at, action, entity, entity_id, before)
+SELECT now(), 'delete', 'user', u.id,
+       json_build_object('id', u.id, 'passportSerie', u.passport_serie, 'passportNumber', u.passport_number,
+                         'name', u.name, 'surname', u.surname, 'patronymic', u.patronymic, 'address', u.address,
+                         'role', u.role, 'managerId', u.manager_id)
+FROM users u
+JOIN passport_duplicates d ON d.id = u.id;
+DELETE FROM users WHERE id IN (SELECT id FROM passport_duplicates);
+
+CREATE UNIQUE INDEX users_passport ON users (passport_serie, passport_number);
+-- malformed passports stored before are left for manual review
+ALTER TABLE users ADD CONSTRAINT users_passport_format
+    CHECK (passport_serie ~ '^[0-9]{4}$' AND passport_number ~ '^[0-9]{6}$') NOT VALID;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- merged users are not restored
+ALTER TABLE users DROP CONSTRAINT users_passport_format;
+DROP INDEX users_passport;
+ALTER TABLE users
+    ALTER COLUMN passport_serie TYPE INT USING passport_serie::INT,
+    ALTER COLUMN passport_number TYPE INT USING passport_number::INT;
+-- +goose StatementEnd
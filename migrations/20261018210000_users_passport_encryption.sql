@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+-- passports are encrypted by the application on start as keys are not available to migrations,
+-- plaintext columns are cleared then and left until every deployment has encrypted its passports
+ALTER TABLE users
+    ADD COLUMN passport_encrypted BYTEA,
+    ADD COLUMN passport_index BYTEA,
+    ADD COLUMN passport_key TEXT,
+    ALTER COLUMN passport_serie SET DEFAULT '',
+    ALTER COLUMN passport_number SET DEFAULT '',
+    DROP CONSTRAINT users_passport_format;
+-- uniqueness is kept by the blind index
+DROP INDEX users_passport;
+CREATE UNIQUE INDEX users_passport_index ON users (passport_index);
+
+-- snapshots of users in the audit log don't keep passports
+ALTER TABLE audit_log DISABLE TRIGGER audit_log_no_update;
+UPDATE audit_log
+SET before = before - 'passportSerie' - 'passportNumber',
+    after = after - 'passportSerie' - 'passportNumber'
+WHERE entity = 'user';
+ALTER TABLE audit_log ENABLE TRIGGER audit_log_no_update;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE passport_encrypted IS NOT NULL) THEN
+        RAISE EXCEPTION 'passports are encrypted, they would be lost';
+    END IF;
+END;
+$$;
+DROP INDEX users_passport_index;
+CREATE UNIQUE INDEX users_passport ON users (passport_serie, passport_number);
+ALTER TABLE users
+    DROP COLUMN passport_encrypted,
+    DROP COLUMN passport_index,
+    DROP COLUMN passport_key,
+    ALTER COLUMN passport_serie DROP DEFAULT,
+    ALTER COLUMN passport_number DROP DEFAULT,
+    ADD CONSTRAINT users_passport_format
+        CHECK (passport_serie ~ '^[0-9]{4}$' AND passport_number ~ '^[0-9]{6}$') NOT VALID;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE teams (
+                    id SERIAL PRIMARY KEY,
+                    name VARCHAR NOT NULL UNIQUE,
+                    manager_id INT REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX teams_manager ON teams (manager_id);
+CREATE TABLE team_members (
+                    team_id INT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+                    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                    PRIMARY KEY (team_id, user_id)
+);
+CREATE INDEX team_members_user ON team_members (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE team_members;
+DROP TABLE teams;
+-- +goose StatementEnd
@@ -0,0 +1,114 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE organizations (
+                    id SERIAL PRIMARY KEY,
+                    name VARCHAR NOT NULL UNIQUE
+);
+-- data stored before organizations belongs to the default one
+INSERT INTO organizations (id, name) VALUES (1, 'Default');
+SELECT setval('organizations_id_seq', 1);
+
+ALTER TABLE users ADD COLUMN org_id INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE tasks ADD COLUMN org_id INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE projects ADD COLUMN org_id INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE tags ADD COLUMN org_id INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE teams ADD COLUMN org_id INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE api_keys ADD COLUMN org_id INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE audit_log ADD COLUMN org_id INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE users ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE tasks ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE projects ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE tags ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE teams ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE audit_log ALTER COLUMN org_id DROP DEFAULT;
+
+-- names and passports are unique within an organization
+DROP INDEX users_passport_index;
+CREATE UNIQUE INDEX users_passport_index ON users (org_id, passport_index);
+ALTER TABLE projects DROP CONSTRAINT projects_name_key;
+ALTER TABLE projects ADD CONSTRAINT projects_name_key UNIQUE (org_id, name);
+ALTER TABLE tags DROP CONSTRAINT tags_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_name_key UNIQUE (org_id, name);
+ALTER TABLE teams DROP CONSTRAINT teams_name_key;
+ALTER TABLE teams ADD CONSTRAINT teams_name_key UNIQUE (org_id, name);
+CREATE INDEX tasks_org ON tasks (org_id);
+CREATE INDEX audit_log_org ON audit_log (org_id, id);
+
+-- Row-level security is a backstop for filters of queries: the service sets app.org_id for each transaction
+-- and rows of other organizations are neither visible nor writable. It is forced for the owner of the tables too,
+-- so later data migrations must set app.org_id or run as a role with BYPASSRLS. Superusers are never restricted,
+-- the service must connect as an ordinary role. API keys are looked up before the organization is known.
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+CREATE POLICY users_org ON users USING (org_id = NULLIF(current_setting('app.org_id', true), '')::INT);
+ALTER TABLE tasks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks FORCE ROW LEVEL SECURITY;
+CREATE POLICY tasks_org ON tasks USING (org_id = NULLIF(current_setting('app.org_id', true), '')::INT);
+ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+ALTER TABLE projects FORCE ROW LEVEL SECURITY;
+CREATE POLICY projects_org ON projects USING (org_id = NULLIF(current_setting('app.org_id', true), '')::INT);
+ALTER TABLE tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tags FORCE ROW LEVEL SECURITY;
+CREATE POLICY tags_org ON tags USING (org_id = NULLIF(current_setting('app.org_id', true), '')::INT);
+ALTER TABLE teams ENABLE ROW LEVEL SECURITY;
+ALTER TABLE teams FORCE ROW LEVEL SECURITY;
+CREATE POLICY teams_org ON teams USING (org_id = NULLIF(current_setting('app.org_id', true), '')::INT);
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_log FORCE ROW LEVEL SECURITY;
+CREATE POLICY audit_log_org ON audit_log USING (org_id = NULLIF(current_setting('app.org_id', true), '')::INT);
+
+-- rows of child tables belong to the organization of their parent, which is itself restricted
+ALTER TABLE task_segments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_segments FORCE ROW LEVEL SECURITY;
+CREATE POLICY task_segments_org ON task_segments USING (EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_id));
+ALTER TABLE task_tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_tags FORCE ROW LEVEL SECURITY;
+CREATE POLICY task_tags_org ON task_tags USING (EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_id));
+ALTER TABLE team_members ENABLE ROW LEVEL SECURITY;
+ALTER TABLE team_members FORCE ROW LEVEL SECURITY;
+CREATE POLICY team_members_org ON team_members USING (EXISTS (SELECT 1 FROM teams t WHERE t.id = team_id));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- data of all organizations is left together, names and passports must be unique across them
+DROP POLICY team_members_org ON team_members;
+ALTER TABLE team_members NO FORCE ROW LEVEL SECURITY, DISABLE ROW LEVEL SECURITY;
+DROP POLICY task_tags_org ON task_tags;
+ALTER TABLE task_tags NO FORCE ROW LEVEL SECURITY, DISABLE ROW LEVEL SECURITY;
+DROP POLICY task_segments_org ON task_segments;
+ALTER TABLE task_segments NO FORCE ROW LEVEL SECURITY, DISABLE ROW LEVEL SECURITY;
+DROP POLICY audit_log_org ON audit_log;
+ALTER TABLE audit_log NO FORCE ROW LEVEL SECURITY, DISABLE ROW LEVEL SECURITY;
+DROP POLICY teams_org ON teams;
+ALTER TABLE teams NO FORCE ROW LEVEL SECURITY, DISABLE ROW LEVEL SECURITY;
+DROP POLICY tags_org ON tags;
+ALTER TABLE tags NO FORCE ROW LEVEL SECURITY, DISABLE ROW LEVEL SECURITY;
+DROP POLICY projects_org ON projects;
+ALTER TABLE projects NO FORCE ROW LEVEL SECURITY, DISABLE ROW LEVEL SECURITY;
+DROP POLICY tasks_org ON tasks;
+ALTER TABLE tasks NO FORCE ROW LEVEL SECURITY, DISABLE ROW LEVEL SECURITY;
+DROP POLICY users_org ON users;
+ALTER TABLE users NO FORCE ROW LEVEL SECURITY, DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX audit_log_org;
+DROP INDEX tasks_org;
+ALTER TABLE teams DROP CONSTRAINT teams_name_key;
+ALTER TABLE teams ADD CONSTRAINT teams_name_key UNIQUE (name);
+ALTER TABLE tags DROP CONSTRAINT tags_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_name_key UNIQUE (name);
+ALTER TABLE projects DROP CONSTRAINT projects_name_key;
+ALTER TABLE projects ADD CONSTRAINT projects_name_key UNIQUE (name);
+DROP INDEX users_passport_index;
+CREATE UNIQUE INDEX users_passport_index ON users (passport_index);
+
+ALTER TABLE audit_log DROP COLUMN org_id;
+ALTER TABLE api_keys DROP COLUMN org_id;
+ALTER TABLE teams DROP COLUMN org_id;
+ALTER TABLE tags DROP COLUMN org_id;
+ALTER TABLE projects DROP COLUMN org_id;
+ALTER TABLE tasks DROP COLUMN org_id;
+ALTER TABLE users DROP COLUMN org_id;
+DROP TABLE organizations;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- instances of the service listen to the channel to stream appended entries to their subscribers,
+-- notifications are delivered once the transaction of the change commits
+CREATE FUNCTION audit_log_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('audit_log', json_build_object('org', NEW.org_id, 'id', NEW.id)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_notify AFTER INSERT ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_notify();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER audit_log_notify ON audit_log;
+DROP FUNCTION audit_log_notify();
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN enrich_status TEXT NOT NULL DEFAULT 'done';
+ALTER TABLE users ADD COLUMN enrich_attempts INT NOT NULL DEFAULT 0;
+-- users without a name were created while the name service was unavailable, row-level security
+-- is lifted for the owner meanwhile so users of all organizations are updated
+ALTER TABLE users NO FORCE ROW LEVEL SECURITY;
+UPDATE users SET enrich_status = 'pending' WHERE name = '';
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+CREATE INDEX users_enrich_pending ON users (org_id, id) WHERE enrich_status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_enrich_pending;
+ALTER TABLE users DROP COLUMN enrich_attempts;
+ALTER TABLE users DROP COLUMN enrich_status;
+-- +goose StatementEnd
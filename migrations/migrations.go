@@ -2,5 +2,12 @@ package migrations
 
 import "embed"
 
+// Migrations are PostgreSQL migrations.
+//
 //go:embed *.sql
 var Migrations embed.FS
+
+// SQLite are migrations of the embedded SQLite storage, they live in the sqlite directory.
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
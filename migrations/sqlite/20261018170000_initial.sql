@@ -0,0 +1,74 @@
+-- +goose Up
+-- +goose StatementBegin
+-- times are stored as microseconds since the Unix epoch
+CREATE TABLE users (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    name TEXT NOT NULL,
+                    surname TEXT NOT NULL,
+                    patronymic TEXT NOT NULL,
+                    address TEXT NOT NULL DEFAULT '',
+                    passport_serie INTEGER NOT NULL,
+                    passport_number INTEGER NOT NULL,
+                    role TEXT NOT NULL DEFAULT 'employee' CHECK (role IN ('employee', 'manager', 'admin')),
+                    manager_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+                    feed_token_hash BLOB UNIQUE
+);
+CREATE INDEX users_manager ON users (manager_id);
+CREATE TABLE projects (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    name TEXT NOT NULL UNIQUE,
+                    description TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE tags (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE tasks (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                    title TEXT NOT NULL DEFAULT '',
+                    description TEXT NOT NULL DEFAULT '',
+                    project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL,
+                    start_time INTEGER NOT NULL,
+                    end_time INTEGER,
+                    minutes INTEGER NOT NULL
+);
+CREATE INDEX tasks_user_start ON tasks (user_id, start_time);
+CREATE INDEX tasks_project ON tasks (project_id);
+CREATE UNIQUE INDEX tasks_one_running ON tasks (user_id) WHERE end_time IS NULL;
+CREATE TABLE task_tags (
+                    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+                    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+                    PRIMARY KEY (task_id, tag_id)
+);
+CREATE INDEX task_tags_tag ON task_tags (tag_id);
+CREATE TABLE task_segments (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+                    start_time INTEGER NOT NULL,
+                    end_time INTEGER
+);
+CREATE INDEX task_segments_task ON task_segments (task_id, start_time);
+CREATE UNIQUE INDEX task_segments_one_open ON task_segments (task_id) WHERE end_time IS NULL;
+CREATE TABLE api_keys (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                    name TEXT NOT NULL DEFAULT '',
+                    prefix TEXT NOT NULL,
+                    hash BLOB NOT NULL UNIQUE,
+                    created_at INTEGER NOT NULL,
+                    revoked_at INTEGER
+);
+CREATE INDEX api_keys_user ON api_keys (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_keys;
+DROP TABLE task_segments;
+DROP TABLE task_tags;
+DROP TABLE tasks;
+DROP TABLE tags;
+DROP TABLE projects;
+DROP TABLE users;
+-- +goose StatementEnd
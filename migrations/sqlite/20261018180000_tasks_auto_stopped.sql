@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN auto_stopped INTEGER NOT NULL DEFAULT 0;
+-- the auto-stop worker looks for segments open for too long
+CREATE INDEX task_segments_open_start ON task_segments (start_time) WHERE end_time IS NULL;
+-- leases of background jobs, SQLite has no advisory locks
+CREATE TABLE locks (
+                    name TEXT PRIMARY KEY,
+                    expires_at INTEGER NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE locks;
+DROP INDEX task_segments_open_start;
+ALTER TABLE tasks DROP COLUMN auto_stopped;
+-- +goose StatementEnd
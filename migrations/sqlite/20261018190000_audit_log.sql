@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- actors and entities are not referenced, entries outlive deleted users and tasks
+CREATE TABLE audit_log (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    created_at INTEGER NOT NULL,
+                    actor_id INTEGER,
+                    actor_role TEXT NOT NULL DEFAULT '',
+                    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+                    entity TEXT NOT NULL CHECK (entity IN ('user', 'task')),
+                    entity_id INTEGER NOT NULL,
+                    before TEXT,
+                    after TEXT,
+                    request_id TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX audit_log_entity ON audit_log (entity, entity_id, id);
+CREATE INDEX audit_log_actor ON audit_log (actor_id, id);
+CREATE INDEX audit_log_created_at ON audit_log (created_at);
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_log;
+-- +goose StatementEnd
@@ -0,0 +1,72 @@
+-- +goose Up
+-- +goose StatementBegin
+-- passports are stored as entered, leading zeros of the series and number are significant,
+-- columns are recreated since INTEGER affinity converts numeric text back to numbers
+ALTER TABLE users ADD COLUMN passport_serie_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN passport_number_text TEXT NOT NULL DEFAULT '';
+UPDATE users
+SET passport_serie_text = CASE WHEN typeof(passport_serie) = 'integer' THEN printf('%04d', passport_serie) ELSE passport_serie END,
+    passport_number_text = CASE WHEN typeof(passport_number) = 'integer' THEN printf('%06d', passport_number) ELSE passport_number END;
+ALTER TABLE users DROP COLUMN passport_serie;
+ALTER TABLE users DROP COLUMN passport_number;
+ALTER TABLE users RENAME COLUMN passport_serie_text TO passport_serie;
+ALTER TABLE users RENAME COLUMN passport_number_text TO passport_number;
+
+-- users registered with the same passport are merged into the one registered first
+CREATE TEMPORARY TABLE passport_duplicates AS
+SELECT id, keep_id
+FROM (SELECT id, min(id) OVER (PARTITION BY passport_serie, passport_number) AS keep_id FROM users)
+WHERE id <> keep_id;
+
+-- only the latest running task of merged users is left running
+CREATE TEMPORARY TABLE passport_stopped_tasks AS
+SELECT t.id
+FROM tasks t
+LEFT JOIN passport_duplicates d ON d.id = t.user_id
+WHERE t.end_time IS NULL
+  AND EXISTS (SELECT 1
+              FROM tasks o
+              LEFT JOIN passport_duplicates od ON od.id = o.user_id
+              WHERE o.end_time IS NULL
+                AND o.id > t.id
+                AND COALESCE(od.keep_id, o.user_id) = COALESCE(d.keep_id, t.user_id));
+UPDATE task_segments
+SET end_time = CAST((julianday('now') - 2440587.5) * 86400000000 AS INTEGER)
+WHERE end_time IS NULL
+  AND task_id IN (SELECT id FROM passport_stopped_tasks);
+UPDATE tasks
+SET end_time = (SELECT MAX(s.end_time) FROM task_segments s WHERE s.task_id = tasks.id),
+    minutes = (SELECT COALESCE(SUM(s.end_time - s.start_time), 0) / 60000000 FROM task_segments s WHERE s.task_id = tasks.id)
+WHERE id IN (SELECT id FROM passport_stopped_tasks);
+
+UPDATE tasks SET user_id = d.keep_id FROM passport_duplicates d WHERE tasks.user_id = d.id;
+UPDATE api_keys SET user_id = d.keep_id FROM passport_duplicates d WHERE api_keys.user_id = d.id;
+UPDATE users SET manager_id = NULLIF(d.keep_id, users.id) FROM passport_duplicates d WHERE users.manager_id = d.id;
+
+INSERT INTO audit_log (created_at, action, entity, entity_id, before)
+SELECT CAST((julianday('now') - 2440587.5) * 86400000000 AS INTEGER), 'delete', 'user', u.id,
+       json_object('id', u.id, 'passportSerie', u.passport_serie, 'passportNumber', u.passport_number,
+                   'name', u.name, 'surname', u.surname, 'patronymic', u.patronymic, 'address', u.address,
+                   'role', u.role, 'managerId', u.manager_id)
+FROM users u
+JOIN passport_duplicates d ON d.id = u.id;
+DELETE FROM users WHERE id IN (SELECT id FROM passport_duplicates);
+
+DROP TABLE passport_stopped_tasks;
+DROP TABLE passport_duplicates;
+
+CREATE UNIQUE INDEX users_passport ON users (passport_serie, passport_number);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- merged users are not restored
+DROP INDEX users_passport;
+ALTER TABLE users ADD COLUMN passport_serie_int INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN passport_number_int INTEGER NOT NULL DEFAULT 0;
+UPDATE users SET passport_serie_int = CAST(passport_serie AS INTEGER), passport_number_int = CAST(passport_number AS INTEGER);
+ALTER TABLE users DROP COLUMN passport_serie;
+ALTER TABLE users DROP COLUMN passport_number;
+ALTER TABLE users RENAME COLUMN passport_serie_int TO passport_serie;
+ALTER TABLE users RENAME COLUMN passport_number_int TO passport_number;
+-- +goose StatementEnd
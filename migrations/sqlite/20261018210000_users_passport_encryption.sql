@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- passports are encrypted by the application on start as keys are not available to migrations,
+-- plaintext columns are cleared then and left until every deployment has encrypted its passports
+ALTER TABLE users ADD COLUMN passport_encrypted BLOB;
+ALTER TABLE users ADD COLUMN passport_index BLOB;
+ALTER TABLE users ADD COLUMN passport_key TEXT;
+-- uniqueness is kept by the blind index
+DROP INDEX users_passport;
+CREATE UNIQUE INDEX users_passport_index ON users (passport_index);
+
+-- snapshots of users in the audit log don't keep passports
+DROP TRIGGER audit_log_no_update;
+UPDATE audit_log
+SET before = json_remove(before, '$.passportSerie', '$.passportNumber'),
+    after = json_remove(after, '$.passportSerie', '$.passportNumber')
+WHERE entity = 'user';
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- SQLite can't raise errors outside of triggers, the check constraint fails if any passport is encrypted
+CREATE TEMPORARY TABLE passports_encrypted (count INTEGER CHECK (count = 0));
+INSERT INTO passports_encrypted SELECT COUNT(*) FROM users WHERE passport_encrypted IS NOT NULL;
+DROP TABLE passports_encrypted;
+DROP INDEX users_passport_index;
+CREATE UNIQUE INDEX users_passport ON users (passport_serie, passport_number);
+ALTER TABLE users DROP COLUMN passport_encrypted;
+ALTER TABLE users DROP COLUMN passport_index;
+ALTER TABLE users DROP COLUMN passport_key;
+-- +goose StatementEnd
@@ -0,0 +1,103 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- +goose StatementBegin
+-- tables are rebuilt with foreign keys off, so dropping the old ones doesn't cascade to tasks and team members,
+-- it can only be switched outside of a transaction
+PRAGMA foreign_keys = OFF;
+BEGIN;
+CREATE TABLE organizations (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    name TEXT NOT NULL UNIQUE
+);
+-- data stored before organizations belongs to the default one
+INSERT INTO organizations (id, name) VALUES (1, 'Default');
+
+-- SQLite can't add a referencing column with a default, organizations of these are checked by the service
+ALTER TABLE users ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE tasks ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE api_keys ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE audit_log ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1;
+
+-- passports are unique within an organization
+DROP INDEX users_passport_index;
+CREATE UNIQUE INDEX users_passport_index ON users (org_id, passport_index);
+CREATE INDEX tasks_org ON tasks (org_id);
+CREATE INDEX audit_log_org ON audit_log (org_id, id);
+
+-- names are unique within an organization, SQLite can't drop column constraints so tables are rebuilt
+CREATE TABLE projects_new (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id),
+                    name TEXT NOT NULL,
+                    description TEXT NOT NULL DEFAULT '',
+                    UNIQUE (org_id, name)
+);
+INSERT INTO projects_new (id, name, description) SELECT id, name, description FROM projects;
+DROP TABLE projects;
+ALTER TABLE projects_new RENAME TO projects;
+CREATE TABLE tags_new (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id),
+                    name TEXT NOT NULL,
+                    UNIQUE (org_id, name)
+);
+INSERT INTO tags_new (id, name) SELECT id, name FROM tags;
+DROP TABLE tags;
+ALTER TABLE tags_new RENAME TO tags;
+CREATE TABLE teams_new (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id),
+                    name TEXT NOT NULL,
+                    manager_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+                    UNIQUE (org_id, name)
+);
+INSERT INTO teams_new (id, name, manager_id) SELECT id, name, manager_id FROM teams;
+DROP TABLE teams;
+ALTER TABLE teams_new RENAME TO teams;
+CREATE INDEX teams_manager ON teams (manager_id);
+COMMIT;
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- data of all organizations is left together, names and passports must be unique across them
+PRAGMA foreign_keys = OFF;
+BEGIN;
+CREATE TABLE teams_new (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    name TEXT NOT NULL UNIQUE,
+                    manager_id INTEGER REFERENCES users(id) ON DELETE SET NULL
+);
+INSERT INTO teams_new (id, name, manager_id) SELECT id, name, manager_id FROM teams;
+DROP TABLE teams;
+ALTER TABLE teams_new RENAME TO teams;
+CREATE INDEX teams_manager ON teams (manager_id);
+CREATE TABLE tags_new (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    name TEXT NOT NULL UNIQUE
+);
+INSERT INTO tags_new (id, name) SELECT id, name FROM tags;
+DROP TABLE tags;
+ALTER TABLE tags_new RENAME TO tags;
+CREATE TABLE projects_new (
+                    id INTEGER PRIMARY KEY AUTOINCREMENT,
+                    name TEXT NOT NULL UNIQUE,
+                    description TEXT NOT NULL DEFAULT ''
+);
+INSERT INTO projects_new (id, name, description) SELECT id, name, description FROM projects;
+DROP TABLE projects;
+ALTER TABLE projects_new RENAME TO projects;
+
+DROP INDEX audit_log_org;
+DROP INDEX tasks_org;
+DROP INDEX users_passport_index;
+CREATE UNIQUE INDEX users_passport_index ON users (passport_index);
+ALTER TABLE audit_log DROP COLUMN org_id;
+ALTER TABLE api_keys DROP COLUMN org_id;
+ALTER TABLE tasks DROP COLUMN org_id;
+ALTER TABLE users DROP COLUMN org_id;
+DROP TABLE organizations;
+COMMIT;
+PRAGMA foreign_keys = ON;
+-- +goose StatementEnd
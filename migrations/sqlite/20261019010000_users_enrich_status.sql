@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN enrich_status TEXT NOT NULL DEFAULT 'done';
+ALTER TABLE users ADD COLUMN enrich_attempts INTEGER NOT NULL DEFAULT 0;
+-- users without a name were created while the name service was unavailable
+UPDATE users SET enrich_status = 'pending' WHERE name = '';
+CREATE INDEX users_enrich_pending ON users (org_id, id) WHERE enrich_status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_enrich_pending;
+ALTER TABLE users DROP COLUMN enrich_attempts;
+ALTER TABLE users DROP COLUMN enrich_status;
+-- +goose StatementEnd
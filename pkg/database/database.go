@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/Nicholas2012/time-tracker/migrations"
@@ -21,6 +22,14 @@ func New(dsn string) (*sql.DB, error) {
 	return db, nil
 }
 
+// BypassesRowSecurity reports whether the role of the connection isn't restricted by row-level security,
+// superusers and roles with BYPASSRLS see rows of all organizations.
+func BypassesRowSecurity(ctx context.Context, db *sql.DB) (bool, error) {
+	var bypass bool
+	err := db.QueryRowContext(ctx, `SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`).Scan(&bypass)
+	return bypass, err
+}
+
 func ApplyMigrations(db *sql.DB) error {
 	goose.SetBaseFS(migrations.Migrations)
 	if err := goose.Up(db, "."); err != nil {
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"net/url"
+
+	"github.com/Nicholas2012/time-tracker/migrations"
+	goose "github.com/pressly/goose/v3"
+	_ "modernc.org/sqlite"
+)
+
+// NewSQLite opens the SQLite database file, it's created if it doesn't exist.
+// Write transactions take the database lock at the start, so concurrent writers wait for each other instead of failing.
+func NewSQLite(path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?" + url.Values{
+		"_pragma": {"foreign_keys(1)", "journal_mode(WAL)", "busy_timeout(10000)"},
+		"_txlock": {"immediate"},
+	}.Encode()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func ApplySQLiteMigrations(db *sql.DB) error {
+	fsys, err := fs.Sub(migrations.SQLite, "sqlite")
+	if err != nil {
+		return err
+	}
+
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, fsys)
+	if err != nil {
+		return fmt.Errorf("create migration provider: %w", err)
+	}
+
+	_, err = provider.Up(context.Background())
+	return err
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+
+	"github.com/Nicholas2012/time-tracker/internal/logging"
+	"github.com/Nicholas2012/time-tracker/migrations"
+	goose "github.com/pressly/goose/v3"
+)
+
+// CheckMigrations returns an error if the PostgreSQL schema is behind the latest embedded migration.
+// A schema ahead of it is only logged, it's migrated by a newer version of the service during a rolling deploy.
+func CheckMigrations(ctx context.Context, db *sql.DB) error {
+	return checkVersion(ctx, goose.DialectPostgres, db, migrations.Migrations)
+}
+
+// CheckSQLiteMigrations returns an error if the SQLite schema is behind the latest embedded migration.
+func CheckSQLiteMigrations(ctx context.Context, db *sql.DB) error {
+	fsys, err := fs.Sub(migrations.SQLite, "sqlite")
+	if err != nil {
+		return err
+	}
+	return checkVersion(ctx, goose.DialectSQLite3, db, fsys)
+}
+
+func checkVersion(ctx context.Context, dialect goose.Dialect, db *sql.DB, fsys fs.FS) error {
+	provider, err := goose.NewProvider(dialect, db, fsys)
+	if err != nil {
+		return fmt.Errorf("create migration provider: %w", err)
+	}
+
+	sources := provider.ListSources()
+	if len(sources) == 0 {
+		return nil
+	}
+	expected := sources[len(sources)-1].Version
+
+	current, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("get schema version: %w", err)
+	}
+	if current < expected {
+		return fmt.Errorf("schema version is %d, expected %d", current, expected)
+	}
+	if current > expected {
+		logging.FromContext(ctx).Warn("Schema is ahead of the service", "version", current, "expected", expected)
+	}
+
+	return nil
+}
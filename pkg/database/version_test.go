@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Nicholas2012/time-tracker/migrations"
+	goose "github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSQLiteMigrations(t *testing.T) {
+	db, err := NewSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	require.ErrorContains(t, CheckSQLiteMigrations(ctx, db), "schema version is 0")
+
+	require.NoError(t, ApplySQLiteMigrations(db))
+	require.NoError(t, CheckSQLiteMigrations(ctx, db))
+
+	// an older service keeps serving while a newer one migrates the schema
+	fsys, err := fs.Sub(migrations.SQLite, "sqlite")
+	require.NoError(t, err)
+	names, err := fs.Glob(fsys, "*.sql")
+	require.NoError(t, err)
+	data, err := fs.ReadFile(fsys, names[0])
+	require.NoError(t, err)
+	require.NoError(t, checkVersion(ctx, goose.DialectSQLite3, db, fstest.MapFS{names[0]: {Data: data}}))
+}
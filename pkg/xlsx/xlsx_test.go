@@ -0,0 +1,51 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, "Tasks & time")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRow("Name", "Minutes", "Start", "End"))
+	require.NoError(t, w.WriteRow("<Иванов>", 90, time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC), time.Time{}))
+	require.Error(t, w.WriteRow(struct{}{}))
+	require.NoError(t, w.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	parts := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		parts[f.Name] = string(b)
+	}
+
+	require.Contains(t, parts, "[Content_Types].xml")
+	require.Contains(t, parts["xl/workbook.xml"], `name="Tasks &amp; time"`)
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	require.Contains(t, sheet, `<c r="A1" t="inlineStr"><is><t xml:space="preserve">Name</t></is></c>`)
+	require.Contains(t, sheet, `<t xml:space="preserve">&lt;Иванов&gt;</t>`)
+	require.Contains(t, sheet, `<c r="B2"><v>90</v></c>`)
+	require.Contains(t, sheet, `<c r="C2" s="1"><v>45474.5</v></c>`)
+	require.NotContains(t, sheet, `r="D2"`)
+}
+
+func TestColumnName(t *testing.T) {
+	for i, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		require.Equal(t, name, ColumnName(i))
+	}
+}